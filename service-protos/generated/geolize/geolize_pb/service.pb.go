@@ -11,6 +11,9 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -23,6 +26,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// LookupStatus is the outcome of looking up a single IP in a LookupIP batch.
+type LookupStatus int32
+
+const (
+	LookupStatus_LOOKUP_STATUS_OK         LookupStatus = 0
+	LookupStatus_LOOKUP_STATUS_INVALID_IP LookupStatus = 1
+	LookupStatus_LOOKUP_STATUS_NOT_FOUND  LookupStatus = 2
+	LookupStatus_LOOKUP_STATUS_INTERNAL   LookupStatus = 3
+)
+
+// Enum value maps for LookupStatus.
+var (
+	LookupStatus_name = map[int32]string{
+		0: "LOOKUP_STATUS_OK",
+		1: "LOOKUP_STATUS_INVALID_IP",
+		2: "LOOKUP_STATUS_NOT_FOUND",
+		3: "LOOKUP_STATUS_INTERNAL",
+	}
+	LookupStatus_value = map[string]int32{
+		"LOOKUP_STATUS_OK":         0,
+		"LOOKUP_STATUS_INVALID_IP": 1,
+		"LOOKUP_STATUS_NOT_FOUND":  2,
+		"LOOKUP_STATUS_INTERNAL":   3,
+	}
+)
+
+func (x LookupStatus) Enum() *LookupStatus {
+	p := new(LookupStatus)
+	*p = x
+	return p
+}
+
+func (x LookupStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LookupStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_geolize_service_proto_enumTypes[0].Descriptor()
+}
+
+func (LookupStatus) Type() protoreflect.EnumType {
+	return &file_geolize_service_proto_enumTypes[0]
+}
+
+func (x LookupStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LookupStatus.Descriptor instead.
+func (LookupStatus) EnumDescriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{0}
+}
+
 type PingRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -96,9 +152,12 @@ func (*PingResponse) Descriptor() ([]byte, []int) {
 }
 
 type Continent struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	Names         map[string]string      `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Code      string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Names     map[string]string      `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	GeonameId uint32                 `protobuf:"varint,3,opt,name=geoname_id,json=geonameId,proto3" json:"geoname_id,omitempty"`
+	// name is the name resolved from the requested languages. Lookup only.
+	Name          string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -147,13 +206,31 @@ func (x *Continent) GetNames() map[string]string {
 	return nil
 }
 
+func (x *Continent) GetGeonameId() uint32 {
+	if x != nil {
+		return x.GeonameId
+	}
+	return 0
+}
+
+func (x *Continent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
 type Country struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	IsoCode           string                 `protobuf:"bytes,1,opt,name=iso_code,json=isoCode,proto3" json:"iso_code,omitempty"`
 	Names             map[string]string      `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	IsInEuropeanUnion bool                   `protobuf:"varint,3,opt,name=is_in_european_union,json=isInEuropeanUnion,proto3" json:"is_in_european_union,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	GeonameId         uint32                 `protobuf:"varint,4,opt,name=geoname_id,json=geonameId,proto3" json:"geoname_id,omitempty"`
+	Confidence        uint32                 `protobuf:"varint,5,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// name is the name resolved from the requested languages. Lookup only.
+	Name          string `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Country) Reset() {
@@ -207,14 +284,38 @@ func (x *Country) GetIsInEuropeanUnion() bool {
 	return false
 }
 
+func (x *Country) GetGeonameId() uint32 {
+	if x != nil {
+		return x.GeonameId
+	}
+	return 0
+}
+
+func (x *Country) GetConfidence() uint32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Country) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
 type Location struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Latitude       float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
-	Longitude      float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
-	AccuracyRadius uint32                 `protobuf:"varint,3,opt,name=accuracy_radius,json=accuracyRadius,proto3" json:"accuracy_radius,omitempty"`
-	TimeZone       string                 `protobuf:"bytes,4,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Latitude          float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude         float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	AccuracyRadius    uint32                 `protobuf:"varint,3,opt,name=accuracy_radius,json=accuracyRadius,proto3" json:"accuracy_radius,omitempty"`
+	TimeZone          string                 `protobuf:"bytes,4,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	MetroCode         uint32                 `protobuf:"varint,5,opt,name=metro_code,json=metroCode,proto3" json:"metro_code,omitempty"`
+	PopulationDensity uint32                 `protobuf:"varint,6,opt,name=population_density,json=populationDensity,proto3" json:"population_density,omitempty"`
+	AverageIncome     uint32                 `protobuf:"varint,7,opt,name=average_income,json=averageIncome,proto3" json:"average_income,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *Location) Reset() {
@@ -275,10 +376,35 @@ func (x *Location) GetTimeZone() string {
 	return ""
 }
 
+func (x *Location) GetMetroCode() uint32 {
+	if x != nil {
+		return x.MetroCode
+	}
+	return 0
+}
+
+func (x *Location) GetPopulationDensity() uint32 {
+	if x != nil {
+		return x.PopulationDensity
+	}
+	return 0
+}
+
+func (x *Location) GetAverageIncome() uint32 {
+	if x != nil {
+		return x.AverageIncome
+	}
+	return 0
+}
+
 type Subdivision struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	IsoCode       string                 `protobuf:"bytes,1,opt,name=iso_code,json=isoCode,proto3" json:"iso_code,omitempty"`
-	Names         map[string]string      `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	IsoCode    string                 `protobuf:"bytes,1,opt,name=iso_code,json=isoCode,proto3" json:"iso_code,omitempty"`
+	Names      map[string]string      `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	GeonameId  uint32                 `protobuf:"varint,3,opt,name=geoname_id,json=geonameId,proto3" json:"geoname_id,omitempty"`
+	Confidence uint32                 `protobuf:"varint,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// name is the name resolved from the requested languages. Lookup only.
+	Name          string `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -327,9 +453,31 @@ func (x *Subdivision) GetNames() map[string]string {
 	return nil
 }
 
+func (x *Subdivision) GetGeonameId() uint32 {
+	if x != nil {
+		return x.GeonameId
+	}
+	return 0
+}
+
+func (x *Subdivision) GetConfidence() uint32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Subdivision) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
 type Postal struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Confidence    uint32                 `protobuf:"varint,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -371,9 +519,20 @@ func (x *Postal) GetCode() string {
 	return ""
 }
 
+func (x *Postal) GetConfidence() uint32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
 type City struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Names         map[string]string      `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Names      map[string]string      `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	GeonameId  uint32                 `protobuf:"varint,2,opt,name=geoname_id,json=geonameId,proto3" json:"geoname_id,omitempty"`
+	Confidence uint32                 `protobuf:"varint,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// name is the name resolved from the requested languages. Lookup only.
+	Name          string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -415,14 +574,38 @@ func (x *City) GetNames() map[string]string {
 	return nil
 }
 
+func (x *City) GetGeonameId() uint32 {
+	if x != nil {
+		return x.GeonameId
+	}
+	return 0
+}
+
+func (x *City) GetConfidence() uint32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *City) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
 type RepresentedCountry struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	IsoCode           string                 `protobuf:"bytes,1,opt,name=iso_code,json=isoCode,proto3" json:"iso_code,omitempty"`
 	Names             map[string]string      `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Type              string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
 	IsInEuropeanUnion bool                   `protobuf:"varint,4,opt,name=is_in_european_union,json=isInEuropeanUnion,proto3" json:"is_in_european_union,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	GeonameId         uint32                 `protobuf:"varint,5,opt,name=geoname_id,json=geonameId,proto3" json:"geoname_id,omitempty"`
+	// name is the name resolved from the requested languages. Lookup only.
+	Name          string `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RepresentedCountry) Reset() {
@@ -483,13 +666,31 @@ func (x *RepresentedCountry) GetIsInEuropeanUnion() bool {
 	return false
 }
 
+func (x *RepresentedCountry) GetGeonameId() uint32 {
+	if x != nil {
+		return x.GeonameId
+	}
+	return 0
+}
+
+func (x *RepresentedCountry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
 type RegisteredCountry struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	IsoCode           string                 `protobuf:"bytes,1,opt,name=iso_code,json=isoCode,proto3" json:"iso_code,omitempty"`
 	Names             map[string]string      `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	IsInEuropeanUnion bool                   `protobuf:"varint,3,opt,name=is_in_european_union,json=isInEuropeanUnion,proto3" json:"is_in_european_union,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	GeonameId         uint32                 `protobuf:"varint,4,opt,name=geoname_id,json=geonameId,proto3" json:"geoname_id,omitempty"`
+	Confidence        uint32                 `protobuf:"varint,5,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// name is the name resolved from the requested languages. Lookup only.
+	Name          string `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RegisteredCountry) Reset() {
@@ -543,13 +744,53 @@ func (x *RegisteredCountry) GetIsInEuropeanUnion() bool {
 	return false
 }
 
+func (x *RegisteredCountry) GetGeonameId() uint32 {
+	if x != nil {
+		return x.GeonameId
+	}
+	return 0
+}
+
+func (x *RegisteredCountry) GetConfidence() uint32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *RegisteredCountry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
 type Traits struct {
-	state               protoimpl.MessageState `protogen:"open.v1"`
-	IsAnonymousProxy    bool                   `protobuf:"varint,1,opt,name=is_anonymous_proxy,json=isAnonymousProxy,proto3" json:"is_anonymous_proxy,omitempty"`
-	IsAnycast           bool                   `protobuf:"varint,2,opt,name=is_anycast,json=isAnycast,proto3" json:"is_anycast,omitempty"`
-	IsSatelliteProvider bool                   `protobuf:"varint,3,opt,name=is_satellite_provider,json=isSatelliteProvider,proto3" json:"is_satellite_provider,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+	state                        protoimpl.MessageState `protogen:"open.v1"`
+	IsAnonymousProxy             bool                   `protobuf:"varint,1,opt,name=is_anonymous_proxy,json=isAnonymousProxy,proto3" json:"is_anonymous_proxy,omitempty"`
+	IsAnycast                    bool                   `protobuf:"varint,2,opt,name=is_anycast,json=isAnycast,proto3" json:"is_anycast,omitempty"`
+	IsSatelliteProvider          bool                   `protobuf:"varint,3,opt,name=is_satellite_provider,json=isSatelliteProvider,proto3" json:"is_satellite_provider,omitempty"`
+	IsAnonymous                  bool                   `protobuf:"varint,4,opt,name=is_anonymous,json=isAnonymous,proto3" json:"is_anonymous,omitempty"`
+	IsAnonymousVpn               bool                   `protobuf:"varint,5,opt,name=is_anonymous_vpn,json=isAnonymousVpn,proto3" json:"is_anonymous_vpn,omitempty"`
+	IsHostingProvider            bool                   `protobuf:"varint,6,opt,name=is_hosting_provider,json=isHostingProvider,proto3" json:"is_hosting_provider,omitempty"`
+	IsLegitimateProxy            bool                   `protobuf:"varint,7,opt,name=is_legitimate_proxy,json=isLegitimateProxy,proto3" json:"is_legitimate_proxy,omitempty"`
+	IsPublicProxy                bool                   `protobuf:"varint,8,opt,name=is_public_proxy,json=isPublicProxy,proto3" json:"is_public_proxy,omitempty"`
+	IsResidentialProxy           bool                   `protobuf:"varint,9,opt,name=is_residential_proxy,json=isResidentialProxy,proto3" json:"is_residential_proxy,omitempty"`
+	IsTorExitNode                bool                   `protobuf:"varint,10,opt,name=is_tor_exit_node,json=isTorExitNode,proto3" json:"is_tor_exit_node,omitempty"`
+	AutonomousSystemNumber       uint32                 `protobuf:"varint,11,opt,name=autonomous_system_number,json=autonomousSystemNumber,proto3" json:"autonomous_system_number,omitempty"`
+	AutonomousSystemOrganization string                 `protobuf:"bytes,12,opt,name=autonomous_system_organization,json=autonomousSystemOrganization,proto3" json:"autonomous_system_organization,omitempty"`
+	ConnectionType               string                 `protobuf:"bytes,13,opt,name=connection_type,json=connectionType,proto3" json:"connection_type,omitempty"`
+	Domain                       string                 `protobuf:"bytes,14,opt,name=domain,proto3" json:"domain,omitempty"`
+	Isp                          string                 `protobuf:"bytes,15,opt,name=isp,proto3" json:"isp,omitempty"`
+	MobileCountryCode            string                 `protobuf:"bytes,16,opt,name=mobile_country_code,json=mobileCountryCode,proto3" json:"mobile_country_code,omitempty"`
+	MobileNetworkCode            string                 `protobuf:"bytes,17,opt,name=mobile_network_code,json=mobileNetworkCode,proto3" json:"mobile_network_code,omitempty"`
+	Organization                 string                 `protobuf:"bytes,18,opt,name=organization,proto3" json:"organization,omitempty"`
+	UserType                     string                 `protobuf:"bytes,19,opt,name=user_type,json=userType,proto3" json:"user_type,omitempty"`
+	StaticIpScore                float64                `protobuf:"fixed64,20,opt,name=static_ip_score,json=staticIpScore,proto3" json:"static_ip_score,omitempty"`
+	// network is the CIDR the record was matched on. Ignored by ModifyIP.
+	Network       string `protobuf:"bytes,21,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Traits) Reset() {
@@ -603,6 +844,132 @@ func (x *Traits) GetIsSatelliteProvider() bool {
 	return false
 }
 
+func (x *Traits) GetIsAnonymous() bool {
+	if x != nil {
+		return x.IsAnonymous
+	}
+	return false
+}
+
+func (x *Traits) GetIsAnonymousVpn() bool {
+	if x != nil {
+		return x.IsAnonymousVpn
+	}
+	return false
+}
+
+func (x *Traits) GetIsHostingProvider() bool {
+	if x != nil {
+		return x.IsHostingProvider
+	}
+	return false
+}
+
+func (x *Traits) GetIsLegitimateProxy() bool {
+	if x != nil {
+		return x.IsLegitimateProxy
+	}
+	return false
+}
+
+func (x *Traits) GetIsPublicProxy() bool {
+	if x != nil {
+		return x.IsPublicProxy
+	}
+	return false
+}
+
+func (x *Traits) GetIsResidentialProxy() bool {
+	if x != nil {
+		return x.IsResidentialProxy
+	}
+	return false
+}
+
+func (x *Traits) GetIsTorExitNode() bool {
+	if x != nil {
+		return x.IsTorExitNode
+	}
+	return false
+}
+
+func (x *Traits) GetAutonomousSystemNumber() uint32 {
+	if x != nil {
+		return x.AutonomousSystemNumber
+	}
+	return 0
+}
+
+func (x *Traits) GetAutonomousSystemOrganization() string {
+	if x != nil {
+		return x.AutonomousSystemOrganization
+	}
+	return ""
+}
+
+func (x *Traits) GetConnectionType() string {
+	if x != nil {
+		return x.ConnectionType
+	}
+	return ""
+}
+
+func (x *Traits) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Traits) GetIsp() string {
+	if x != nil {
+		return x.Isp
+	}
+	return ""
+}
+
+func (x *Traits) GetMobileCountryCode() string {
+	if x != nil {
+		return x.MobileCountryCode
+	}
+	return ""
+}
+
+func (x *Traits) GetMobileNetworkCode() string {
+	if x != nil {
+		return x.MobileNetworkCode
+	}
+	return ""
+}
+
+func (x *Traits) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *Traits) GetUserType() string {
+	if x != nil {
+		return x.UserType
+	}
+	return ""
+}
+
+func (x *Traits) GetStaticIpScore() float64 {
+	if x != nil {
+		return x.StaticIpScore
+	}
+	return 0
+}
+
+func (x *Traits) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
 type IPInfo struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Ip                 string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
@@ -616,8 +983,31 @@ type IPInfo struct {
 	Traits             *Traits                `protobuf:"bytes,9,opt,name=traits,proto3" json:"traits,omitempty"`
 	Postal             *Postal                `protobuf:"bytes,10,opt,name=postal,proto3" json:"postal,omitempty"`
 	City               *City                  `protobuf:"bytes,11,opt,name=city,proto3" json:"city,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	Status             LookupStatus           `protobuf:"varint,12,opt,name=status,proto3,enum=document_pb.LookupStatus" json:"status,omitempty"`
+	// message describes why the lookup of this IP did not succeed.
+	Message string `protobuf:"bytes,13,opt,name=message,proto3" json:"message,omitempty"`
+	// found is false when the database has no record for the IP.
+	Found bool `protobuf:"varint,14,opt,name=found,proto3" json:"found,omitempty"`
+	// network is the CIDR that matched the IP, also set for misses so callers
+	// can cache results per network.
+	Network string `protobuf:"bytes,15,opt,name=network,proto3" json:"network,omitempty"`
+	// Merged from the optional ASN, Anonymous IP and Connection Type databases.
+	Asn                uint32 `protobuf:"varint,16,opt,name=asn,proto3" json:"asn,omitempty"`
+	AsOrg              string `protobuf:"bytes,17,opt,name=as_org,json=asOrg,proto3" json:"as_org,omitempty"`
+	IsAnonymous        bool   `protobuf:"varint,18,opt,name=is_anonymous,json=isAnonymous,proto3" json:"is_anonymous,omitempty"`
+	IsVpn              bool   `protobuf:"varint,19,opt,name=is_vpn,json=isVpn,proto3" json:"is_vpn,omitempty"`
+	IsTor              bool   `protobuf:"varint,20,opt,name=is_tor,json=isTor,proto3" json:"is_tor,omitempty"`
+	IsHostingProvider  bool   `protobuf:"varint,21,opt,name=is_hosting_provider,json=isHostingProvider,proto3" json:"is_hosting_provider,omitempty"`
+	IsPublicProxy      bool   `protobuf:"varint,22,opt,name=is_public_proxy,json=isPublicProxy,proto3" json:"is_public_proxy,omitempty"`
+	IsResidentialProxy bool   `protobuf:"varint,23,opt,name=is_residential_proxy,json=isResidentialProxy,proto3" json:"is_residential_proxy,omitempty"`
+	ConnectionType     string `protobuf:"bytes,24,opt,name=connection_type,json=connectionType,proto3" json:"connection_type,omitempty"`
+	// provider is the name of the provider that answered the lookup.
+	Provider string `protobuf:"bytes,25,opt,name=provider,proto3" json:"provider,omitempty"`
+	// sources maps each field to the provider that supplied it when the
+	// result was merged from several providers.
+	Sources       map[string]string `protobuf:"bytes,26,rep,name=sources,proto3" json:"sources,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *IPInfo) Reset() {
@@ -727,26 +1117,139 @@ func (x *IPInfo) GetCity() *City {
 	return nil
 }
 
-type LookupIPRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ips           []string               `protobuf:"bytes,1,rep,name=ips,proto3" json:"ips,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *IPInfo) GetStatus() LookupStatus {
+	if x != nil {
+		return x.Status
+	}
+	return LookupStatus_LOOKUP_STATUS_OK
 }
 
-func (x *LookupIPRequest) Reset() {
-	*x = LookupIPRequest{}
-	mi := &file_geolize_service_proto_msgTypes[12]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *IPInfo) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
 }
 
-func (x *LookupIPRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *IPInfo) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
 }
 
-func (*LookupIPRequest) ProtoMessage() {}
-
+func (x *IPInfo) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *IPInfo) GetAsn() uint32 {
+	if x != nil {
+		return x.Asn
+	}
+	return 0
+}
+
+func (x *IPInfo) GetAsOrg() string {
+	if x != nil {
+		return x.AsOrg
+	}
+	return ""
+}
+
+func (x *IPInfo) GetIsAnonymous() bool {
+	if x != nil {
+		return x.IsAnonymous
+	}
+	return false
+}
+
+func (x *IPInfo) GetIsVpn() bool {
+	if x != nil {
+		return x.IsVpn
+	}
+	return false
+}
+
+func (x *IPInfo) GetIsTor() bool {
+	if x != nil {
+		return x.IsTor
+	}
+	return false
+}
+
+func (x *IPInfo) GetIsHostingProvider() bool {
+	if x != nil {
+		return x.IsHostingProvider
+	}
+	return false
+}
+
+func (x *IPInfo) GetIsPublicProxy() bool {
+	if x != nil {
+		return x.IsPublicProxy
+	}
+	return false
+}
+
+func (x *IPInfo) GetIsResidentialProxy() bool {
+	if x != nil {
+		return x.IsResidentialProxy
+	}
+	return false
+}
+
+func (x *IPInfo) GetConnectionType() string {
+	if x != nil {
+		return x.ConnectionType
+	}
+	return ""
+}
+
+func (x *IPInfo) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *IPInfo) GetSources() map[string]string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type LookupIPRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ips   []string               `protobuf:"bytes,1,rep,name=ips,proto3" json:"ips,omitempty"`
+	// languages is the preferred locale order, e.g. ["vi", "en"]. Defaults to
+	// the Accept-Language header. Only matching names are returned.
+	Languages []string `protobuf:"bytes,2,rep,name=languages,proto3" json:"languages,omitempty"`
+	// resolve_names returns a single resolved name per place instead of names.
+	ResolveNames bool `protobuf:"varint,3,opt,name=resolve_names,json=resolveNames,proto3" json:"resolve_names,omitempty"`
+	// fields limits each IPInfo to these paths, e.g. "country.iso_code,location.time_zone".
+	// ip, status and message are always returned. Empty returns everything.
+	Fields        *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupIPRequest) Reset() {
+	*x = LookupIPRequest{}
+	mi := &file_geolize_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupIPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupIPRequest) ProtoMessage() {}
+
 func (x *LookupIPRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_geolize_service_proto_msgTypes[12]
 	if x != nil {
@@ -759,40 +1262,902 @@ func (x *LookupIPRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LookupIPRequest.ProtoReflect.Descriptor instead.
-func (*LookupIPRequest) Descriptor() ([]byte, []int) {
-	return file_geolize_service_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use LookupIPRequest.ProtoReflect.Descriptor instead.
+func (*LookupIPRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LookupIPRequest) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+func (x *LookupIPRequest) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *LookupIPRequest) GetResolveNames() bool {
+	if x != nil {
+		return x.ResolveNames
+	}
+	return false
+}
+
+func (x *LookupIPRequest) GetFields() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type LookupIPResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []*IPInfo              `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupIPResponse) Reset() {
+	*x = LookupIPResponse{}
+	mi := &file_geolize_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupIPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupIPResponse) ProtoMessage() {}
+
+func (x *LookupIPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupIPResponse.ProtoReflect.Descriptor instead.
+func (*LookupIPResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LookupIPResponse) GetData() []*IPInfo {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type StreamLookupIPRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// request_id is echoed back on the matching response.
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Ips           []string               `protobuf:"bytes,2,rep,name=ips,proto3" json:"ips,omitempty"`
+	Languages     []string               `protobuf:"bytes,3,rep,name=languages,proto3" json:"languages,omitempty"`
+	ResolveNames  bool                   `protobuf:"varint,4,opt,name=resolve_names,json=resolveNames,proto3" json:"resolve_names,omitempty"`
+	Fields        *fieldmaskpb.FieldMask `protobuf:"bytes,5,opt,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLookupIPRequest) Reset() {
+	*x = StreamLookupIPRequest{}
+	mi := &file_geolize_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLookupIPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLookupIPRequest) ProtoMessage() {}
+
+func (x *StreamLookupIPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLookupIPRequest.ProtoReflect.Descriptor instead.
+func (*StreamLookupIPRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamLookupIPRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *StreamLookupIPRequest) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+func (x *StreamLookupIPRequest) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *StreamLookupIPRequest) GetResolveNames() bool {
+	if x != nil {
+		return x.ResolveNames
+	}
+	return false
+}
+
+func (x *StreamLookupIPRequest) GetFields() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type StreamLookupIPResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Data      []*IPInfo              `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty"`
+	// error is set when the whole message was rejected, e.g. no ips.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// code is the google.rpc.Code of error, e.g. 3 (INVALID_ARGUMENT) or 13
+	// (INTERNAL), and 0 (OK) when there is no error.
+	Code          int32 `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLookupIPResponse) Reset() {
+	*x = StreamLookupIPResponse{}
+	mi := &file_geolize_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLookupIPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLookupIPResponse) ProtoMessage() {}
+
+func (x *StreamLookupIPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLookupIPResponse.ProtoReflect.Descriptor instead.
+func (*StreamLookupIPResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamLookupIPResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *StreamLookupIPResponse) GetData() []*IPInfo {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *StreamLookupIPResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StreamLookupIPResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+type ModifyIPRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Ip                 string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Continent          *Continent             `protobuf:"bytes,2,opt,name=continent,proto3" json:"continent,omitempty"`
+	Country            *Country               `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	Location           *Location              `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	Subdivisions       []*Subdivision         `protobuf:"bytes,5,rep,name=subdivisions,proto3" json:"subdivisions,omitempty"`
+	RepresentedCountry *RepresentedCountry    `protobuf:"bytes,6,opt,name=represented_country,json=representedCountry,proto3" json:"represented_country,omitempty"`
+	RegisteredCountry  *RegisteredCountry     `protobuf:"bytes,7,opt,name=registered_country,json=registeredCountry,proto3" json:"registered_country,omitempty"`
+	Traits             *Traits                `protobuf:"bytes,8,opt,name=traits,proto3" json:"traits,omitempty"`
+	Postal             *Postal                `protobuf:"bytes,9,opt,name=postal,proto3" json:"postal,omitempty"`
+	City               *City                  `protobuf:"bytes,10,opt,name=city,proto3" json:"city,omitempty"`
+	// network is an IPv4 or IPv6 CIDR (e.g. 203.0.113.0/24, 2001:db8::/32) to
+	// override instead of a single ip.
+	Network string `protobuf:"bytes,11,opt,name=network,proto3" json:"network,omitempty"`
+	// reason explains the change and is required. It is kept in the change log
+	// together with ticket and the caller, taken from the x-user metadata.
+	Reason string `protobuf:"bytes,12,opt,name=reason,proto3" json:"reason,omitempty"`
+	// ticket optionally links the change to an issue, e.g. "GEO-123".
+	Ticket string `protobuf:"bytes,13,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	// fields makes the override partial: only these dotted field paths of the
+	// record are written, e.g. "country.names.vi" or "location.time_zone", and
+	// the rest of the record is left untouched. A listed field the request
+	// leaves empty is deleted. Without fields, every sub-object the request
+	// sets replaces the one in the record as a whole.
+	Fields []string `protobuf:"bytes,14,rep,name=fields,proto3" json:"fields,omitempty"`
+	// remove_fields lists dotted field paths to delete from the record.
+	RemoveFields  []string `protobuf:"bytes,15,rep,name=remove_fields,json=removeFields,proto3" json:"remove_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModifyIPRequest) Reset() {
+	*x = ModifyIPRequest{}
+	mi := &file_geolize_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModifyIPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModifyIPRequest) ProtoMessage() {}
+
+func (x *ModifyIPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModifyIPRequest.ProtoReflect.Descriptor instead.
+func (*ModifyIPRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ModifyIPRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *ModifyIPRequest) GetContinent() *Continent {
+	if x != nil {
+		return x.Continent
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetCountry() *Country {
+	if x != nil {
+		return x.Country
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetSubdivisions() []*Subdivision {
+	if x != nil {
+		return x.Subdivisions
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetRepresentedCountry() *RepresentedCountry {
+	if x != nil {
+		return x.RepresentedCountry
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetRegisteredCountry() *RegisteredCountry {
+	if x != nil {
+		return x.RegisteredCountry
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetTraits() *Traits {
+	if x != nil {
+		return x.Traits
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetPostal() *Postal {
+	if x != nil {
+		return x.Postal
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetCity() *City {
+	if x != nil {
+		return x.City
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *ModifyIPRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ModifyIPRequest) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+func (x *ModifyIPRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *ModifyIPRequest) GetRemoveFields() []string {
+	if x != nil {
+		return x.RemoveFields
+	}
+	return nil
+}
+
+type ModifyIPResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is the database version that contains the override. Overrides
+	// that arrive together are written at once and share the same version.
+	Version       string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModifyIPResponse) Reset() {
+	*x = ModifyIPResponse{}
+	mi := &file_geolize_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModifyIPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModifyIPResponse) ProtoMessage() {}
+
+func (x *ModifyIPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModifyIPResponse.ProtoReflect.Descriptor instead.
+func (*ModifyIPResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ModifyIPResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type BatchModifyIPRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// overrides are applied in order, so a later one wins over an earlier one
+	// for the fields they both set. Their reason and ticket are ignored in
+	// favor of the ones of the batch.
+	Overrides []*ModifyIPRequest `protobuf:"bytes,1,rep,name=overrides,proto3" json:"overrides,omitempty"`
+	// reason is required and, with ticket, is kept in the change log for the
+	// whole batch.
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Ticket        string `protobuf:"bytes,3,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchModifyIPRequest) Reset() {
+	*x = BatchModifyIPRequest{}
+	mi := &file_geolize_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchModifyIPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchModifyIPRequest) ProtoMessage() {}
+
+func (x *BatchModifyIPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchModifyIPRequest.ProtoReflect.Descriptor instead.
+func (*BatchModifyIPRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BatchModifyIPRequest) GetOverrides() []*ModifyIPRequest {
+	if x != nil {
+		return x.Overrides
+	}
+	return nil
+}
+
+func (x *BatchModifyIPRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BatchModifyIPRequest) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+type BatchModifyIPResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is the database version holding every override of the batch.
+	Version       string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchModifyIPResponse) Reset() {
+	*x = BatchModifyIPResponse{}
+	mi := &file_geolize_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchModifyIPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchModifyIPResponse) ProtoMessage() {}
+
+func (x *BatchModifyIPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchModifyIPResponse.ProtoReflect.Descriptor instead.
+func (*BatchModifyIPResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BatchModifyIPResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type RemoveIPOverrideRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ip or network names the override to remove. It must be the target the
+	// override was made for with ModifyIP; one of them is required.
+	Ip      string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Network string `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	// reason and ticket are kept in the change log, like for ModifyIP.
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Ticket        string `protobuf:"bytes,4,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveIPOverrideRequest) Reset() {
+	*x = RemoveIPOverrideRequest{}
+	mi := &file_geolize_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveIPOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveIPOverrideRequest) ProtoMessage() {}
+
+func (x *RemoveIPOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveIPOverrideRequest.ProtoReflect.Descriptor instead.
+func (*RemoveIPOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RemoveIPOverrideRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *RemoveIPOverrideRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *RemoveIPOverrideRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RemoveIPOverrideRequest) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+type RemoveIPOverrideResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveIPOverrideResponse) Reset() {
+	*x = RemoveIPOverrideResponse{}
+	mi := &file_geolize_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveIPOverrideResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveIPOverrideResponse) ProtoMessage() {}
+
+func (x *RemoveIPOverrideResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveIPOverrideResponse.ProtoReflect.Descriptor instead.
+func (*RemoveIPOverrideResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{21}
+}
+
+type ListIPOverridesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ip returns the overrides whose network contains it. A CIDR returns the
+	// overrides whose network lies within it instead.
+	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	// country_iso_code returns the overrides that set this country.
+	CountryIsoCode string `protobuf:"bytes,2,opt,name=country_iso_code,json=countryIsoCode,proto3" json:"country_iso_code,omitempty"`
+	// created_after and created_before bound when the latest override of a
+	// network was made.
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	// page_size defaults to 50 and is capped at 500.
+	PageSize int32 `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page.
+	PageToken     string `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIPOverridesRequest) Reset() {
+	*x = ListIPOverridesRequest{}
+	mi := &file_geolize_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIPOverridesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIPOverridesRequest) ProtoMessage() {}
+
+func (x *ListIPOverridesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIPOverridesRequest.ProtoReflect.Descriptor instead.
+func (*ListIPOverridesRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListIPOverridesRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *ListIPOverridesRequest) GetCountryIsoCode() string {
+	if x != nil {
+		return x.CountryIsoCode
+	}
+	return ""
+}
+
+func (x *ListIPOverridesRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListIPOverridesRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListIPOverridesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListIPOverridesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type IPOverride struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Network string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	// override is the effective override of the network: every override made
+	// for it, applied in the order they were made.
+	Override *ModifyIPRequest `protobuf:"bytes,2,opt,name=override,proto3" json:"override,omitempty"`
+	// version is the database version that introduced the latest override.
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IPOverride) Reset() {
+	*x = IPOverride{}
+	mi := &file_geolize_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPOverride) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPOverride) ProtoMessage() {}
+
+func (x *IPOverride) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPOverride.ProtoReflect.Descriptor instead.
+func (*IPOverride) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *IPOverride) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *IPOverride) GetOverride() *ModifyIPRequest {
+	if x != nil {
+		return x.Override
+	}
+	return nil
+}
+
+func (x *IPOverride) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *IPOverride) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListIPOverridesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// overrides are ordered by network.
+	Overrides []*IPOverride `protobuf:"bytes,1,rep,name=overrides,proto3" json:"overrides,omitempty"`
+	// next_page_token is empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIPOverridesResponse) Reset() {
+	*x = ListIPOverridesResponse{}
+	mi := &file_geolize_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIPOverridesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIPOverridesResponse) ProtoMessage() {}
+
+func (x *ListIPOverridesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIPOverridesResponse.ProtoReflect.Descriptor instead.
+func (*ListIPOverridesResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *LookupIPRequest) GetIps() []string {
+func (x *ListIPOverridesResponse) GetOverrides() []*IPOverride {
 	if x != nil {
-		return x.Ips
+		return x.Overrides
 	}
 	return nil
 }
 
-type LookupIPResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Data          []*IPInfo              `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+func (x *ListIPOverridesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetIPOverrideHistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ip returns the changes made to the networks that contain it, network the
+	// changes made to the networks that overlap it. One of them is required.
+	Ip            string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Network       string `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LookupIPResponse) Reset() {
-	*x = LookupIPResponse{}
-	mi := &file_geolize_service_proto_msgTypes[13]
+func (x *GetIPOverrideHistoryRequest) Reset() {
+	*x = GetIPOverrideHistoryRequest{}
+	mi := &file_geolize_service_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LookupIPResponse) String() string {
+func (x *GetIPOverrideHistoryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LookupIPResponse) ProtoMessage() {}
+func (*GetIPOverrideHistoryRequest) ProtoMessage() {}
 
-func (x *LookupIPResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_geolize_service_proto_msgTypes[13]
+func (x *GetIPOverrideHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -803,49 +2168,51 @@ func (x *LookupIPResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LookupIPResponse.ProtoReflect.Descriptor instead.
-func (*LookupIPResponse) Descriptor() ([]byte, []int) {
-	return file_geolize_service_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use GetIPOverrideHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetIPOverrideHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *LookupIPResponse) GetData() []*IPInfo {
+func (x *GetIPOverrideHistoryRequest) GetIp() string {
 	if x != nil {
-		return x.Data
+		return x.Ip
 	}
-	return nil
+	return ""
 }
 
-type ModifyIPRequest struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	Ip                 string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
-	Continent          *Continent             `protobuf:"bytes,2,opt,name=continent,proto3" json:"continent,omitempty"`
-	Country            *Country               `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
-	Location           *Location              `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
-	Subdivisions       []*Subdivision         `protobuf:"bytes,5,rep,name=subdivisions,proto3" json:"subdivisions,omitempty"`
-	RepresentedCountry *RepresentedCountry    `protobuf:"bytes,6,opt,name=represented_country,json=representedCountry,proto3" json:"represented_country,omitempty"`
-	RegisteredCountry  *RegisteredCountry     `protobuf:"bytes,7,opt,name=registered_country,json=registeredCountry,proto3" json:"registered_country,omitempty"`
-	Traits             *Traits                `protobuf:"bytes,8,opt,name=traits,proto3" json:"traits,omitempty"`
-	Postal             *Postal                `protobuf:"bytes,9,opt,name=postal,proto3" json:"postal,omitempty"`
-	City               *City                  `protobuf:"bytes,10,opt,name=city,proto3" json:"city,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *GetIPOverrideHistoryRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
 }
 
-func (x *ModifyIPRequest) Reset() {
-	*x = ModifyIPRequest{}
-	mi := &file_geolize_service_proto_msgTypes[14]
+type FieldChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// path is the dotted path of the field, e.g. "country.iso_code".
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// before or after is null when the field was added or removed.
+	Before        *structpb.Value `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"`
+	After         *structpb.Value `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_geolize_service_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ModifyIPRequest) String() string {
+func (x *FieldChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ModifyIPRequest) ProtoMessage() {}
+func (*FieldChange) ProtoMessage() {}
 
-func (x *ModifyIPRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_geolize_service_proto_msgTypes[14]
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -856,102 +2223,232 @@ func (x *ModifyIPRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ModifyIPRequest.ProtoReflect.Descriptor instead.
-func (*ModifyIPRequest) Descriptor() ([]byte, []int) {
-	return file_geolize_service_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *ModifyIPRequest) GetIp() string {
+func (x *FieldChange) GetPath() string {
 	if x != nil {
-		return x.Ip
+		return x.Path
 	}
 	return ""
 }
 
-func (x *ModifyIPRequest) GetContinent() *Continent {
+func (x *FieldChange) GetBefore() *structpb.Value {
 	if x != nil {
-		return x.Continent
+		return x.Before
 	}
 	return nil
 }
 
-func (x *ModifyIPRequest) GetCountry() *Country {
+func (x *FieldChange) GetAfter() *structpb.Value {
 	if x != nil {
-		return x.Country
+		return x.After
 	}
 	return nil
 }
 
-func (x *ModifyIPRequest) GetLocation() *Location {
+type IPOverrideChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is the database version the change produced.
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// kind is "override" for ModifyIP, "remove" for RemoveIPOverride and
+	// "rollback" for RollbackToVersion.
+	Kind      string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Network   string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	Author    string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Reason    string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Ticket    string                 `protobuf:"bytes,6,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// before and after are the record of the network's first address.
+	Before  *structpb.Struct `protobuf:"bytes,8,opt,name=before,proto3" json:"before,omitempty"`
+	After   *structpb.Struct `protobuf:"bytes,9,opt,name=after,proto3" json:"after,omitempty"`
+	Changes []*FieldChange   `protobuf:"bytes,10,rep,name=changes,proto3" json:"changes,omitempty"`
+	// reverted is set when a later rollback undid the change.
+	Reverted      bool `protobuf:"varint,11,opt,name=reverted,proto3" json:"reverted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IPOverrideChange) Reset() {
+	*x = IPOverrideChange{}
+	mi := &file_geolize_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPOverrideChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPOverrideChange) ProtoMessage() {}
+
+func (x *IPOverrideChange) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[27]
 	if x != nil {
-		return x.Location
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *ModifyIPRequest) GetSubdivisions() []*Subdivision {
+// Deprecated: Use IPOverrideChange.ProtoReflect.Descriptor instead.
+func (*IPOverrideChange) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *IPOverrideChange) GetVersion() string {
 	if x != nil {
-		return x.Subdivisions
+		return x.Version
 	}
-	return nil
+	return ""
 }
 
-func (x *ModifyIPRequest) GetRepresentedCountry() *RepresentedCountry {
+func (x *IPOverrideChange) GetKind() string {
 	if x != nil {
-		return x.RepresentedCountry
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *IPOverrideChange) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *IPOverrideChange) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *IPOverrideChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *IPOverrideChange) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+func (x *IPOverrideChange) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
 	}
 	return nil
 }
 
-func (x *ModifyIPRequest) GetRegisteredCountry() *RegisteredCountry {
+func (x *IPOverrideChange) GetBefore() *structpb.Struct {
 	if x != nil {
-		return x.RegisteredCountry
+		return x.Before
 	}
 	return nil
 }
 
-func (x *ModifyIPRequest) GetTraits() *Traits {
+func (x *IPOverrideChange) GetAfter() *structpb.Struct {
 	if x != nil {
-		return x.Traits
+		return x.After
 	}
 	return nil
 }
 
-func (x *ModifyIPRequest) GetPostal() *Postal {
+func (x *IPOverrideChange) GetChanges() []*FieldChange {
 	if x != nil {
-		return x.Postal
+		return x.Changes
 	}
 	return nil
 }
 
-func (x *ModifyIPRequest) GetCity() *City {
+func (x *IPOverrideChange) GetReverted() bool {
 	if x != nil {
-		return x.City
+		return x.Reverted
+	}
+	return false
+}
+
+type GetIPOverrideHistoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// changes are in the order they were made.
+	Changes       []*IPOverrideChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIPOverrideHistoryResponse) Reset() {
+	*x = GetIPOverrideHistoryResponse{}
+	mi := &file_geolize_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIPOverrideHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIPOverrideHistoryResponse) ProtoMessage() {}
+
+func (x *GetIPOverrideHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIPOverrideHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetIPOverrideHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetIPOverrideHistoryResponse) GetChanges() []*IPOverrideChange {
+	if x != nil {
+		return x.Changes
 	}
 	return nil
 }
 
-type ModifyIPResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type RollbackToVersionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is a database version, as reported in db_version or in the
+	// override history.
+	Version       string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Ticket        string `protobuf:"bytes,3,opt,name=ticket,proto3" json:"ticket,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ModifyIPResponse) Reset() {
-	*x = ModifyIPResponse{}
-	mi := &file_geolize_service_proto_msgTypes[15]
+func (x *RollbackToVersionRequest) Reset() {
+	*x = RollbackToVersionRequest{}
+	mi := &file_geolize_service_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ModifyIPResponse) String() string {
+func (x *RollbackToVersionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ModifyIPResponse) ProtoMessage() {}
+func (*RollbackToVersionRequest) ProtoMessage() {}
 
-func (x *ModifyIPResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_geolize_service_proto_msgTypes[15]
+func (x *RollbackToVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -962,75 +2459,197 @@ func (x *ModifyIPResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ModifyIPResponse.ProtoReflect.Descriptor instead.
-func (*ModifyIPResponse) Descriptor() ([]byte, []int) {
-	return file_geolize_service_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use RollbackToVersionRequest.ProtoReflect.Descriptor instead.
+func (*RollbackToVersionRequest) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RollbackToVersionRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RollbackToVersionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RollbackToVersionRequest) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+type RollbackToVersionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is the database version the rollback produced.
+	Version       string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackToVersionResponse) Reset() {
+	*x = RollbackToVersionResponse{}
+	mi := &file_geolize_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackToVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackToVersionResponse) ProtoMessage() {}
+
+func (x *RollbackToVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geolize_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackToVersionResponse.ProtoReflect.Descriptor instead.
+func (*RollbackToVersionResponse) Descriptor() ([]byte, []int) {
+	return file_geolize_service_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RollbackToVersionResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
 }
 
 var File_geolize_service_proto protoreflect.FileDescriptor
 
 const file_geolize_service_proto_rawDesc = "" +
 	"\n" +
-	"\x15geolize/service.proto\x12\vdocument_pb\x1a+includes/openapiv2/options/annotation.proto\x1a$includes/google/api/annotation.proto\"\r\n" +
+	"\x15geolize/service.proto\x12\vdocument_pb\x1a+includes/openapiv2/options/annotation.proto\x1a$includes/google/api/annotation.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/protobuf/struct.proto\"\r\n" +
 	"\vPingRequest\"\x0e\n" +
-	"\fPingResponse\"\x92\x01\n" +
+	"\fPingResponse\"\xc5\x01\n" +
 	"\tContinent\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x127\n" +
-	"\x05names\x18\x02 \x03(\v2!.document_pb.Continent.NamesEntryR\x05names\x1a8\n" +
+	"\x05names\x18\x02 \x03(\v2!.document_pb.Continent.NamesEntryR\x05names\x12\x1d\n" +
+	"\n" +
+	"geoname_id\x18\x03 \x01(\rR\tgeonameId\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x1a8\n" +
 	"\n" +
 	"NamesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc6\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x99\x02\n" +
 	"\aCountry\x12\x19\n" +
 	"\biso_code\x18\x01 \x01(\tR\aisoCode\x125\n" +
 	"\x05names\x18\x02 \x03(\v2\x1f.document_pb.Country.NamesEntryR\x05names\x12/\n" +
-	"\x14is_in_european_union\x18\x03 \x01(\bR\x11isInEuropeanUnion\x1a8\n" +
+	"\x14is_in_european_union\x18\x03 \x01(\bR\x11isInEuropeanUnion\x12\x1d\n" +
+	"\n" +
+	"geoname_id\x18\x04 \x01(\rR\tgeonameId\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x05 \x01(\rR\n" +
+	"confidence\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x1a8\n" +
 	"\n" +
 	"NamesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8a\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xff\x01\n" +
 	"\bLocation\x12\x1a\n" +
 	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
 	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12'\n" +
 	"\x0faccuracy_radius\x18\x03 \x01(\rR\x0eaccuracyRadius\x12\x1b\n" +
-	"\ttime_zone\x18\x04 \x01(\tR\btimeZone\"\x9d\x01\n" +
+	"\ttime_zone\x18\x04 \x01(\tR\btimeZone\x12\x1d\n" +
+	"\n" +
+	"metro_code\x18\x05 \x01(\rR\tmetroCode\x12-\n" +
+	"\x12population_density\x18\x06 \x01(\rR\x11populationDensity\x12%\n" +
+	"\x0eaverage_income\x18\a \x01(\rR\raverageIncome\"\xf0\x01\n" +
 	"\vSubdivision\x12\x19\n" +
 	"\biso_code\x18\x01 \x01(\tR\aisoCode\x129\n" +
-	"\x05names\x18\x02 \x03(\v2#.document_pb.Subdivision.NamesEntryR\x05names\x1a8\n" +
+	"\x05names\x18\x02 \x03(\v2#.document_pb.Subdivision.NamesEntryR\x05names\x12\x1d\n" +
+	"\n" +
+	"geoname_id\x18\x03 \x01(\rR\tgeonameId\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\rR\n" +
+	"confidence\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x1a8\n" +
 	"\n" +
 	"NamesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x1c\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"<\n" +
 	"\x06Postal\x12\x12\n" +
-	"\x04code\x18\x01 \x01(\tR\x04code\"t\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\rR\n" +
+	"confidence\"\xc7\x01\n" +
 	"\x04City\x122\n" +
-	"\x05names\x18\x01 \x03(\v2\x1c.document_pb.City.NamesEntryR\x05names\x1a8\n" +
+	"\x05names\x18\x01 \x03(\v2\x1c.document_pb.City.NamesEntryR\x05names\x12\x1d\n" +
+	"\n" +
+	"geoname_id\x18\x02 \x01(\rR\tgeonameId\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x03 \x01(\rR\n" +
+	"confidence\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x1a8\n" +
 	"\n" +
 	"NamesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf0\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa3\x02\n" +
 	"\x12RepresentedCountry\x12\x19\n" +
 	"\biso_code\x18\x01 \x01(\tR\aisoCode\x12@\n" +
 	"\x05names\x18\x02 \x03(\v2*.document_pb.RepresentedCountry.NamesEntryR\x05names\x12\x12\n" +
 	"\x04type\x18\x03 \x01(\tR\x04type\x12/\n" +
-	"\x14is_in_european_union\x18\x04 \x01(\bR\x11isInEuropeanUnion\x1a8\n" +
+	"\x14is_in_european_union\x18\x04 \x01(\bR\x11isInEuropeanUnion\x12\x1d\n" +
+	"\n" +
+	"geoname_id\x18\x05 \x01(\rR\tgeonameId\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x1a8\n" +
 	"\n" +
 	"NamesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xda\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xad\x02\n" +
 	"\x11RegisteredCountry\x12\x19\n" +
 	"\biso_code\x18\x01 \x01(\tR\aisoCode\x12?\n" +
 	"\x05names\x18\x02 \x03(\v2).document_pb.RegisteredCountry.NamesEntryR\x05names\x12/\n" +
-	"\x14is_in_european_union\x18\x03 \x01(\bR\x11isInEuropeanUnion\x1a8\n" +
+	"\x14is_in_european_union\x18\x03 \x01(\bR\x11isInEuropeanUnion\x12\x1d\n" +
+	"\n" +
+	"geoname_id\x18\x04 \x01(\rR\tgeonameId\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x05 \x01(\rR\n" +
+	"confidence\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x1a8\n" +
 	"\n" +
 	"NamesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x89\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xef\x06\n" +
 	"\x06Traits\x12,\n" +
 	"\x12is_anonymous_proxy\x18\x01 \x01(\bR\x10isAnonymousProxy\x12\x1d\n" +
 	"\n" +
 	"is_anycast\x18\x02 \x01(\bR\tisAnycast\x122\n" +
-	"\x15is_satellite_provider\x18\x03 \x01(\bR\x13isSatelliteProvider\"\xb0\x04\n" +
+	"\x15is_satellite_provider\x18\x03 \x01(\bR\x13isSatelliteProvider\x12!\n" +
+	"\fis_anonymous\x18\x04 \x01(\bR\visAnonymous\x12(\n" +
+	"\x10is_anonymous_vpn\x18\x05 \x01(\bR\x0eisAnonymousVpn\x12.\n" +
+	"\x13is_hosting_provider\x18\x06 \x01(\bR\x11isHostingProvider\x12.\n" +
+	"\x13is_legitimate_proxy\x18\a \x01(\bR\x11isLegitimateProxy\x12&\n" +
+	"\x0fis_public_proxy\x18\b \x01(\bR\risPublicProxy\x120\n" +
+	"\x14is_residential_proxy\x18\t \x01(\bR\x12isResidentialProxy\x12'\n" +
+	"\x10is_tor_exit_node\x18\n" +
+	" \x01(\bR\risTorExitNode\x128\n" +
+	"\x18autonomous_system_number\x18\v \x01(\rR\x16autonomousSystemNumber\x12D\n" +
+	"\x1eautonomous_system_organization\x18\f \x01(\tR\x1cautonomousSystemOrganization\x12'\n" +
+	"\x0fconnection_type\x18\r \x01(\tR\x0econnectionType\x12\x16\n" +
+	"\x06domain\x18\x0e \x01(\tR\x06domain\x12\x10\n" +
+	"\x03isp\x18\x0f \x01(\tR\x03isp\x12.\n" +
+	"\x13mobile_country_code\x18\x10 \x01(\tR\x11mobileCountryCode\x12.\n" +
+	"\x13mobile_network_code\x18\x11 \x01(\tR\x11mobileNetworkCode\x12\"\n" +
+	"\forganization\x18\x12 \x01(\tR\forganization\x12\x1b\n" +
+	"\tuser_type\x18\x13 \x01(\tR\buserType\x12&\n" +
+	"\x0fstatic_ip_score\x18\x14 \x01(\x01R\rstaticIpScore\x12\x18\n" +
+	"\anetwork\x18\x15 \x01(\tR\anetwork\"\xee\b\n" +
 	"\x06IPInfo\x12\x0e\n" +
 	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x1d\n" +
 	"\n" +
@@ -1044,11 +2663,45 @@ const file_geolize_service_proto_rawDesc = "" +
 	"\x06traits\x18\t \x01(\v2\x13.document_pb.TraitsR\x06traits\x12+\n" +
 	"\x06postal\x18\n" +
 	" \x01(\v2\x13.document_pb.PostalR\x06postal\x12%\n" +
-	"\x04city\x18\v \x01(\v2\x11.document_pb.CityR\x04city\"#\n" +
+	"\x04city\x18\v \x01(\v2\x11.document_pb.CityR\x04city\x121\n" +
+	"\x06status\x18\f \x01(\x0e2\x19.document_pb.LookupStatusR\x06status\x12\x18\n" +
+	"\amessage\x18\r \x01(\tR\amessage\x12\x14\n" +
+	"\x05found\x18\x0e \x01(\bR\x05found\x12\x18\n" +
+	"\anetwork\x18\x0f \x01(\tR\anetwork\x12\x10\n" +
+	"\x03asn\x18\x10 \x01(\rR\x03asn\x12\x15\n" +
+	"\x06as_org\x18\x11 \x01(\tR\x05asOrg\x12!\n" +
+	"\fis_anonymous\x18\x12 \x01(\bR\visAnonymous\x12\x15\n" +
+	"\x06is_vpn\x18\x13 \x01(\bR\x05isVpn\x12\x15\n" +
+	"\x06is_tor\x18\x14 \x01(\bR\x05isTor\x12.\n" +
+	"\x13is_hosting_provider\x18\x15 \x01(\bR\x11isHostingProvider\x12&\n" +
+	"\x0fis_public_proxy\x18\x16 \x01(\bR\risPublicProxy\x120\n" +
+	"\x14is_residential_proxy\x18\x17 \x01(\bR\x12isResidentialProxy\x12'\n" +
+	"\x0fconnection_type\x18\x18 \x01(\tR\x0econnectionType\x12\x1a\n" +
+	"\bprovider\x18\x19 \x01(\tR\bprovider\x12:\n" +
+	"\asources\x18\x1a \x03(\v2 .document_pb.IPInfo.SourcesEntryR\asources\x1a:\n" +
+	"\fSourcesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9a\x01\n" +
 	"\x0fLookupIPRequest\x12\x10\n" +
-	"\x03ips\x18\x01 \x03(\tR\x03ips\";\n" +
+	"\x03ips\x18\x01 \x03(\tR\x03ips\x12\x1c\n" +
+	"\tlanguages\x18\x02 \x03(\tR\tlanguages\x12#\n" +
+	"\rresolve_names\x18\x03 \x01(\bR\fresolveNames\x122\n" +
+	"\x06fields\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\x06fields\";\n" +
 	"\x10LookupIPResponse\x12'\n" +
-	"\x04data\x18\x01 \x03(\v2\x13.document_pb.IPInfoR\x04data\"\x9a\x04\n" +
+	"\x04data\x18\x01 \x03(\v2\x13.document_pb.IPInfoR\x04data\"\xbf\x01\n" +
+	"\x15StreamLookupIPRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x10\n" +
+	"\x03ips\x18\x02 \x03(\tR\x03ips\x12\x1c\n" +
+	"\tlanguages\x18\x03 \x03(\tR\tlanguages\x12#\n" +
+	"\rresolve_names\x18\x04 \x01(\bR\fresolveNames\x122\n" +
+	"\x06fields\x18\x05 \x01(\v2\x1a.google.protobuf.FieldMaskR\x06fields\"\x8a\x01\n" +
+	"\x16StreamLookupIPResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12'\n" +
+	"\x04data\x18\x02 \x03(\v2\x13.document_pb.IPInfoR\x04data\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\x05R\x04code\"\xa1\x05\n" +
 	"\x0fModifyIPRequest\x12\x0e\n" +
 	"\x02ip\x18\x01 \x01(\tR\x02ip\x124\n" +
 	"\tcontinent\x18\x02 \x01(\v2\x16.document_pb.ContinentR\tcontinent\x12.\n" +
@@ -1060,12 +2713,88 @@ const file_geolize_service_proto_rawDesc = "" +
 	"\x06traits\x18\b \x01(\v2\x13.document_pb.TraitsR\x06traits\x12+\n" +
 	"\x06postal\x18\t \x01(\v2\x13.document_pb.PostalR\x06postal\x12%\n" +
 	"\x04city\x18\n" +
-	" \x01(\v2\x11.document_pb.CityR\x04city\"\x12\n" +
-	"\x10ModifyIPResponse2\xa4\x02\n" +
+	" \x01(\v2\x11.document_pb.CityR\x04city\x12\x18\n" +
+	"\anetwork\x18\v \x01(\tR\anetwork\x12\x16\n" +
+	"\x06reason\x18\f \x01(\tR\x06reason\x12\x16\n" +
+	"\x06ticket\x18\r \x01(\tR\x06ticket\x12\x16\n" +
+	"\x06fields\x18\x0e \x03(\tR\x06fields\x12#\n" +
+	"\rremove_fields\x18\x0f \x03(\tR\fremoveFields\",\n" +
+	"\x10ModifyIPResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"\x82\x01\n" +
+	"\x14BatchModifyIPRequest\x12:\n" +
+	"\toverrides\x18\x01 \x03(\v2\x1c.document_pb.ModifyIPRequestR\toverrides\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06ticket\x18\x03 \x01(\tR\x06ticket\"1\n" +
+	"\x15BatchModifyIPResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"s\n" +
+	"\x17RemoveIPOverrideRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06ticket\x18\x04 \x01(\tR\x06ticket\"\x1a\n" +
+	"\x18RemoveIPOverrideResponse\"\x92\x02\n" +
+	"\x16ListIPOverridesRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12(\n" +
+	"\x10country_iso_code\x18\x02 \x01(\tR\x0ecountryIsoCode\x12?\n" +
+	"\rcreated_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x06 \x01(\tR\tpageToken\"\xb5\x01\n" +
+	"\n" +
+	"IPOverride\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x128\n" +
+	"\boverride\x18\x02 \x01(\v2\x1c.document_pb.ModifyIPRequestR\boverride\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"x\n" +
+	"\x17ListIPOverridesResponse\x125\n" +
+	"\toverrides\x18\x01 \x03(\v2\x17.document_pb.IPOverrideR\toverrides\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"G\n" +
+	"\x1bGetIPOverrideHistoryRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\"\x7f\n" +
+	"\vFieldChange\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12.\n" +
+	"\x06before\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x06before\x12,\n" +
+	"\x05after\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\x05after\"\x8d\x03\n" +
+	"\x10IPOverrideChange\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06ticket\x18\x06 \x01(\tR\x06ticket\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12/\n" +
+	"\x06before\x18\b \x01(\v2\x17.google.protobuf.StructR\x06before\x12-\n" +
+	"\x05after\x18\t \x01(\v2\x17.google.protobuf.StructR\x05after\x122\n" +
+	"\achanges\x18\n" +
+	" \x03(\v2\x18.document_pb.FieldChangeR\achanges\x12\x1a\n" +
+	"\breverted\x18\v \x01(\bR\breverted\"W\n" +
+	"\x1cGetIPOverrideHistoryResponse\x127\n" +
+	"\achanges\x18\x01 \x03(\v2\x1d.document_pb.IPOverrideChangeR\achanges\"d\n" +
+	"\x18RollbackToVersionRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06ticket\x18\x03 \x01(\tR\x06ticket\"5\n" +
+	"\x19RollbackToVersionResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion*{\n" +
+	"\fLookupStatus\x12\x14\n" +
+	"\x10LOOKUP_STATUS_OK\x10\x00\x12\x1c\n" +
+	"\x18LOOKUP_STATUS_INVALID_IP\x10\x01\x12\x1b\n" +
+	"\x17LOOKUP_STATUS_NOT_FOUND\x10\x02\x12\x1a\n" +
+	"\x16LOOKUP_STATUS_INTERNAL\x10\x032\x9d\b\n" +
 	"\aGeolize\x12J\n" +
 	"\x04Ping\x12\x18.document_pb.PingRequest\x1a\x19.document_pb.PingResponse\"\r\x82\xd3\xe4\x93\x02\a\x12\x05/ping\x12d\n" +
-	"\bLookupIP\x12\x1c.document_pb.LookupIPRequest\x1a\x1d.document_pb.LookupIPResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/geoip/lookup-ip\x12g\n" +
-	"\bModifyIP\x12\x1c.document_pb.ModifyIPRequest\x1a\x1d.document_pb.ModifyIPResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/geoip/modify-ipB}\x92Af\x12<\n" +
+	"\bLookupIP\x12\x1c.document_pb.LookupIPRequest\x1a\x1d.document_pb.LookupIPResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/geoip/lookup-ip\x12]\n" +
+	"\x0eStreamLookupIP\x12\".document_pb.StreamLookupIPRequest\x1a#.document_pb.StreamLookupIPResponse(\x010\x01\x12g\n" +
+	"\bModifyIP\x12\x1c.document_pb.ModifyIPRequest\x1a\x1d.document_pb.ModifyIPResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/geoip/modify-ip\x12|\n" +
+	"\rBatchModifyIP\x12!.document_pb.BatchModifyIPRequest\x1a\".document_pb.BatchModifyIPResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/geoip/batch-modify-ip\x12\x88\x01\n" +
+	"\x10RemoveIPOverride\x12$.document_pb.RemoveIPOverrideRequest\x1a%.document_pb.RemoveIPOverrideResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/geoip/remove-ip-override\x12y\n" +
+	"\x0fListIPOverrides\x12#.document_pb.ListIPOverridesRequest\x1a$.document_pb.ListIPOverridesResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/geoip/overrides\x12\x8f\x01\n" +
+	"\x14GetIPOverrideHistory\x12(.document_pb.GetIPOverrideHistoryRequest\x1a).document_pb.GetIPOverrideHistoryResponse\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/v1/geoip/override-history\x12\x81\x01\n" +
+	"\x11RollbackToVersion\x12%.document_pb.RollbackToVersionRequest\x1a&.document_pb.RollbackToVersionResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/geoip/rollbackB}\x92Af\x12<\n" +
 	"\vGeolize API\"!\n" +
 	"\x05SANGO\x1a\x18sangnguyen.itp@gmail.com*\x05\n" +
 	"\x03MIT2\x031.0*\x02\x01\x022\x10application/json:\x10application/jsonZ\x12geolize/geolize_pbb\x06proto3"
@@ -1082,68 +2811,120 @@ func file_geolize_service_proto_rawDescGZIP() []byte {
 	return file_geolize_service_proto_rawDescData
 }
 
-var file_geolize_service_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_geolize_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_geolize_service_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
 var file_geolize_service_proto_goTypes = []any{
-	(*PingRequest)(nil),        // 0: document_pb.PingRequest
-	(*PingResponse)(nil),       // 1: document_pb.PingResponse
-	(*Continent)(nil),          // 2: document_pb.Continent
-	(*Country)(nil),            // 3: document_pb.Country
-	(*Location)(nil),           // 4: document_pb.Location
-	(*Subdivision)(nil),        // 5: document_pb.Subdivision
-	(*Postal)(nil),             // 6: document_pb.Postal
-	(*City)(nil),               // 7: document_pb.City
-	(*RepresentedCountry)(nil), // 8: document_pb.RepresentedCountry
-	(*RegisteredCountry)(nil),  // 9: document_pb.RegisteredCountry
-	(*Traits)(nil),             // 10: document_pb.Traits
-	(*IPInfo)(nil),             // 11: document_pb.IPInfo
-	(*LookupIPRequest)(nil),    // 12: document_pb.LookupIPRequest
-	(*LookupIPResponse)(nil),   // 13: document_pb.LookupIPResponse
-	(*ModifyIPRequest)(nil),    // 14: document_pb.ModifyIPRequest
-	(*ModifyIPResponse)(nil),   // 15: document_pb.ModifyIPResponse
-	nil,                        // 16: document_pb.Continent.NamesEntry
-	nil,                        // 17: document_pb.Country.NamesEntry
-	nil,                        // 18: document_pb.Subdivision.NamesEntry
-	nil,                        // 19: document_pb.City.NamesEntry
-	nil,                        // 20: document_pb.RepresentedCountry.NamesEntry
-	nil,                        // 21: document_pb.RegisteredCountry.NamesEntry
+	(LookupStatus)(0),                    // 0: document_pb.LookupStatus
+	(*PingRequest)(nil),                  // 1: document_pb.PingRequest
+	(*PingResponse)(nil),                 // 2: document_pb.PingResponse
+	(*Continent)(nil),                    // 3: document_pb.Continent
+	(*Country)(nil),                      // 4: document_pb.Country
+	(*Location)(nil),                     // 5: document_pb.Location
+	(*Subdivision)(nil),                  // 6: document_pb.Subdivision
+	(*Postal)(nil),                       // 7: document_pb.Postal
+	(*City)(nil),                         // 8: document_pb.City
+	(*RepresentedCountry)(nil),           // 9: document_pb.RepresentedCountry
+	(*RegisteredCountry)(nil),            // 10: document_pb.RegisteredCountry
+	(*Traits)(nil),                       // 11: document_pb.Traits
+	(*IPInfo)(nil),                       // 12: document_pb.IPInfo
+	(*LookupIPRequest)(nil),              // 13: document_pb.LookupIPRequest
+	(*LookupIPResponse)(nil),             // 14: document_pb.LookupIPResponse
+	(*StreamLookupIPRequest)(nil),        // 15: document_pb.StreamLookupIPRequest
+	(*StreamLookupIPResponse)(nil),       // 16: document_pb.StreamLookupIPResponse
+	(*ModifyIPRequest)(nil),              // 17: document_pb.ModifyIPRequest
+	(*ModifyIPResponse)(nil),             // 18: document_pb.ModifyIPResponse
+	(*BatchModifyIPRequest)(nil),         // 19: document_pb.BatchModifyIPRequest
+	(*BatchModifyIPResponse)(nil),        // 20: document_pb.BatchModifyIPResponse
+	(*RemoveIPOverrideRequest)(nil),      // 21: document_pb.RemoveIPOverrideRequest
+	(*RemoveIPOverrideResponse)(nil),     // 22: document_pb.RemoveIPOverrideResponse
+	(*ListIPOverridesRequest)(nil),       // 23: document_pb.ListIPOverridesRequest
+	(*IPOverride)(nil),                   // 24: document_pb.IPOverride
+	(*ListIPOverridesResponse)(nil),      // 25: document_pb.ListIPOverridesResponse
+	(*GetIPOverrideHistoryRequest)(nil),  // 26: document_pb.GetIPOverrideHistoryRequest
+	(*FieldChange)(nil),                  // 27: document_pb.FieldChange
+	(*IPOverrideChange)(nil),             // 28: document_pb.IPOverrideChange
+	(*GetIPOverrideHistoryResponse)(nil), // 29: document_pb.GetIPOverrideHistoryResponse
+	(*RollbackToVersionRequest)(nil),     // 30: document_pb.RollbackToVersionRequest
+	(*RollbackToVersionResponse)(nil),    // 31: document_pb.RollbackToVersionResponse
+	nil,                                  // 32: document_pb.Continent.NamesEntry
+	nil,                                  // 33: document_pb.Country.NamesEntry
+	nil,                                  // 34: document_pb.Subdivision.NamesEntry
+	nil,                                  // 35: document_pb.City.NamesEntry
+	nil,                                  // 36: document_pb.RepresentedCountry.NamesEntry
+	nil,                                  // 37: document_pb.RegisteredCountry.NamesEntry
+	nil,                                  // 38: document_pb.IPInfo.SourcesEntry
+	(*fieldmaskpb.FieldMask)(nil),        // 39: google.protobuf.FieldMask
+	(*timestamppb.Timestamp)(nil),        // 40: google.protobuf.Timestamp
+	(*structpb.Value)(nil),               // 41: google.protobuf.Value
+	(*structpb.Struct)(nil),              // 42: google.protobuf.Struct
 }
 var file_geolize_service_proto_depIdxs = []int32{
-	16, // 0: document_pb.Continent.names:type_name -> document_pb.Continent.NamesEntry
-	17, // 1: document_pb.Country.names:type_name -> document_pb.Country.NamesEntry
-	18, // 2: document_pb.Subdivision.names:type_name -> document_pb.Subdivision.NamesEntry
-	19, // 3: document_pb.City.names:type_name -> document_pb.City.NamesEntry
-	20, // 4: document_pb.RepresentedCountry.names:type_name -> document_pb.RepresentedCountry.NamesEntry
-	21, // 5: document_pb.RegisteredCountry.names:type_name -> document_pb.RegisteredCountry.NamesEntry
-	2,  // 6: document_pb.IPInfo.continent:type_name -> document_pb.Continent
-	3,  // 7: document_pb.IPInfo.country:type_name -> document_pb.Country
-	4,  // 8: document_pb.IPInfo.location:type_name -> document_pb.Location
-	5,  // 9: document_pb.IPInfo.subdivisions:type_name -> document_pb.Subdivision
-	8,  // 10: document_pb.IPInfo.represented_country:type_name -> document_pb.RepresentedCountry
-	9,  // 11: document_pb.IPInfo.registered_country:type_name -> document_pb.RegisteredCountry
-	10, // 12: document_pb.IPInfo.traits:type_name -> document_pb.Traits
-	6,  // 13: document_pb.IPInfo.postal:type_name -> document_pb.Postal
-	7,  // 14: document_pb.IPInfo.city:type_name -> document_pb.City
-	11, // 15: document_pb.LookupIPResponse.data:type_name -> document_pb.IPInfo
-	2,  // 16: document_pb.ModifyIPRequest.continent:type_name -> document_pb.Continent
-	3,  // 17: document_pb.ModifyIPRequest.country:type_name -> document_pb.Country
-	4,  // 18: document_pb.ModifyIPRequest.location:type_name -> document_pb.Location
-	5,  // 19: document_pb.ModifyIPRequest.subdivisions:type_name -> document_pb.Subdivision
-	8,  // 20: document_pb.ModifyIPRequest.represented_country:type_name -> document_pb.RepresentedCountry
-	9,  // 21: document_pb.ModifyIPRequest.registered_country:type_name -> document_pb.RegisteredCountry
-	10, // 22: document_pb.ModifyIPRequest.traits:type_name -> document_pb.Traits
-	6,  // 23: document_pb.ModifyIPRequest.postal:type_name -> document_pb.Postal
-	7,  // 24: document_pb.ModifyIPRequest.city:type_name -> document_pb.City
-	0,  // 25: document_pb.Geolize.Ping:input_type -> document_pb.PingRequest
-	12, // 26: document_pb.Geolize.LookupIP:input_type -> document_pb.LookupIPRequest
-	14, // 27: document_pb.Geolize.ModifyIP:input_type -> document_pb.ModifyIPRequest
-	1,  // 28: document_pb.Geolize.Ping:output_type -> document_pb.PingResponse
-	13, // 29: document_pb.Geolize.LookupIP:output_type -> document_pb.LookupIPResponse
-	15, // 30: document_pb.Geolize.ModifyIP:output_type -> document_pb.ModifyIPResponse
-	28, // [28:31] is the sub-list for method output_type
-	25, // [25:28] is the sub-list for method input_type
-	25, // [25:25] is the sub-list for extension type_name
-	25, // [25:25] is the sub-list for extension extendee
-	0,  // [0:25] is the sub-list for field type_name
+	32, // 0: document_pb.Continent.names:type_name -> document_pb.Continent.NamesEntry
+	33, // 1: document_pb.Country.names:type_name -> document_pb.Country.NamesEntry
+	34, // 2: document_pb.Subdivision.names:type_name -> document_pb.Subdivision.NamesEntry
+	35, // 3: document_pb.City.names:type_name -> document_pb.City.NamesEntry
+	36, // 4: document_pb.RepresentedCountry.names:type_name -> document_pb.RepresentedCountry.NamesEntry
+	37, // 5: document_pb.RegisteredCountry.names:type_name -> document_pb.RegisteredCountry.NamesEntry
+	3,  // 6: document_pb.IPInfo.continent:type_name -> document_pb.Continent
+	4,  // 7: document_pb.IPInfo.country:type_name -> document_pb.Country
+	5,  // 8: document_pb.IPInfo.location:type_name -> document_pb.Location
+	6,  // 9: document_pb.IPInfo.subdivisions:type_name -> document_pb.Subdivision
+	9,  // 10: document_pb.IPInfo.represented_country:type_name -> document_pb.RepresentedCountry
+	10, // 11: document_pb.IPInfo.registered_country:type_name -> document_pb.RegisteredCountry
+	11, // 12: document_pb.IPInfo.traits:type_name -> document_pb.Traits
+	7,  // 13: document_pb.IPInfo.postal:type_name -> document_pb.Postal
+	8,  // 14: document_pb.IPInfo.city:type_name -> document_pb.City
+	0,  // 15: document_pb.IPInfo.status:type_name -> document_pb.LookupStatus
+	38, // 16: document_pb.IPInfo.sources:type_name -> document_pb.IPInfo.SourcesEntry
+	39, // 17: document_pb.LookupIPRequest.fields:type_name -> google.protobuf.FieldMask
+	12, // 18: document_pb.LookupIPResponse.data:type_name -> document_pb.IPInfo
+	39, // 19: document_pb.StreamLookupIPRequest.fields:type_name -> google.protobuf.FieldMask
+	12, // 20: document_pb.StreamLookupIPResponse.data:type_name -> document_pb.IPInfo
+	3,  // 21: document_pb.ModifyIPRequest.continent:type_name -> document_pb.Continent
+	4,  // 22: document_pb.ModifyIPRequest.country:type_name -> document_pb.Country
+	5,  // 23: document_pb.ModifyIPRequest.location:type_name -> document_pb.Location
+	6,  // 24: document_pb.ModifyIPRequest.subdivisions:type_name -> document_pb.Subdivision
+	9,  // 25: document_pb.ModifyIPRequest.represented_country:type_name -> document_pb.RepresentedCountry
+	10, // 26: document_pb.ModifyIPRequest.registered_country:type_name -> document_pb.RegisteredCountry
+	11, // 27: document_pb.ModifyIPRequest.traits:type_name -> document_pb.Traits
+	7,  // 28: document_pb.ModifyIPRequest.postal:type_name -> document_pb.Postal
+	8,  // 29: document_pb.ModifyIPRequest.city:type_name -> document_pb.City
+	17, // 30: document_pb.BatchModifyIPRequest.overrides:type_name -> document_pb.ModifyIPRequest
+	40, // 31: document_pb.ListIPOverridesRequest.created_after:type_name -> google.protobuf.Timestamp
+	40, // 32: document_pb.ListIPOverridesRequest.created_before:type_name -> google.protobuf.Timestamp
+	17, // 33: document_pb.IPOverride.override:type_name -> document_pb.ModifyIPRequest
+	40, // 34: document_pb.IPOverride.created_at:type_name -> google.protobuf.Timestamp
+	24, // 35: document_pb.ListIPOverridesResponse.overrides:type_name -> document_pb.IPOverride
+	41, // 36: document_pb.FieldChange.before:type_name -> google.protobuf.Value
+	41, // 37: document_pb.FieldChange.after:type_name -> google.protobuf.Value
+	40, // 38: document_pb.IPOverrideChange.created_at:type_name -> google.protobuf.Timestamp
+	42, // 39: document_pb.IPOverrideChange.before:type_name -> google.protobuf.Struct
+	42, // 40: document_pb.IPOverrideChange.after:type_name -> google.protobuf.Struct
+	27, // 41: document_pb.IPOverrideChange.changes:type_name -> document_pb.FieldChange
+	28, // 42: document_pb.GetIPOverrideHistoryResponse.changes:type_name -> document_pb.IPOverrideChange
+	1,  // 43: document_pb.Geolize.Ping:input_type -> document_pb.PingRequest
+	13, // 44: document_pb.Geolize.LookupIP:input_type -> document_pb.LookupIPRequest
+	15, // 45: document_pb.Geolize.StreamLookupIP:input_type -> document_pb.StreamLookupIPRequest
+	17, // 46: document_pb.Geolize.ModifyIP:input_type -> document_pb.ModifyIPRequest
+	19, // 47: document_pb.Geolize.BatchModifyIP:input_type -> document_pb.BatchModifyIPRequest
+	21, // 48: document_pb.Geolize.RemoveIPOverride:input_type -> document_pb.RemoveIPOverrideRequest
+	23, // 49: document_pb.Geolize.ListIPOverrides:input_type -> document_pb.ListIPOverridesRequest
+	26, // 50: document_pb.Geolize.GetIPOverrideHistory:input_type -> document_pb.GetIPOverrideHistoryRequest
+	30, // 51: document_pb.Geolize.RollbackToVersion:input_type -> document_pb.RollbackToVersionRequest
+	2,  // 52: document_pb.Geolize.Ping:output_type -> document_pb.PingResponse
+	14, // 53: document_pb.Geolize.LookupIP:output_type -> document_pb.LookupIPResponse
+	16, // 54: document_pb.Geolize.StreamLookupIP:output_type -> document_pb.StreamLookupIPResponse
+	18, // 55: document_pb.Geolize.ModifyIP:output_type -> document_pb.ModifyIPResponse
+	20, // 56: document_pb.Geolize.BatchModifyIP:output_type -> document_pb.BatchModifyIPResponse
+	22, // 57: document_pb.Geolize.RemoveIPOverride:output_type -> document_pb.RemoveIPOverrideResponse
+	25, // 58: document_pb.Geolize.ListIPOverrides:output_type -> document_pb.ListIPOverridesResponse
+	29, // 59: document_pb.Geolize.GetIPOverrideHistory:output_type -> document_pb.GetIPOverrideHistoryResponse
+	31, // 60: document_pb.Geolize.RollbackToVersion:output_type -> document_pb.RollbackToVersionResponse
+	52, // [52:61] is the sub-list for method output_type
+	43, // [43:52] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
 }
 
 func init() { file_geolize_service_proto_init() }
@@ -1156,13 +2937,14 @@ func file_geolize_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_geolize_service_proto_rawDesc), len(file_geolize_service_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   22,
+			NumEnums:      1,
+			NumMessages:   38,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_geolize_service_proto_goTypes,
 		DependencyIndexes: file_geolize_service_proto_depIdxs,
+		EnumInfos:         file_geolize_service_proto_enumTypes,
 		MessageInfos:      file_geolize_service_proto_msgTypes,
 	}.Build()
 	File_geolize_service_proto = out.File
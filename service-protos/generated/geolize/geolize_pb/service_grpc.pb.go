@@ -19,9 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Geolize_Ping_FullMethodName     = "/document_pb.Geolize/Ping"
-	Geolize_LookupIP_FullMethodName = "/document_pb.Geolize/LookupIP"
-	Geolize_ModifyIP_FullMethodName = "/document_pb.Geolize/ModifyIP"
+	Geolize_Ping_FullMethodName                 = "/document_pb.Geolize/Ping"
+	Geolize_LookupIP_FullMethodName             = "/document_pb.Geolize/LookupIP"
+	Geolize_StreamLookupIP_FullMethodName       = "/document_pb.Geolize/StreamLookupIP"
+	Geolize_ModifyIP_FullMethodName             = "/document_pb.Geolize/ModifyIP"
+	Geolize_BatchModifyIP_FullMethodName        = "/document_pb.Geolize/BatchModifyIP"
+	Geolize_RemoveIPOverride_FullMethodName     = "/document_pb.Geolize/RemoveIPOverride"
+	Geolize_ListIPOverrides_FullMethodName      = "/document_pb.Geolize/ListIPOverrides"
+	Geolize_GetIPOverrideHistory_FullMethodName = "/document_pb.Geolize/GetIPOverrideHistory"
+	Geolize_RollbackToVersion_FullMethodName    = "/document_pb.Geolize/RollbackToVersion"
 )
 
 // GeolizeClient is the client API for Geolize service.
@@ -30,7 +36,25 @@ const (
 type GeolizeClient interface {
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
 	LookupIP(ctx context.Context, in *LookupIPRequest, opts ...grpc.CallOption) (*LookupIPResponse, error)
+	// StreamLookupIP geolocates batches over a single bidirectional stream.
+	// Responses may arrive out of order and are matched by request_id.
+	StreamLookupIP(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamLookupIPRequest, StreamLookupIPResponse], error)
 	ModifyIP(ctx context.Context, in *ModifyIPRequest, opts ...grpc.CallOption) (*ModifyIPResponse, error)
+	// BatchModifyIP applies many overrides at once, with a single database
+	// rebuild and version. Either every override is applied or, when one of
+	// them is invalid, none is.
+	BatchModifyIP(ctx context.Context, in *BatchModifyIPRequest, opts ...grpc.CallOption) (*BatchModifyIPResponse, error)
+	// RemoveIPOverride drops the overrides made for an IP or network and
+	// restores its original record from the base database.
+	RemoveIPOverride(ctx context.Context, in *RemoveIPOverrideRequest, opts ...grpc.CallOption) (*RemoveIPOverrideResponse, error)
+	// ListIPOverrides lists the networks that have been overridden.
+	ListIPOverrides(ctx context.Context, in *ListIPOverridesRequest, opts ...grpc.CallOption) (*ListIPOverridesResponse, error)
+	// GetIPOverrideHistory returns who changed an IP or network, when, why and
+	// what changed.
+	GetIPOverrideHistory(ctx context.Context, in *GetIPOverrideHistoryRequest, opts ...grpc.CallOption) (*GetIPOverrideHistoryResponse, error)
+	// RollbackToVersion undoes every change made after an earlier version. The
+	// undone changes stay in the override history, marked as reverted.
+	RollbackToVersion(ctx context.Context, in *RollbackToVersionRequest, opts ...grpc.CallOption) (*RollbackToVersionResponse, error)
 }
 
 type geolizeClient struct {
@@ -61,6 +85,19 @@ func (c *geolizeClient) LookupIP(ctx context.Context, in *LookupIPRequest, opts
 	return out, nil
 }
 
+func (c *geolizeClient) StreamLookupIP(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamLookupIPRequest, StreamLookupIPResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Geolize_ServiceDesc.Streams[0], Geolize_StreamLookupIP_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLookupIPRequest, StreamLookupIPResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Geolize_StreamLookupIPClient = grpc.BidiStreamingClient[StreamLookupIPRequest, StreamLookupIPResponse]
+
 func (c *geolizeClient) ModifyIP(ctx context.Context, in *ModifyIPRequest, opts ...grpc.CallOption) (*ModifyIPResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ModifyIPResponse)
@@ -71,13 +108,81 @@ func (c *geolizeClient) ModifyIP(ctx context.Context, in *ModifyIPRequest, opts
 	return out, nil
 }
 
+func (c *geolizeClient) BatchModifyIP(ctx context.Context, in *BatchModifyIPRequest, opts ...grpc.CallOption) (*BatchModifyIPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchModifyIPResponse)
+	err := c.cc.Invoke(ctx, Geolize_BatchModifyIP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geolizeClient) RemoveIPOverride(ctx context.Context, in *RemoveIPOverrideRequest, opts ...grpc.CallOption) (*RemoveIPOverrideResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveIPOverrideResponse)
+	err := c.cc.Invoke(ctx, Geolize_RemoveIPOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geolizeClient) ListIPOverrides(ctx context.Context, in *ListIPOverridesRequest, opts ...grpc.CallOption) (*ListIPOverridesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIPOverridesResponse)
+	err := c.cc.Invoke(ctx, Geolize_ListIPOverrides_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geolizeClient) GetIPOverrideHistory(ctx context.Context, in *GetIPOverrideHistoryRequest, opts ...grpc.CallOption) (*GetIPOverrideHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetIPOverrideHistoryResponse)
+	err := c.cc.Invoke(ctx, Geolize_GetIPOverrideHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geolizeClient) RollbackToVersion(ctx context.Context, in *RollbackToVersionRequest, opts ...grpc.CallOption) (*RollbackToVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackToVersionResponse)
+	err := c.cc.Invoke(ctx, Geolize_RollbackToVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GeolizeServer is the server API for Geolize service.
 // All implementations should embed UnimplementedGeolizeServer
 // for forward compatibility.
 type GeolizeServer interface {
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
 	LookupIP(context.Context, *LookupIPRequest) (*LookupIPResponse, error)
+	// StreamLookupIP geolocates batches over a single bidirectional stream.
+	// Responses may arrive out of order and are matched by request_id.
+	StreamLookupIP(grpc.BidiStreamingServer[StreamLookupIPRequest, StreamLookupIPResponse]) error
 	ModifyIP(context.Context, *ModifyIPRequest) (*ModifyIPResponse, error)
+	// BatchModifyIP applies many overrides at once, with a single database
+	// rebuild and version. Either every override is applied or, when one of
+	// them is invalid, none is.
+	BatchModifyIP(context.Context, *BatchModifyIPRequest) (*BatchModifyIPResponse, error)
+	// RemoveIPOverride drops the overrides made for an IP or network and
+	// restores its original record from the base database.
+	RemoveIPOverride(context.Context, *RemoveIPOverrideRequest) (*RemoveIPOverrideResponse, error)
+	// ListIPOverrides lists the networks that have been overridden.
+	ListIPOverrides(context.Context, *ListIPOverridesRequest) (*ListIPOverridesResponse, error)
+	// GetIPOverrideHistory returns who changed an IP or network, when, why and
+	// what changed.
+	GetIPOverrideHistory(context.Context, *GetIPOverrideHistoryRequest) (*GetIPOverrideHistoryResponse, error)
+	// RollbackToVersion undoes every change made after an earlier version. The
+	// undone changes stay in the override history, marked as reverted.
+	RollbackToVersion(context.Context, *RollbackToVersionRequest) (*RollbackToVersionResponse, error)
 }
 
 // UnimplementedGeolizeServer should be embedded to have
@@ -93,9 +198,27 @@ func (UnimplementedGeolizeServer) Ping(context.Context, *PingRequest) (*PingResp
 func (UnimplementedGeolizeServer) LookupIP(context.Context, *LookupIPRequest) (*LookupIPResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LookupIP not implemented")
 }
+func (UnimplementedGeolizeServer) StreamLookupIP(grpc.BidiStreamingServer[StreamLookupIPRequest, StreamLookupIPResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLookupIP not implemented")
+}
 func (UnimplementedGeolizeServer) ModifyIP(context.Context, *ModifyIPRequest) (*ModifyIPResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ModifyIP not implemented")
 }
+func (UnimplementedGeolizeServer) BatchModifyIP(context.Context, *BatchModifyIPRequest) (*BatchModifyIPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchModifyIP not implemented")
+}
+func (UnimplementedGeolizeServer) RemoveIPOverride(context.Context, *RemoveIPOverrideRequest) (*RemoveIPOverrideResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveIPOverride not implemented")
+}
+func (UnimplementedGeolizeServer) ListIPOverrides(context.Context, *ListIPOverridesRequest) (*ListIPOverridesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIPOverrides not implemented")
+}
+func (UnimplementedGeolizeServer) GetIPOverrideHistory(context.Context, *GetIPOverrideHistoryRequest) (*GetIPOverrideHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIPOverrideHistory not implemented")
+}
+func (UnimplementedGeolizeServer) RollbackToVersion(context.Context, *RollbackToVersionRequest) (*RollbackToVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackToVersion not implemented")
+}
 func (UnimplementedGeolizeServer) testEmbeddedByValue() {}
 
 // UnsafeGeolizeServer may be embedded to opt out of forward compatibility for this service.
@@ -152,6 +275,13 @@ func _Geolize_LookupIP_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Geolize_StreamLookupIP_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GeolizeServer).StreamLookupIP(&grpc.GenericServerStream[StreamLookupIPRequest, StreamLookupIPResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Geolize_StreamLookupIPServer = grpc.BidiStreamingServer[StreamLookupIPRequest, StreamLookupIPResponse]
+
 func _Geolize_ModifyIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ModifyIPRequest)
 	if err := dec(in); err != nil {
@@ -170,6 +300,96 @@ func _Geolize_ModifyIP_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Geolize_BatchModifyIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchModifyIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeolizeServer).BatchModifyIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Geolize_BatchModifyIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeolizeServer).BatchModifyIP(ctx, req.(*BatchModifyIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Geolize_RemoveIPOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveIPOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeolizeServer).RemoveIPOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Geolize_RemoveIPOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeolizeServer).RemoveIPOverride(ctx, req.(*RemoveIPOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Geolize_ListIPOverrides_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIPOverridesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeolizeServer).ListIPOverrides(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Geolize_ListIPOverrides_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeolizeServer).ListIPOverrides(ctx, req.(*ListIPOverridesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Geolize_GetIPOverrideHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIPOverrideHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeolizeServer).GetIPOverrideHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Geolize_GetIPOverrideHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeolizeServer).GetIPOverrideHistory(ctx, req.(*GetIPOverrideHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Geolize_RollbackToVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackToVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeolizeServer).RollbackToVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Geolize_RollbackToVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeolizeServer).RollbackToVersion(ctx, req.(*RollbackToVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Geolize_ServiceDesc is the grpc.ServiceDesc for Geolize service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -189,7 +409,34 @@ var Geolize_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ModifyIP",
 			Handler:    _Geolize_ModifyIP_Handler,
 		},
+		{
+			MethodName: "BatchModifyIP",
+			Handler:    _Geolize_BatchModifyIP_Handler,
+		},
+		{
+			MethodName: "RemoveIPOverride",
+			Handler:    _Geolize_RemoveIPOverride_Handler,
+		},
+		{
+			MethodName: "ListIPOverrides",
+			Handler:    _Geolize_ListIPOverrides_Handler,
+		},
+		{
+			MethodName: "GetIPOverrideHistory",
+			Handler:    _Geolize_GetIPOverrideHistory_Handler,
+		},
+		{
+			MethodName: "RollbackToVersion",
+			Handler:    _Geolize_RollbackToVersion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLookupIP",
+			Handler:       _Geolize_StreamLookupIP_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "geolize/service.proto",
 }
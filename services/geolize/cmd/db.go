@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/maxmind"
+	"geolize/utilities/logging"
+	"geolize/utilities/service"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	migrateBase string
+
+	rollbackAddr   string
+	rollbackReason string
+	rollbackTicket string
+	rollbackUser   string
+)
+
+// dbCmd groups the commands that maintain the database and its overrides
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Maintain the database and its overrides",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// dbMigrateCmd imports the JSON history files into the override store
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Import the JSON history files into the override store",
+	Long: `Import the history files of data/histories into the SQLite override store.
+Files that were already imported are skipped, so the command can be run again safely.
+Pass the original database the histories were applied to with --base, so their
+overrides can be removed and rolled back. Restart the service afterwards so it picks
+up the imported history.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		migrate()
+	},
+}
+
+func migrate() {
+	logger, err := logging.NewLogger(logging.ZapLoggerType)
+	if err != nil {
+		panic(err)
+	}
+
+	imported, err := maxmind.MigrateHistories(context.Background(), logger, migrateBase)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Imported %d history files\n", imported)
+}
+
+// dbRollbackCmd rolls the database of a running service back to an earlier version
+var dbRollbackCmd = &cobra.Command{
+	Use:   "rollback <version>",
+	Short: "Roll the database back to an earlier override version",
+	Long: `Roll the database of the running service back to an earlier version, as reported
+in db_version or in the override history. The changes made after it are kept in the
+history, marked as reverted, and the rollback becomes the new version.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rollback(args[0])
+	},
+}
+
+func rollback(version string) {
+	conn, err := grpc.NewClient(rollbackAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-user", rollbackUser)
+
+	response, err := geolize_pb.NewGeolizeClient(conn).RollbackToVersion(ctx, &geolize_pb.RollbackToVersionRequest{
+		Version: version,
+		Reason:  rollbackReason,
+		Ticket:  rollbackTicket,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Rolled back to %s, the database is now at version %s\n", version, response.Version)
+}
+
+func init() {
+	dbMigrateCmd.Flags().StringVar(&migrateBase, "base", "", "original database the history files were applied to")
+
+	dbRollbackCmd.Flags().StringVar(&rollbackAddr, "addr", fmt.Sprintf("localhost:%d", service.GetPort()), "address of the running service")
+	dbRollbackCmd.Flags().StringVar(&rollbackReason, "reason", "", "why the database is rolled back (required)")
+	dbRollbackCmd.Flags().StringVar(&rollbackTicket, "ticket", "", "ticket tracking the rollback")
+	dbRollbackCmd.Flags().StringVar(&rollbackUser, "user", os.Getenv("USER"), "who rolls the database back")
+	dbRollbackCmd.MarkFlagRequired("reason")
+
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbRollbackCmd)
+	rootCmd.AddCommand(dbCmd)
+}
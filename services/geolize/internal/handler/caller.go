@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"geolize/utilities/contexts"
+	"strings"
+
+	"google.golang.org/grpc/peer"
+)
+
+// callerHeaders are the metadata keys that identify the caller, in order of
+// preference. HTTP callers set the X-User header.
+var callerHeaders = []string{"x-user"}
+
+// requestCaller identifies who made the request for the change log. Callers
+// that do not say who they are are recorded by address.
+func requestCaller(ctx context.Context) string {
+	if data := contexts.GetServerData(ctx); data != nil {
+		for _, header := range callerHeaders {
+			if values := data.IncomingHeaders[header]; len(values) > 0 && len(strings.TrimSpace(values[0])) > 0 {
+				return strings.TrimSpace(values[0])
+			}
+		}
+		// grpc-gateway records the HTTP client, the peer is the gateway itself.
+		if values := data.IncomingHeaders["x-forwarded-for"]; len(values) > 0 {
+			return "anonymous@" + values[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		return "anonymous@" + p.Addr.String()
+	}
+
+	return "anonymous"
+}
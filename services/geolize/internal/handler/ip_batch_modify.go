@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/validation"
+	"geolize/utilities/logging"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s Service) BatchModifyIP(ctx context.Context, request *geolize_pb.BatchModifyIPRequest) (*geolize_pb.BatchModifyIPResponse, error) {
+	if len(request.Overrides) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "overrides are required")
+	}
+
+	reason := strings.TrimSpace(request.Reason)
+	if len(reason) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	batch := &model.IPBatchUpdateRequest{
+		Reason: reason,
+		Ticket: strings.TrimSpace(request.Ticket),
+		Author: requestCaller(ctx),
+	}
+	for _, override := range request.Overrides {
+		batch.Overrides = append(batch.Overrides, toUpdateRequest(override))
+	}
+
+	version, err := s.ipLocation.BatchUpdate(ctx, batch)
+	var invalid *validation.Error
+	switch {
+	case errors.As(err, &invalid):
+		return nil, invalidOverride(invalid)
+	case errors.Is(err, model.ErrInvalidIP):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		s.logger.Error(ctx, "ipLocation.BatchUpdate", logging.NewError(err)...)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &geolize_pb.BatchModifyIPResponse{Version: version}, nil
+}
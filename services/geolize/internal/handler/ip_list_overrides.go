@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/transform_response"
+	"geolize/utilities/logging"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s Service) ListIPOverrides(ctx context.Context, request *geolize_pb.ListIPOverridesRequest) (*geolize_pb.ListIPOverridesResponse, error) {
+	if request.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	list, err := s.ipLocation.ListOverrides(ctx, &model.IPOverrideListRequest{
+		IP:             request.Ip,
+		CountryISOCode: request.CountryIsoCode,
+		CreatedAfter:   toTime(request.CreatedAfter),
+		CreatedBefore:  toTime(request.CreatedBefore),
+		PageSize:       int(request.PageSize),
+		PageToken:      request.PageToken,
+	})
+	switch {
+	case errors.Is(err, model.ErrInvalidIP), errors.Is(err, model.ErrInvalidPageToken):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		s.logger.Error(ctx, "ipLocation.ListOverrides", logging.NewError(err)...)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return transform_response.ToListIPOverridesResponse(list), nil
+}
+
+// toTime returns the zero time for an unset timestamp.
+func toTime(timestamp *timestamppb.Timestamp) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+	return timestamp.AsTime()
+}
@@ -2,27 +2,49 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"geolize/service-protos/generated/geolize/geolize_pb"
 	"geolize/services/geolize/internal/pkg/ip_location/model"
 	"geolize/services/geolize/internal/pkg/transform_response"
 	"geolize/utilities/logging"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
+// LookupIP only fails as a whole for request-level problems. Per-IP failures
+// (invalid input, missing records) are reported in each IPInfo's status.
 func (s Service) LookupIP(ctx context.Context, request *geolize_pb.LookupIPRequest) (*geolize_pb.LookupIPResponse, error) {
-	if len(request.Ips) < 1 {
-		return nil, errors.New("IPs are required")
+	data, err := s.lookup(ctx, request.GetIps(), request.GetLanguages(), request.GetResolveNames(), request.GetFields())
+	if err != nil {
+		return nil, err
+	}
+
+	return &geolize_pb.LookupIPResponse{
+		Data: data,
+	}, nil
+}
+
+// lookup is shared by LookupIP and StreamLookupIP. Errors are gRPC statuses.
+func (s Service) lookup(ctx context.Context, ips []string, languages []string, resolveNames bool, fields *fieldmaskpb.FieldMask) ([]*geolize_pb.IPInfo, error) {
+	if len(ips) < 1 {
+		return nil, status.Error(codes.InvalidArgument, "IPs are required")
+	}
+
+	mask, err := transform_response.NewFieldMask(fields.GetPaths())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	resp, err := s.ipLocation.Lookup(ctx, &model.IPLookupRequest{
-		IPs: request.GetIps(),
+		IPs:    ips,
+		Fields: mask.Fields(),
 	})
 	if err != nil {
 		s.logger.Error(ctx, "ipLocation.Lookup", logging.NewError(err)...)
-		return nil, err
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return &geolize_pb.LookupIPResponse{
-		Data: transform_response.ToLookupIPsResponse(resp),
-	}, nil
+	locale := transform_response.NewLocale(requestLanguages(ctx, languages), resolveNames)
+	return transform_response.ToLookupIPsResponse(resp, locale, mask), nil
 }
@@ -5,22 +5,63 @@ import (
 	"errors"
 	"geolize/service-protos/generated/geolize/geolize_pb"
 	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/validation"
+	"geolize/utilities/logging"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPRequest) (*geolize_pb.ModifyIPResponse, error) {
-	if len(request.Ip) == 0 {
-		return nil, errors.New("invalid IP")
+	if len(request.Ip) == 0 && len(request.Network) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ip or network is required")
+	}
+
+	if len(request.Ip) > 0 && len(request.Network) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "only one of ip or network can be set")
 	}
 
-	err := s.ipLocation.Update(ctx, &model.IPUpdateRequest{
-		IP: request.Ip,
+	if len(strings.TrimSpace(request.Reason)) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	override := toUpdateRequest(request)
+	override.Reason = strings.TrimSpace(request.Reason)
+	override.Ticket = strings.TrimSpace(request.Ticket)
+	override.Author = requestCaller(ctx)
+
+	version, err := s.ipLocation.Update(ctx, override)
+	var invalid *validation.Error
+	switch {
+	case errors.As(err, &invalid):
+		return nil, invalidOverride(invalid)
+	case errors.Is(err, model.ErrInvalidIP):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		s.logger.Error(ctx, "ipLocation.Update", logging.NewError(err)...)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &geolize_pb.ModifyIPResponse{Version: version}, nil
+}
+
+// toUpdateRequest converts the target and the values of request. The reason,
+// ticket and author of the change are left to the caller.
+func toUpdateRequest(request *geolize_pb.ModifyIPRequest) *model.IPUpdateRequest {
+	return &model.IPUpdateRequest{
+		IP:           request.Ip,
+		Network:      request.Network,
+		Fields:       request.Fields,
+		RemoveFields: request.RemoveFields,
 		Continent: func() *model.Continent {
 			if request.Continent == nil {
 				return nil
 			}
 			return &model.Continent{
-				Code:  request.Continent.Code,
-				Names: request.Continent.Names,
+				Code:      request.Continent.Code,
+				Names:     request.Continent.Names,
+				GeoNameID: uint(request.Continent.GeonameId),
 			}
 		}(),
 		Country: func() *model.Country {
@@ -31,6 +72,8 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 				ISOCode:           request.Country.IsoCode,
 				Names:             request.Country.Names,
 				IsInEuropeanUnion: request.Country.IsInEuropeanUnion,
+				GeoNameID:         uint(request.Country.GeonameId),
+				Confidence:        uint8(request.Country.Confidence),
 			}
 		}(),
 		Subdivisions: func() []*model.Subdivision {
@@ -40,8 +83,10 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 			var subdivisions []*model.Subdivision
 			for _, subdivision := range request.Subdivisions {
 				subdivisions = append(subdivisions, &model.Subdivision{
-					ISOCode: subdivision.IsoCode,
-					Names:   subdivision.Names,
+					ISOCode:    subdivision.IsoCode,
+					Names:      subdivision.Names,
+					GeoNameID:  uint(subdivision.GeonameId),
+					Confidence: uint8(subdivision.Confidence),
 				})
 			}
 			return subdivisions
@@ -51,10 +96,13 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 				return nil
 			}
 			return &model.Location{
-				Latitude:       request.Location.Latitude,
-				Longitude:      request.Location.Longitude,
-				AccuracyRadius: uint16(request.Location.AccuracyRadius),
-				TimeZone:       request.Location.TimeZone,
+				Latitude:          request.Location.Latitude,
+				Longitude:         request.Location.Longitude,
+				AccuracyRadius:    uint16(request.Location.AccuracyRadius),
+				TimeZone:          request.Location.TimeZone,
+				MetroCode:         uint(request.Location.MetroCode),
+				PopulationDensity: uint(request.Location.PopulationDensity),
+				AverageIncome:     uint(request.Location.AverageIncome),
 			}
 		}(),
 		Postal: func() *model.Postal {
@@ -62,7 +110,8 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 				return nil
 			}
 			return &model.Postal{
-				Code: request.Postal.Code,
+				Code:       request.Postal.Code,
+				Confidence: uint8(request.Postal.Confidence),
 			}
 		}(),
 		City: func() *model.City {
@@ -70,7 +119,9 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 				return nil
 			}
 			return &model.City{
-				Names: request.City.Names,
+				Names:      request.City.Names,
+				GeoNameID:  uint(request.City.GeonameId),
+				Confidence: uint8(request.City.Confidence),
 			}
 		}(),
 		RepresentedCountry: func() *model.RepresentedCountry {
@@ -80,7 +131,9 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 			return &model.RepresentedCountry{
 				ISOCode:           request.RepresentedCountry.IsoCode,
 				Names:             request.RepresentedCountry.Names,
+				Type:              request.RepresentedCountry.Type,
 				IsInEuropeanUnion: request.RepresentedCountry.IsInEuropeanUnion,
+				GeoNameID:         uint(request.RepresentedCountry.GeonameId),
 			}
 		}(),
 		RegisteredCountry: func() *model.RegisteredCountry {
@@ -91,6 +144,8 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 				ISOCode:           request.RegisteredCountry.IsoCode,
 				Names:             request.RegisteredCountry.Names,
 				IsInEuropeanUnion: request.RegisteredCountry.IsInEuropeanUnion,
+				GeoNameID:         uint(request.RegisteredCountry.GeonameId),
+				Confidence:        uint8(request.RegisteredCountry.Confidence),
 			}
 		}(),
 		Traits: func() *model.Traits {
@@ -98,15 +153,27 @@ func (s Service) ModifyIP(ctx context.Context, request *geolize_pb.ModifyIPReque
 				return nil
 			}
 			return &model.Traits{
-				IsAnonymousProxy:    request.Traits.IsAnonymousProxy,
-				IsAnycast:           request.Traits.IsAnycast,
-				IsSatelliteProvider: request.Traits.IsSatelliteProvider,
+				IsAnonymousProxy:             request.Traits.IsAnonymousProxy,
+				IsAnycast:                    request.Traits.IsAnycast,
+				IsSatelliteProvider:          request.Traits.IsSatelliteProvider,
+				IsAnonymous:                  request.Traits.IsAnonymous,
+				IsAnonymousVPN:               request.Traits.IsAnonymousVpn,
+				IsHostingProvider:            request.Traits.IsHostingProvider,
+				IsLegitimateProxy:            request.Traits.IsLegitimateProxy,
+				IsPublicProxy:                request.Traits.IsPublicProxy,
+				IsResidentialProxy:           request.Traits.IsResidentialProxy,
+				IsTorExitNode:                request.Traits.IsTorExitNode,
+				AutonomousSystemNumber:       uint(request.Traits.AutonomousSystemNumber),
+				AutonomousSystemOrganization: request.Traits.AutonomousSystemOrganization,
+				ConnectionType:               request.Traits.ConnectionType,
+				Domain:                       request.Traits.Domain,
+				ISP:                          request.Traits.Isp,
+				MobileCountryCode:            request.Traits.MobileCountryCode,
+				MobileNetworkCode:            request.Traits.MobileNetworkCode,
+				Organization:                 request.Traits.Organization,
+				UserType:                     request.Traits.UserType,
+				StaticIPScore:                request.Traits.StaticIpScore,
 			}
 		}(),
-	})
-	if err != nil {
-		return nil, err
 	}
-
-	return &geolize_pb.ModifyIPResponse{}, nil
 }
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/transform_response"
+	"geolize/utilities/logging"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s Service) GetIPOverrideHistory(ctx context.Context, request *geolize_pb.GetIPOverrideHistoryRequest) (*geolize_pb.GetIPOverrideHistoryResponse, error) {
+	if len(request.Ip) == 0 && len(request.Network) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ip or network is required")
+	}
+
+	if len(request.Ip) > 0 && len(request.Network) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "only one of ip or network can be set")
+	}
+
+	changes, err := s.ipLocation.OverrideHistory(ctx, &model.IPOverrideHistoryRequest{
+		IP:      request.Ip,
+		Network: request.Network,
+	})
+	switch {
+	case errors.Is(err, model.ErrInvalidIP):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		s.logger.Error(ctx, "ipLocation.OverrideHistory", logging.NewError(err)...)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	response, err := transform_response.ToGetIPOverrideHistoryResponse(changes)
+	if err != nil {
+		s.logger.Error(ctx, "transform_response.ToGetIPOverrideHistoryResponse", logging.NewError(err)...)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/logging"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s Service) RemoveIPOverride(ctx context.Context, request *geolize_pb.RemoveIPOverrideRequest) (*geolize_pb.RemoveIPOverrideResponse, error) {
+	if len(request.Ip) == 0 && len(request.Network) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ip or network is required")
+	}
+
+	if len(request.Ip) > 0 && len(request.Network) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "only one of ip or network can be set")
+	}
+
+	err := s.ipLocation.Remove(ctx, &model.IPRemoveRequest{
+		IP:      request.Ip,
+		Network: request.Network,
+		Reason:  strings.TrimSpace(request.Reason),
+		Ticket:  strings.TrimSpace(request.Ticket),
+		Author:  requestCaller(ctx),
+	})
+	switch {
+	case errors.Is(err, model.ErrOverrideNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrInvalidIP):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, model.ErrBaseUnavailable):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		s.logger.Error(ctx, "ipLocation.Remove", logging.NewError(err)...)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &geolize_pb.RemoveIPOverrideResponse{}, nil
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/utilities/conf"
+	"geolize/utilities/logging"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// streamWindow bounds the messages a stream may have in flight. Once it
+	// is full the server stops reading, and HTTP/2 flow control pushes back
+	// on the client.
+	streamWindow, _ = conf.GetInt32("geolize", "stream_window", 64)
+	// streamWorkers is the number of messages of one stream looked up in parallel.
+	streamWorkers, _ = conf.GetInt32("geolize", "stream_workers", 4)
+)
+
+func (s Service) StreamLookupIP(stream grpc.BidiStreamingServer[geolize_pb.StreamLookupIPRequest, geolize_pb.StreamLookupIPResponse]) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	requests := make(chan *geolize_pb.StreamLookupIPRequest, streamWindow)
+	responses := make(chan *geolize_pb.StreamLookupIPResponse, streamWindow)
+	recvErr := make(chan error, 1)
+
+	go func() {
+		defer close(requests)
+		for {
+			request, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				recvErr <- nil
+				return
+			}
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			select {
+			case requests <- request:
+			case <-ctx.Done():
+				recvErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < int(max(streamWorkers, 1)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for request := range requests {
+				select {
+				case responses <- s.streamLookup(ctx, request):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(responses)
+	}()
+
+	// gRPC streams allow a single sender, so all responses go out from here.
+	for response := range responses {
+		if err := stream.Send(response); err != nil {
+			s.logger.Error(ctx, "stream.Send", logging.NewError(err)...)
+			return err
+		}
+	}
+
+	return <-recvErr
+}
+
+func (s Service) streamLookup(ctx context.Context, request *geolize_pb.StreamLookupIPRequest) *geolize_pb.StreamLookupIPResponse {
+	data, err := s.lookup(ctx, request.GetIps(), request.GetLanguages(), request.GetResolveNames(), request.GetFields())
+	if err != nil {
+		st := status.Convert(err)
+		return &geolize_pb.StreamLookupIPResponse{
+			RequestId: request.GetRequestId(),
+			Error:     st.Message(),
+			Code:      int32(st.Code()),
+		}
+	}
+
+	return &geolize_pb.StreamLookupIPResponse{
+		RequestId: request.GetRequestId(),
+		Data:      data,
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"geolize/utilities/contexts"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// acceptLanguageHeaders are the metadata keys the Accept-Language header
+// arrives under: grpc-gateway prefixes HTTP headers, plain gRPC does not.
+var acceptLanguageHeaders = []string{"grpcgateway-accept-language", "accept-language"}
+
+// requestLanguages returns the caller's language preferences: the explicit
+// list on the request first, then the Accept-Language header.
+func requestLanguages(ctx context.Context, languages []string) []string {
+	if len(languages) > 0 {
+		return languages
+	}
+
+	data := contexts.GetServerData(ctx)
+	if data == nil {
+		return nil
+	}
+
+	for _, header := range acceptLanguageHeaders {
+		if values := data.IncomingHeaders[header]; len(values) > 0 {
+			return parseAcceptLanguage(strings.Join(values, ","))
+		}
+	}
+
+	return nil
+}
+
+// parseAcceptLanguage orders the languages of an Accept-Language header by
+// quality, e.g. "vi-VN,vi;q=0.9,en;q=0.8" gives [vi-VN vi en].
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		language string
+		quality  float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		language := strings.TrimSpace(fields[0])
+		if len(language) == 0 || language == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				quality = q
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		entries = append(entries, weighted{language: language, quality: quality})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+
+	languages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		languages = append(languages, entry.language)
+	}
+
+	return languages
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/logging"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s Service) RollbackToVersion(ctx context.Context, request *geolize_pb.RollbackToVersionRequest) (*geolize_pb.RollbackToVersionResponse, error) {
+	version := strings.TrimSpace(request.Version)
+	if len(version) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+
+	reason := strings.TrimSpace(request.Reason)
+	if len(reason) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	version, err := s.ipLocation.Rollback(ctx, &model.RollbackRequest{
+		Version: version,
+		Reason:  reason,
+		Ticket:  strings.TrimSpace(request.Ticket),
+		Author:  requestCaller(ctx),
+	})
+	switch {
+	case errors.Is(err, model.ErrVersionNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrBaseUnavailable):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		s.logger.Error(ctx, "ipLocation.Rollback", logging.NewError(err)...)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &geolize_pb.RollbackToVersionResponse{Version: version}, nil
+}
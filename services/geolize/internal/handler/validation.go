@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"geolize/services/geolize/internal/pkg/ip_location/validation"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invalidOverride is the InvalidArgument status of an override that failed
+// validation, with a BadRequest detail listing the invalid fields.
+func invalidOverride(err *validation.Error) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range err.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	s, detailErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return s.Err()
+}
@@ -3,15 +3,44 @@ package iplocation
 import (
 	"context"
 	"geolize/services/geolize/internal/pkg/ip_location/model"
-	"geolize/services/geolize/internal/pkg/ip_location/providers/maxmind"
 	"geolize/utilities/logging"
 )
 
 type IPGeolocate interface {
 	Lookup(ctx context.Context, request *model.IPLookupRequest) ([]*model.IPResult, error)
-	Update(ctx context.Context, request *model.IPUpdateRequest) error
+	// Update applies the override of the request and returns the version
+	// that contains it.
+	Update(ctx context.Context, request *model.IPUpdateRequest) (string, error)
+	// BatchUpdate applies every override of the request as one change, or
+	// none of them, and returns the version it produces.
+	BatchUpdate(ctx context.Context, request *model.IPBatchUpdateRequest) (string, error)
+	// Remove drops the overrides made for the request's IP or network and
+	// restores the provider's own data for it.
+	Remove(ctx context.Context, request *model.IPRemoveRequest) error
+	// ListOverrides returns the effective override of every overridden
+	// network that matches the request, one page at a time.
+	ListOverrides(ctx context.Context, request *model.IPOverrideListRequest) (*model.IPOverrideList, error)
+	// OverrideHistory returns the change log of an IP or network, oldest
+	// first.
+	OverrideHistory(ctx context.Context, request *model.IPOverrideHistoryRequest) ([]*model.IPOverrideChange, error)
+	// Rollback undoes every change made after the request's version and
+	// returns the version the rollback produces.
+	Rollback(ctx context.Context, request *model.RollbackRequest) (string, error)
 }
 
+// NewIPGeolocate creates the provider selected by geolize.provider.
 func NewIPGeolocate(logger logging.Logger) IPGeolocate {
-	return maxmind.New(logger)
+	ipGeolocate, err := NewProvider(logger, provider)
+	if err != nil {
+		logger.Fatal(context.Background(), "Failed to create IP geolocation provider",
+			append(logging.NewError(err), logging.NewKeyVal("provider", provider))...)
+		panic(err)
+	}
+
+	logger.Info(context.Background(), "IP geolocation provider selected", logging.NewKeyVal("provider", provider))
+
+	if redisEnable {
+		ipGeolocate = newRedisCache(logger, provider, ipGeolocate)
+	}
+	return validated{ipGeolocate}
 }
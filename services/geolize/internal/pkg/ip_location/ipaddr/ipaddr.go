@@ -0,0 +1,58 @@
+// Package ipaddr parses the IPs and networks that lookups and overrides are
+// made for, so every provider resolves them to the same addresses.
+package ipaddr
+
+import (
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"net/netip"
+	"strings"
+)
+
+// Parse parses a single IPv4 or IPv6 address, optionally in brackets. The zone
+// is dropped and IPv4-mapped IPv6 addresses (::ffff:1.2.3.4) are unmapped so
+// they hit the same records as their IPv4 form.
+func Parse(ip string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.Trim(strings.TrimSpace(ip), "[]"))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("%w %q", model.ErrInvalidIP, ip)
+	}
+
+	return addr.WithZone("").Unmap(), nil
+}
+
+// ParseNetwork parses a CIDR and returns its canonical, masked form.
+// IPv4-mapped IPv6 prefixes are rewritten to their IPv4 equivalent.
+func ParseNetwork(network string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(network))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: network %q: %v", model.ErrInvalidIP, network, err)
+	}
+
+	if prefix.Addr().Is4In6() {
+		if prefix.Bits() < 96 {
+			return netip.Prefix{}, fmt.Errorf("%w: network %q: IPv4-mapped prefix must be at least /96", model.ErrInvalidIP, network)
+		}
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	return prefix.Masked(), nil
+}
+
+// OverrideNetwork resolves the network an override applies to. A CIDR in
+// Network takes precedence; otherwise the single host in IP is used, as a /32
+// for IPv4 or a /128 for IPv6.
+func OverrideNetwork(override *model.IPUpdateRequest) (netip.Prefix, error) {
+	if len(override.Network) > 0 {
+		return ParseNetwork(override.Network)
+	}
+	if len(override.IP) == 0 {
+		return netip.Prefix{}, fmt.Errorf("%w: ip or network is required", model.ErrInvalidIP)
+	}
+
+	addr, err := Parse(override.IP)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
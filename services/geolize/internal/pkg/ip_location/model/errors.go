@@ -0,0 +1,26 @@
+package model
+
+import "errors"
+
+var (
+	// ErrInvalidIP is returned when an IP or network cannot be parsed.
+	ErrInvalidIP = errors.New("invalid IP")
+	// ErrOverrideNotFound is returned when there is no override to remove for
+	// an IP or network.
+	ErrOverrideNotFound = errors.New("override not found")
+	// ErrInvalidPageToken is returned when a page token was not issued by a
+	// previous list call.
+	ErrInvalidPageToken = errors.New("invalid page token")
+	// ErrVersionNotFound is returned when a version to roll back to does not
+	// exist or was itself reverted.
+	ErrVersionNotFound = errors.New("version not found")
+	// ErrInvalidFieldPath is returned when a field path of a partial override
+	// does not name a field of the record.
+	ErrInvalidFieldPath = errors.New("invalid field path")
+	// ErrInvalidOverride is returned when an override sets values that do not
+	// describe a valid record.
+	ErrInvalidOverride = errors.New("invalid override")
+	// ErrBaseUnavailable is returned when the database has to be rebuilt from
+	// a base database that is not available.
+	ErrBaseUnavailable = errors.New("base database is not available")
+)
@@ -0,0 +1,12 @@
+package model
+
+// IPBatchUpdateRequest applies Overrides, in order, as a single change: they
+// are all applied or none is.
+type IPBatchUpdateRequest struct {
+	Overrides []*IPUpdateRequest `json:"overrides"`
+
+	// Reason, Ticket and Author are recorded for the whole batch.
+	Reason string `json:"reason,omitempty"`
+	Ticket string `json:"ticket,omitempty"`
+	Author string `json:"author,omitempty"`
+}
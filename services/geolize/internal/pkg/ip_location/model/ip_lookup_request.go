@@ -2,4 +2,7 @@ package model
 
 type IPLookupRequest struct {
 	IPs []string
+	// Fields limits the lookup to these top-level result fields, by their JSON
+	// name (e.g. "country", "location"). Empty means every field.
+	Fields []string
 }
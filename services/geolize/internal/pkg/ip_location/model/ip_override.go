@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// IPOverride is the effective override of a network: every override made for
+// it, applied in the order they were made.
+type IPOverride struct {
+	Network  string           `json:"network"`
+	Override *IPUpdateRequest `json:"override"`
+	// Version is the database version that introduced the latest override of
+	// the network, and CreatedAt is when it was made.
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type IPOverrideListRequest struct {
+	// IP matches the overrides whose network contains it. It may also be a
+	// CIDR, which matches the overrides whose network lies within it.
+	IP             string
+	CountryISOCode string
+	// CreatedAfter and CreatedBefore bound the time of the latest override of
+	// a network. Zero values are ignored.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	PageSize      int
+	PageToken     string
+}
+
+type IPOverrideList struct {
+	Overrides []*IPOverride
+	// NextPageToken is empty on the last page.
+	NextPageToken string
+}
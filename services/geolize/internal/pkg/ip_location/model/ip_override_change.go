@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	OverrideChangeKindOverride = "override"
+	OverrideChangeKindRemove   = "remove"
+	OverrideChangeKindRollback = "rollback"
+)
+
+// IPOverrideChange is an entry of the change log of overrides.
+type IPOverrideChange struct {
+	// Version is the database version the change produced.
+	Version   string    `json:"version"`
+	Kind      string    `json:"kind"`
+	Network   string    `json:"network"`
+	Author    string    `json:"author,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Ticket    string    `json:"ticket,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Reverted is set when a later rollback undid the change.
+	Reverted bool `json:"reverted,omitempty"`
+	// Before and After are the record of the network before and after the
+	// change, and Changes lists the fields that differ between them.
+	Before  map[string]any `json:"before"`
+	After   map[string]any `json:"after"`
+	Changes []*FieldChange `json:"changes"`
+}
+
+// FieldChange is a field whose value was changed. Path is dotted, e.g.
+// "country.iso_code"; a nil Before or After means the field was added or
+// removed.
+type FieldChange struct {
+	Path   string `json:"path"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+type IPOverrideHistoryRequest struct {
+	// IP returns the changes made to the networks that contain it, and
+	// Network the changes made to the networks that overlap it.
+	IP      string
+	Network string
+}
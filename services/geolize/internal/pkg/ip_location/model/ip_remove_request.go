@@ -0,0 +1,12 @@
+package model
+
+// IPRemoveRequest names the override to remove by the target it was made
+// for: a single IP or a network.
+type IPRemoveRequest struct {
+	IP      string `json:"ip"`
+	Network string `json:"network,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+	Ticket string `json:"ticket,omitempty"`
+	Author string `json:"author,omitempty"`
+}
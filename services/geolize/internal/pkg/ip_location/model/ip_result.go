@@ -1,8 +1,27 @@
 package model
 
+// LookupStatus is the outcome of looking up a single IP in a batch.
+type LookupStatus string
+
+const (
+	LookupStatusOK        LookupStatus = "ok"
+	LookupStatusInvalidIP LookupStatus = "invalid_ip"
+	LookupStatusNotFound  LookupStatus = "not_found"
+	LookupStatusInternal  LookupStatus = "internal"
+)
+
 type IPResult struct {
-	IP                 string              `json:"ip,omitempty"`
-	DBVersion          string              `json:"db_version,omitempty"`
+	IP        string       `json:"ip,omitempty"`
+	DBVersion string       `json:"db_version,omitempty"`
+	Found     bool         `json:"found"`
+	Network   string       `json:"network,omitempty"`
+	Status    LookupStatus `json:"status,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	// Provider is the name of the provider that answered the lookup.
+	Provider string `json:"provider,omitempty"`
+	// Sources maps each field to the provider that supplied it, when the
+	// result was merged from several providers.
+	Sources            map[string]string   `json:"sources,omitempty"`
 	Continent          *Continent          `json:"continent,omitempty"`
 	Country            *Country            `json:"country,omitempty"`
 	Location           *Location           `json:"location,omitempty"`
@@ -12,37 +31,59 @@ type IPResult struct {
 	RepresentedCountry *RepresentedCountry `json:"represented_country,omitempty"`
 	RegisteredCountry  *RegisteredCountry  `json:"registered_country,omitempty"`
 	Traits             *Traits             `json:"traits,omitempty"`
+
+	// Merged from the optional ASN, Anonymous IP and Connection Type databases.
+	ASN                uint   `json:"asn,omitempty"`
+	ASOrg              string `json:"as_org,omitempty"`
+	IsAnonymous        bool   `json:"is_anonymous,omitempty"`
+	IsVPN              bool   `json:"is_vpn,omitempty"`
+	IsTor              bool   `json:"is_tor,omitempty"`
+	IsHostingProvider  bool   `json:"is_hosting_provider,omitempty"`
+	IsPublicProxy      bool   `json:"is_public_proxy,omitempty"`
+	IsResidentialProxy bool   `json:"is_residential_proxy,omitempty"`
+	ConnectionType     string `json:"connection_type,omitempty"`
 }
 
 type Continent struct {
-	Code  string            `json:"code,omitempty"`
-	Names map[string]string `json:"names,omitempty"`
+	Code      string            `json:"code,omitempty"`
+	Names     map[string]string `json:"names,omitempty"`
+	GeoNameID uint              `json:"geoname_id,omitempty"`
 }
 
 type Country struct {
 	ISOCode           string            `json:"iso_code,omitempty"`
 	Names             map[string]string `json:"names,omitempty"`
 	IsInEuropeanUnion bool              `json:"is_in_european_union,omitempty"`
+	GeoNameID         uint              `json:"geoname_id,omitempty"`
+	Confidence        uint8             `json:"confidence,omitempty"`
 }
 
 type Location struct {
-	Latitude       float64 `json:"latitude,omitempty"`
-	Longitude      float64 `json:"longitude,omitempty"`
-	AccuracyRadius uint16  `json:"accuracy_radius,omitempty"`
-	TimeZone       string  `json:"time_zone,omitempty"`
+	Latitude          float64 `json:"latitude,omitempty"`
+	Longitude         float64 `json:"longitude,omitempty"`
+	AccuracyRadius    uint16  `json:"accuracy_radius,omitempty"`
+	TimeZone          string  `json:"time_zone,omitempty"`
+	MetroCode         uint    `json:"metro_code,omitempty"`
+	PopulationDensity uint    `json:"population_density,omitempty"`
+	AverageIncome     uint    `json:"average_income,omitempty"`
 }
 
 type Subdivision struct {
-	ISOCode string            `json:"iso_code,omitempty"`
-	Names   map[string]string `json:"names,omitempty"`
+	ISOCode    string            `json:"iso_code,omitempty"`
+	Names      map[string]string `json:"names,omitempty"`
+	GeoNameID  uint              `json:"geoname_id,omitempty"`
+	Confidence uint8             `json:"confidence,omitempty"`
 }
 
 type Postal struct {
-	Code string `json:"code,omitempty"`
+	Code       string `json:"code,omitempty"`
+	Confidence uint8  `json:"confidence,omitempty"`
 }
 
 type City struct {
-	Names map[string]string `json:"names,omitempty"`
+	Names      map[string]string `json:"names,omitempty"`
+	GeoNameID  uint              `json:"geoname_id,omitempty"`
+	Confidence uint8             `json:"confidence,omitempty"`
 }
 
 type RepresentedCountry struct {
@@ -50,16 +91,40 @@ type RepresentedCountry struct {
 	Names             map[string]string `json:"names,omitempty"`
 	Type              string            `json:"type,omitempty"`
 	IsInEuropeanUnion bool              `json:"is_in_european_union,omitempty"`
+	GeoNameID         uint              `json:"geoname_id,omitempty"`
 }
 
 type RegisteredCountry struct {
 	ISOCode           string            `json:"iso_code,omitempty"`
 	Names             map[string]string `json:"names,omitempty"`
 	IsInEuropeanUnion bool              `json:"is_in_european_union,omitempty"`
+	GeoNameID         uint              `json:"geoname_id,omitempty"`
+	Confidence        uint8             `json:"confidence,omitempty"`
 }
 
 type Traits struct {
 	IsAnonymousProxy    bool `json:"is_anonymous_proxy,omitempty"`
 	IsAnycast           bool `json:"is_anycast,omitempty"`
 	IsSatelliteProvider bool `json:"is_satellite_provider,omitempty"`
+
+	IsAnonymous                  bool    `json:"is_anonymous,omitempty"`
+	IsAnonymousVPN               bool    `json:"is_anonymous_vpn,omitempty"`
+	IsHostingProvider            bool    `json:"is_hosting_provider,omitempty"`
+	IsLegitimateProxy            bool    `json:"is_legitimate_proxy,omitempty"`
+	IsPublicProxy                bool    `json:"is_public_proxy,omitempty"`
+	IsResidentialProxy           bool    `json:"is_residential_proxy,omitempty"`
+	IsTorExitNode                bool    `json:"is_tor_exit_node,omitempty"`
+	AutonomousSystemNumber       uint    `json:"autonomous_system_number,omitempty"`
+	AutonomousSystemOrganization string  `json:"autonomous_system_organization,omitempty"`
+	ConnectionType               string  `json:"connection_type,omitempty"`
+	Domain                       string  `json:"domain,omitempty"`
+	ISP                          string  `json:"isp,omitempty"`
+	MobileCountryCode            string  `json:"mobile_country_code,omitempty"`
+	MobileNetworkCode            string  `json:"mobile_network_code,omitempty"`
+	Organization                 string  `json:"organization,omitempty"`
+	UserType                     string  `json:"user_type,omitempty"`
+	StaticIPScore                float64 `json:"static_ip_score,omitempty"`
+	// Network is the network the record was matched on. It is filled in at
+	// lookup time and never written into the database.
+	Network string `json:"network,omitempty"`
 }
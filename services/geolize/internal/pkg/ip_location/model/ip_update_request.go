@@ -2,6 +2,7 @@ package model
 
 type IPUpdateRequest struct {
 	IP                 string              `json:"ip"`
+	Network            string              `json:"network,omitempty"`
 	Continent          *Continent          `json:"continent"`
 	Country            *Country            `json:"country"`
 	Location           *Location           `json:"location"`
@@ -11,4 +12,20 @@ type IPUpdateRequest struct {
 	RepresentedCountry *RepresentedCountry `json:"represented_country"`
 	RegisteredCountry  *RegisteredCountry  `json:"registered_country"`
 	Traits             *Traits             `json:"traits"`
+
+	// Fields makes the override partial. Only the listed dotted field paths of
+	// the record, e.g. "country.names.vi", are written, each with the value
+	// the request gives it; a listed field the request leaves empty is
+	// deleted. The rest of the record is left untouched. Without Fields every
+	// sub-object the request sets replaces the record's one as a whole.
+	Fields []string `json:"fields,omitempty"`
+	// RemoveFields lists dotted field paths to delete from the record, after
+	// the rest of the override is applied.
+	RemoveFields []string `json:"remove_fields,omitempty"`
+
+	// Reason, Ticket and Author record why and by whom the override was made.
+	// They are kept in the change log and never written into the database.
+	Reason string `json:"reason,omitempty"`
+	Ticket string `json:"ticket,omitempty"`
+	Author string `json:"author,omitempty"`
 }
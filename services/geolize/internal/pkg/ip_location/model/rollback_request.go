@@ -0,0 +1,11 @@
+package model
+
+// RollbackRequest rolls the overrides back to Version, a database version
+// reported in IPResult.DBVersion.
+type RollbackRequest struct {
+	Version string `json:"version"`
+
+	Reason string `json:"reason,omitempty"`
+	Ticket string `json:"ticket,omitempty"`
+	Author string `json:"author,omitempty"`
+}
@@ -0,0 +1,261 @@
+package overrides
+
+import (
+	"encoding/json"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// recordFields maps each sub-object of a record, by its JSON name, to its type.
+var recordFields = func() map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	typ := reflect.TypeOf(model.IPUpdateRequest{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type.Kind() != reflect.Pointer && field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() == reflect.String {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		fields[name] = field.Type
+	}
+
+	return fields
+}()
+
+// CheckFieldPath returns model.ErrInvalidFieldPath unless path names a field
+// of the record: a sub-object, e.g. "country", one of its fields, e.g.
+// "country.iso_code", or a language of its names, e.g. "country.names.vi".
+// Subdivisions are a list and can only be written as a whole.
+func CheckFieldPath(path string) error {
+	keys := strings.Split(path, ".")
+
+	typ, ok := recordFields[keys[0]]
+	if !ok {
+		return fmt.Errorf("%w %q: unknown field %q", model.ErrInvalidFieldPath, path, keys[0])
+	}
+	if len(keys) == 1 {
+		return nil
+	}
+	if typ.Kind() == reflect.Slice {
+		return fmt.Errorf("%w %q: %s can only be written as a whole", model.ErrInvalidFieldPath, path, keys[0])
+	}
+
+	typ = typ.Elem()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != keys[1] || (keys[0] == "traits" && name == "network") {
+			continue
+		}
+		switch {
+		case len(keys) == 2:
+			return nil
+		case len(keys) == 3 && field.Type.Kind() == reflect.Map && len(keys[2]) > 0:
+			return nil
+		}
+		break
+	}
+
+	return fmt.Errorf("%w %q", model.ErrInvalidFieldPath, path)
+}
+
+// Patch writes fields, dotted field paths, from patch into record. A field
+// that patch does not have is deleted from record. Maps that end up empty are
+// deleted too.
+func Patch[M ~map[K]V, K ~string, V any](record, patch M, fields []string) {
+	for _, path := range fields {
+		keys := strings.Split(path, ".")
+		if value, ok := get(patch, keys); ok {
+			set(record, keys, value)
+		} else {
+			del(record, keys)
+		}
+	}
+}
+
+// Remove deletes fields, dotted field paths, from record, together with the
+// maps that end up empty.
+func Remove[M ~map[K]V, K ~string, V any](record M, fields []string) {
+	for _, path := range fields {
+		del(record, strings.Split(path, "."))
+	}
+}
+
+func get[M ~map[K]V, K ~string, V any](m M, keys []string) (V, bool) {
+	for {
+		value, ok := m[K(keys[0])]
+		if !ok || len(keys) == 1 {
+			return value, ok
+		}
+		child, ok := any(value).(M)
+		if !ok {
+			var zero V
+			return zero, false
+		}
+		m, keys = child, keys[1:]
+	}
+}
+
+func set[M ~map[K]V, K ~string, V any](m M, keys []string, value V) {
+	for ; len(keys) > 1; keys = keys[1:] {
+		child, ok := any(m[K(keys[0])]).(M)
+		if !ok {
+			child = M{}
+			m[K(keys[0])] = any(child).(V)
+		}
+		m = child
+	}
+	m[K(keys[0])] = value
+}
+
+func del[M ~map[K]V, K ~string, V any](m M, keys []string) {
+	key := K(keys[0])
+	if len(keys) > 1 {
+		child, ok := any(m[key]).(M)
+		if !ok {
+			return
+		}
+		del(child, keys[1:])
+		if len(child) > 0 {
+			return
+		}
+	}
+	delete(m, key)
+}
+
+// Apply applies a partial override to record, a record in its JSON form.
+func Apply(record map[string]any, override *model.IPUpdateRequest) error {
+	patch, err := recordOf(override)
+	if err != nil {
+		return err
+	}
+
+	Patch(record, patch, override.Fields)
+	Remove(record, override.RemoveFields)
+
+	return nil
+}
+
+// recordOf returns the sub-objects override sets, in their JSON form.
+func recordOf(override *model.IPUpdateRequest) (map[string]any, error) {
+	data, err := json.Marshal(&model.IPResult{
+		Continent:          override.Continent,
+		Country:            override.Country,
+		Location:           override.Location,
+		Subdivisions:       override.Subdivisions,
+		Postal:             override.Postal,
+		City:               override.City,
+		RepresentedCountry: override.RepresentedCountry,
+		RegisteredCountry:  override.RegisteredCountry,
+		Traits:             override.Traits,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record := map[string]any{}
+	if err = json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	delete(record, "found")
+
+	return record, nil
+}
+
+// effective is the combined override of every override made for a network,
+// as a record and the field paths it writes and removes.
+type effective struct {
+	record  map[string]any
+	fields  []string
+	removed []string
+}
+
+// apply layers override on top of e.
+func (e *effective) apply(override *model.IPUpdateRequest) error {
+	patch, err := recordOf(override)
+	if err != nil {
+		return err
+	}
+
+	// A whole-object override writes the sub-objects it sets.
+	fields := override.Fields
+	if len(fields) == 0 {
+		for key := range patch {
+			fields = append(fields, key)
+		}
+		sort.Strings(fields)
+	}
+
+	Patch(e.record, patch, fields)
+	for _, path := range fields {
+		e.write(path)
+	}
+
+	Remove(e.record, override.RemoveFields)
+	for _, path := range override.RemoveFields {
+		e.remove(path)
+	}
+
+	return nil
+}
+
+func (e *effective) write(path string) {
+	// A field written under a removed one makes the removed field hold only
+	// what is written under it from then on, which is what the record has.
+	if i := slices.IndexFunc(e.removed, func(removed string) bool { return Covers(removed, path) }); i >= 0 {
+		path = e.removed[i]
+	}
+	e.removed = slices.DeleteFunc(e.removed, func(removed string) bool { return Covers(path, removed) })
+
+	if slices.ContainsFunc(e.fields, func(field string) bool { return Covers(field, path) }) {
+		return
+	}
+	e.fields = append(slices.DeleteFunc(e.fields, func(field string) bool { return Covers(path, field) }), path)
+}
+
+func (e *effective) remove(path string) {
+	// A field removed under a written one is simply missing from the record.
+	if slices.ContainsFunc(e.fields, func(field string) bool { return Covers(field, path) }) {
+		return
+	}
+	e.fields = slices.DeleteFunc(e.fields, func(field string) bool { return Covers(path, field) })
+
+	if slices.ContainsFunc(e.removed, func(removed string) bool { return Covers(removed, path) }) {
+		return
+	}
+	e.removed = append(e.removed, path)
+}
+
+// override returns e as an override of network. It is a whole-object one
+// when every field it writes is a sub-object that the record has.
+func (e *effective) override(network string) (*model.IPUpdateRequest, error) {
+	data, err := json.Marshal(e.record)
+	if err != nil {
+		return nil, err
+	}
+
+	override := &model.IPUpdateRequest{}
+	if err = json.Unmarshal(data, override); err != nil {
+		return nil, err
+	}
+	override.Network = network
+
+	if slices.ContainsFunc(e.fields, func(field string) bool { _, ok := e.record[field]; return !ok }) {
+		override.Fields = slices.Sorted(slices.Values(e.fields))
+	}
+	if len(e.removed) > 0 {
+		override.RemoveFields = slices.Sorted(slices.Values(e.removed))
+	}
+
+	return override, nil
+}
+
+// Covers reports whether the field path parent is path or one of its parents.
+func Covers(parent, path string) bool {
+	return parent == path || strings.HasPrefix(path, parent+".")
+}
@@ -0,0 +1,330 @@
+package overrides
+
+import (
+	"errors"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"reflect"
+	"testing"
+)
+
+// testRecord returns a record in its JSON form with nested maps and a slice.
+func testRecord() map[string]any {
+	return map[string]any{
+		"country": map[string]any{
+			"iso_code": "VN",
+			"names":    map[string]any{"en": "Vietnam", "vi": "Việt Nam"},
+		},
+		"location": map[string]any{"latitude": 21.0, "longitude": 105.8},
+		"subdivisions": []any{
+			map[string]any{"iso_code": "HN"},
+		},
+	}
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		patch  map[string]any
+		fields []string
+		want   map[string]any
+	}{
+		{
+			name:   "nested field",
+			patch:  map[string]any{"country": map[string]any{"names": map[string]any{"vi": "Viet Nam"}}},
+			fields: []string{"country.names.vi"},
+			want: map[string]any{
+				"country": map[string]any{
+					"iso_code": "VN",
+					"names":    map[string]any{"en": "Vietnam", "vi": "Viet Nam"},
+				},
+				"location": map[string]any{"latitude": 21.0, "longitude": 105.8},
+				"subdivisions": []any{
+					map[string]any{"iso_code": "HN"},
+				},
+			},
+		},
+		{
+			name:   "new nested field",
+			patch:  map[string]any{"city": map[string]any{"names": map[string]any{"en": "Hanoi"}}},
+			fields: []string{"city.names.en"},
+			want: map[string]any{
+				"city": map[string]any{"names": map[string]any{"en": "Hanoi"}},
+				"country": map[string]any{
+					"iso_code": "VN",
+					"names":    map[string]any{"en": "Vietnam", "vi": "Việt Nam"},
+				},
+				"location": map[string]any{"latitude": 21.0, "longitude": 105.8},
+				"subdivisions": []any{
+					map[string]any{"iso_code": "HN"},
+				},
+			},
+		},
+		{
+			name:   "field the patch leaves empty",
+			patch:  map[string]any{},
+			fields: []string{"country.iso_code", "country.names.en", "country.names.vi"},
+			want: map[string]any{
+				"location": map[string]any{"latitude": 21.0, "longitude": 105.8},
+				"subdivisions": []any{
+					map[string]any{"iso_code": "HN"},
+				},
+			},
+		},
+		{
+			name:   "whole object",
+			patch:  map[string]any{"location": map[string]any{"latitude": 0.0, "longitude": 0.0}},
+			fields: []string{"location"},
+			want: map[string]any{
+				"country": map[string]any{
+					"iso_code": "VN",
+					"names":    map[string]any{"en": "Vietnam", "vi": "Việt Nam"},
+				},
+				"location": map[string]any{"latitude": 0.0, "longitude": 0.0},
+				"subdivisions": []any{
+					map[string]any{"iso_code": "HN"},
+				},
+			},
+		},
+		{
+			name:   "slice field",
+			patch:  map[string]any{"subdivisions": []any{map[string]any{"iso_code": "SG"}, map[string]any{"iso_code": "HP"}}},
+			fields: []string{"subdivisions"},
+			want: map[string]any{
+				"country": map[string]any{
+					"iso_code": "VN",
+					"names":    map[string]any{"en": "Vietnam", "vi": "Việt Nam"},
+				},
+				"location": map[string]any{"latitude": 21.0, "longitude": 105.8},
+				"subdivisions": []any{
+					map[string]any{"iso_code": "SG"},
+					map[string]any{"iso_code": "HP"},
+				},
+			},
+		},
+		{
+			name:   "path into a slice",
+			patch:  map[string]any{"subdivisions": []any{map[string]any{"iso_code": "SG"}}},
+			fields: []string{"subdivisions.0.iso_code"},
+			want:   testRecord(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := testRecord()
+			Patch(record, tt.patch, tt.fields)
+			if !reflect.DeepEqual(record, tt.want) {
+				t.Fatalf("record = %v, want %v", record, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   map[string]any
+	}{
+		{
+			name:   "nested field",
+			fields: []string{"country.names.vi"},
+			want: map[string]any{
+				"country": map[string]any{
+					"iso_code": "VN",
+					"names":    map[string]any{"en": "Vietnam"},
+				},
+				"location": map[string]any{"latitude": 21.0, "longitude": 105.8},
+				"subdivisions": []any{
+					map[string]any{"iso_code": "HN"},
+				},
+			},
+		},
+		{
+			name:   "last fields of a map",
+			fields: []string{"location.latitude", "location.longitude"},
+			want: map[string]any{
+				"country": map[string]any{
+					"iso_code": "VN",
+					"names":    map[string]any{"en": "Vietnam", "vi": "Việt Nam"},
+				},
+				"subdivisions": []any{
+					map[string]any{"iso_code": "HN"},
+				},
+			},
+		},
+		{
+			name:   "missing path",
+			fields: []string{"city.names.en", "country.names.fr", "postal"},
+			want:   testRecord(),
+		},
+		{
+			name:   "path through a value",
+			fields: []string{"country.iso_code.x"},
+			want:   testRecord(),
+		},
+		{
+			name:   "slice field",
+			fields: []string{"subdivisions"},
+			want: map[string]any{
+				"country": map[string]any{
+					"iso_code": "VN",
+					"names":    map[string]any{"en": "Vietnam", "vi": "Việt Nam"},
+				},
+				"location": map[string]any{"latitude": 21.0, "longitude": 105.8},
+			},
+		},
+		{
+			name:   "path into a slice",
+			fields: []string{"subdivisions.0"},
+			want:   testRecord(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := testRecord()
+			Remove(record, tt.fields)
+			if !reflect.DeepEqual(record, tt.want) {
+				t.Fatalf("record = %v, want %v", record, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFieldPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"country", true},
+		{"country.iso_code", true},
+		{"country.names.vi", true},
+		{"location.latitude", true},
+		{"subdivisions", true},
+		{"subdivisions.iso_code", false},
+		{"subdivisions.0", false},
+		{"traits.network", false},
+		{"country.names.vi.x", false},
+		{"country.iso_code.x", false},
+		{"country.unknown", false},
+		{"unknown", false},
+		{"ip", false},
+	}
+
+	for _, tt := range tests {
+		err := CheckFieldPath(tt.path)
+		if tt.valid && err != nil {
+			t.Errorf("CheckFieldPath(%q) = %v, want nil", tt.path, err)
+		}
+		if !tt.valid && !errors.Is(err, model.ErrInvalidFieldPath) {
+			t.Errorf("CheckFieldPath(%q) = %v, want %v", tt.path, err, model.ErrInvalidFieldPath)
+		}
+	}
+}
+
+func TestEffective(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []*model.IPUpdateRequest
+		want      *model.IPUpdateRequest
+	}{
+		{
+			name: "whole objects",
+			overrides: []*model.IPUpdateRequest{
+				{Country: &model.Country{ISOCode: "VN"}},
+				{Location: &model.Location{Latitude: 21, Longitude: 105.8}},
+			},
+			want: &model.IPUpdateRequest{
+				Country:  &model.Country{ISOCode: "VN"},
+				Location: &model.Location{Latitude: 21, Longitude: 105.8},
+			},
+		},
+		{
+			name: "nested field on a whole object",
+			overrides: []*model.IPUpdateRequest{
+				{Country: &model.Country{ISOCode: "VN"}},
+				{Country: &model.Country{Names: map[string]string{"vi": "Việt Nam"}}, Fields: []string{"country.names.vi"}},
+			},
+			want: &model.IPUpdateRequest{
+				Country: &model.Country{ISOCode: "VN", Names: map[string]string{"vi": "Việt Nam"}},
+			},
+		},
+		{
+			name: "nested fields only",
+			overrides: []*model.IPUpdateRequest{
+				{Country: &model.Country{Names: map[string]string{"vi": "Việt Nam"}}, Fields: []string{"country.names.vi"}},
+				{City: &model.City{Names: map[string]string{"en": "Hanoi"}}, Fields: []string{"city.names.en"}},
+			},
+			want: &model.IPUpdateRequest{
+				Country: &model.Country{Names: map[string]string{"vi": "Việt Nam"}},
+				City:    &model.City{Names: map[string]string{"en": "Hanoi"}},
+				Fields:  []string{"city.names.en", "country.names.vi"},
+			},
+		},
+		{
+			name: "removed field",
+			overrides: []*model.IPUpdateRequest{
+				{Country: &model.Country{ISOCode: "VN", Names: map[string]string{"en": "Vietnam"}}},
+				{RemoveFields: []string{"country.names.en", "postal"}},
+			},
+			want: &model.IPUpdateRequest{
+				Country:      &model.Country{ISOCode: "VN"},
+				RemoveFields: []string{"postal"},
+			},
+		},
+		{
+			name: "field written after its removal",
+			overrides: []*model.IPUpdateRequest{
+				{RemoveFields: []string{"city"}},
+				{City: &model.City{Names: map[string]string{"en": "Hanoi"}}, Fields: []string{"city.names.en"}},
+			},
+			want: &model.IPUpdateRequest{
+				City: &model.City{Names: map[string]string{"en": "Hanoi"}},
+			},
+		},
+		{
+			name: "slice field",
+			overrides: []*model.IPUpdateRequest{
+				{Subdivisions: []*model.Subdivision{{ISOCode: "HN"}}},
+				{Subdivisions: []*model.Subdivision{{ISOCode: "SG"}, {ISOCode: "HP"}}, Fields: []string{"subdivisions"}},
+			},
+			want: &model.IPUpdateRequest{
+				Subdivisions: []*model.Subdivision{{ISOCode: "SG"}, {ISOCode: "HP"}},
+			},
+		},
+		{
+			// A field removed after it was written is left out of a partial
+			// override, which deletes it too.
+			name: "removed slice field",
+			overrides: []*model.IPUpdateRequest{
+				{Subdivisions: []*model.Subdivision{{ISOCode: "HN"}}, Country: &model.Country{ISOCode: "VN"}},
+				{RemoveFields: []string{"subdivisions"}},
+			},
+			want: &model.IPUpdateRequest{
+				Country: &model.Country{ISOCode: "VN"},
+				Fields:  []string{"country", "subdivisions"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &effective{record: map[string]any{}}
+			for _, override := range tt.overrides {
+				if err := e.apply(override); err != nil {
+					t.Fatalf("apply() error = %v", err)
+				}
+			}
+
+			got, err := e.override("81.2.69.0/24")
+			if err != nil {
+				t.Fatalf("override() error = %v", err)
+			}
+			tt.want.Network = "81.2.69.0/24"
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("override() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
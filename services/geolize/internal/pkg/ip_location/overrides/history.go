@@ -0,0 +1,83 @@
+package overrides
+
+import (
+	"errors"
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"reflect"
+	"sort"
+)
+
+// History returns the changes, given in the order they were made, that
+// concern the IP or network of request, each with the fields it changed.
+func History(changes []*model.IPOverrideChange, request *model.IPOverrideHistoryRequest) ([]*model.IPOverrideChange, error) {
+	if len(request.IP) == 0 && len(request.Network) == 0 {
+		return nil, errors.New("ip or network is required")
+	}
+
+	target, err := ipaddr.OverrideNetwork(&model.IPUpdateRequest{IP: request.IP, Network: request.Network})
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*model.IPOverrideChange
+	for _, change := range changes {
+		network, err := ipaddr.OverrideNetwork(&model.IPUpdateRequest{Network: change.Network})
+		if err != nil {
+			continue
+		}
+		if len(request.Network) > 0 && !network.Overlaps(target) {
+			continue
+		}
+		if len(request.Network) == 0 && !network.Contains(target.Addr()) {
+			continue
+		}
+
+		change.Changes = Diff(change.Before, change.After)
+		history = append(history, change)
+	}
+
+	return history, nil
+}
+
+// Diff lists the leaves of after that differ from before, and the leaves of
+// before that after no longer has, ordered by path. Lists are compared as a
+// whole.
+func Diff(before, after map[string]any) []*model.FieldChange {
+	var changes []*model.FieldChange
+	diff("", before, after, &changes)
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+func diff(prefix string, before, after map[string]any, changes *[]*model.FieldChange) {
+	for key, b := range before {
+		compare(prefix+key, b, after[key], changes)
+	}
+
+	for key, a := range after {
+		if _, ok := before[key]; !ok {
+			compare(prefix+key, nil, a, changes)
+		}
+	}
+}
+
+func compare(path string, before, after any, changes *[]*model.FieldChange) {
+	b, bIsMap := before.(map[string]any)
+	a, aIsMap := after.(map[string]any)
+
+	switch {
+	case bIsMap && aIsMap:
+		diff(path+".", b, a, changes)
+	case bIsMap && after == nil:
+		diff(path+".", b, nil, changes)
+	case aIsMap && before == nil:
+		diff(path+".", nil, a, changes)
+	case !reflect.DeepEqual(before, after):
+		*changes = append(*changes, &model.FieldChange{Path: path, Before: before, After: after})
+	}
+}
@@ -0,0 +1,188 @@
+// Package overrides lists the overrides of a provider and their change log.
+// Providers record every override they are given; this package layers them
+// into the effective override of each network and filters and pages the
+// result.
+package overrides
+
+import (
+	"encoding/base64"
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// Change is an override as it was recorded by a provider.
+type Change struct {
+	Override *model.IPUpdateRequest
+	Version  string
+	// CreatedAt is when the override was made.
+	CreatedAt time.Time
+}
+
+type entry struct {
+	network   netip.Prefix
+	effective *effective
+	override  *model.IPOverride
+}
+
+// List layers changes, given in the order they were made, into the effective
+// override of each network, and returns the page of them that matches
+// request, ordered by network.
+func List(changes []Change, request *model.IPOverrideListRequest) (*model.IPOverrideList, error) {
+	filter, err := newFilter(request)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodePageToken(request.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	byNetwork := make(map[netip.Prefix]*entry)
+	for _, change := range changes {
+		network, err := ipaddr.OverrideNetwork(change.Override)
+		if err != nil {
+			continue
+		}
+
+		e, ok := byNetwork[network]
+		if !ok {
+			e = &entry{
+				network:   network,
+				effective: &effective{record: map[string]any{}},
+				override:  &model.IPOverride{Network: network.String()},
+			}
+			byNetwork[network] = e
+		}
+		if err = e.effective.apply(change.Override); err != nil {
+			return nil, err
+		}
+		e.override.Version = change.Version
+		e.override.CreatedAt = change.CreatedAt
+	}
+
+	entries := make([]*entry, 0, len(byNetwork))
+	for _, e := range byNetwork {
+		if e.override.Override, err = e.effective.override(e.override.Network); err != nil {
+			return nil, err
+		}
+		if filter.match(e) && (!after.IsValid() || comparePrefix(e.network, after) > 0) {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return comparePrefix(entries[i].network, entries[j].network) < 0
+	})
+
+	size := request.PageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	size = min(size, maxPageSize)
+
+	list := &model.IPOverrideList{}
+	for i, e := range entries {
+		if i == size {
+			list.NextPageToken = encodePageToken(entries[i-1].network)
+			break
+		}
+		list.Overrides = append(list.Overrides, e.override)
+	}
+
+	return list, nil
+}
+
+type filter struct {
+	addr    netip.Addr
+	network netip.Prefix
+	request *model.IPOverrideListRequest
+}
+
+func newFilter(request *model.IPOverrideListRequest) (*filter, error) {
+	f := &filter{request: request}
+
+	ip := strings.TrimSpace(request.IP)
+	switch {
+	case len(ip) == 0:
+	case strings.Contains(ip, "/"):
+		network, err := ipaddr.ParseNetwork(ip)
+		if err != nil {
+			return nil, err
+		}
+		f.network = network
+	default:
+		addr, err := ipaddr.Parse(ip)
+		if err != nil {
+			return nil, err
+		}
+		f.addr = addr
+	}
+
+	return f, nil
+}
+
+func (f *filter) match(e *entry) bool {
+	if f.addr.IsValid() && !e.network.Contains(f.addr) {
+		return false
+	}
+	if f.network.IsValid() && (e.network.Bits() < f.network.Bits() || !f.network.Contains(e.network.Addr())) {
+		return false
+	}
+
+	if len(f.request.CountryISOCode) > 0 {
+		country := e.override.Override.Country
+		if country == nil || !strings.EqualFold(country.ISOCode, f.request.CountryISOCode) {
+			return false
+		}
+	}
+
+	if !f.request.CreatedAfter.IsZero() && !e.override.CreatedAt.After(f.request.CreatedAfter) {
+		return false
+	}
+	if !f.request.CreatedBefore.IsZero() && !e.override.CreatedAt.Before(f.request.CreatedBefore) {
+		return false
+	}
+
+	return true
+}
+
+// comparePrefix orders networks by address, IPv4 first, then by length.
+func comparePrefix(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+	return a.Bits() - b.Bits()
+}
+
+// The page token is the last network of the previous page, so pages stay
+// consistent when overrides are added or removed in between.
+func encodePageToken(network netip.Prefix) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(network.String()))
+}
+
+func decodePageToken(token string) (netip.Prefix, error) {
+	if len(token) == 0 {
+		return netip.Prefix{}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return netip.Prefix{}, model.ErrInvalidPageToken
+	}
+
+	network, err := netip.ParsePrefix(string(data))
+	if err != nil {
+		return netip.Prefix{}, model.ErrInvalidPageToken
+	}
+
+	return network, nil
+}
@@ -0,0 +1,149 @@
+package overrides
+
+import (
+	"errors"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+func change(network, country, version string, createdAt time.Time) Change {
+	return Change{
+		Override:  &model.IPUpdateRequest{Network: network, Country: &model.Country{ISOCode: country}},
+		Version:   version,
+		CreatedAt: createdAt,
+	}
+}
+
+// testChanges overrides five networks, one of them twice.
+func testChanges() []Change {
+	at := time.Unix(1744099200, 0)
+	return []Change{
+		change("2a02:ff0::/32", "DE", "v1", at),
+		change("89.160.20.0/24", "SE", "v2", at.Add(time.Hour)),
+		change("81.2.69.0/24", "GB", "v3", at.Add(2*time.Hour)),
+		change("81.2.69.142/32", "VN", "v4", at.Add(3*time.Hour)),
+		change("10.0.0.0/8", "VN", "v5", at.Add(4*time.Hour)),
+		change("89.160.20.0/24", "FR", "v6", at.Add(5*time.Hour)),
+	}
+}
+
+func networks(list *model.IPOverrideList) []string {
+	var networks []string
+	for _, o := range list.Overrides {
+		networks = append(networks, o.Network)
+	}
+	return networks
+}
+
+func TestListPages(t *testing.T) {
+	changes := testChanges()
+
+	var pages [][]string
+	request := &model.IPOverrideListRequest{PageSize: 2}
+	for {
+		list, err := List(changes, request)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		pages = append(pages, networks(list))
+		if len(list.NextPageToken) == 0 {
+			break
+		}
+		request.PageToken = list.NextPageToken
+	}
+
+	want := [][]string{
+		{"10.0.0.0/8", "81.2.69.0/24"},
+		{"81.2.69.142/32", "89.160.20.0/24"},
+		{"2a02:ff0::/32"},
+	}
+	if !slices.EqualFunc(pages, want, slices.Equal) {
+		t.Fatalf("pages = %q, want %q", pages, want)
+	}
+}
+
+func TestListPagesStayConsistent(t *testing.T) {
+	changes := testChanges()
+
+	first, err := List(changes, &model.IPOverrideListRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	// A network added before the page token does not shift the next page.
+	changes = append(changes, change("1.1.1.0/24", "AU", "v7", time.Unix(1744120800, 0)))
+	next, err := List(changes, &model.IPOverrideListRequest{PageSize: 2, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got, want := networks(next), []string{"81.2.69.142/32", "89.160.20.0/24"}; !slices.Equal(got, want) {
+		t.Fatalf("next page = %q, want %q", got, want)
+	}
+}
+
+func TestListLayersOverridesOfANetwork(t *testing.T) {
+	list, err := List(testChanges(), &model.IPOverrideListRequest{IP: "89.160.20.0/24"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list.Overrides) != 1 {
+		t.Fatalf("List() = %q, want the one network", networks(list))
+	}
+	o := list.Overrides[0]
+	if o.Override.Country.ISOCode != "FR" || o.Version != "v6" {
+		t.Fatalf("override = %+v at %s, want the latest FR at v6", o.Override.Country, o.Version)
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	at := time.Unix(1744099200, 0)
+	tests := []struct {
+		name    string
+		request *model.IPOverrideListRequest
+		want    []string
+	}{
+		{
+			name:    "networks containing an IP",
+			request: &model.IPOverrideListRequest{IP: "81.2.69.142"},
+			want:    []string{"81.2.69.0/24", "81.2.69.142/32"},
+		},
+		{
+			name:    "networks within a network",
+			request: &model.IPOverrideListRequest{IP: "81.0.0.0/8"},
+			want:    []string{"81.2.69.0/24", "81.2.69.142/32"},
+		},
+		{
+			name:    "country",
+			request: &model.IPOverrideListRequest{CountryISOCode: "vn"},
+			want:    []string{"10.0.0.0/8", "81.2.69.142/32"},
+		},
+		{
+			name:    "creation time",
+			request: &model.IPOverrideListRequest{CreatedAfter: at.Add(time.Hour), CreatedBefore: at.Add(4 * time.Hour)},
+			want:    []string{"81.2.69.0/24", "81.2.69.142/32"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := List(testChanges(), tt.request)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if got := networks(list); !slices.Equal(got, tt.want) {
+				t.Fatalf("List() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListRejectsInvalidRequests(t *testing.T) {
+	if _, err := List(testChanges(), &model.IPOverrideListRequest{PageToken: "not a token"}); !errors.Is(err, model.ErrInvalidPageToken) {
+		t.Fatalf("List() error = %v, want %v", err, model.ErrInvalidPageToken)
+	}
+	if _, err := List(testChanges(), &model.IPOverrideListRequest{IP: "not an ip"}); !errors.Is(err, model.ErrInvalidIP) {
+		t.Fatalf("List() error = %v, want %v", err, model.ErrInvalidIP)
+	}
+}
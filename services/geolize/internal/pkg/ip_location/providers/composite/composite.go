@@ -0,0 +1,481 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Name identifies the composite provider in the registry.
+const Name = "composite"
+
+// Provider is one dataset of the chain.
+type Provider interface {
+	Lookup(ctx context.Context, request *model.IPLookupRequest) ([]*model.IPResult, error)
+	Update(ctx context.Context, request *model.IPUpdateRequest) (string, error)
+	BatchUpdate(ctx context.Context, request *model.IPBatchUpdateRequest) (string, error)
+	Remove(ctx context.Context, request *model.IPRemoveRequest) error
+	ListOverrides(ctx context.Context, request *model.IPOverrideListRequest) (*model.IPOverrideList, error)
+	OverrideHistory(ctx context.Context, request *model.IPOverrideHistoryRequest) ([]*model.IPOverrideChange, error)
+	Rollback(ctx context.Context, request *model.RollbackRequest) (string, error)
+}
+
+// checker is implemented by members that can tell whether they accept an
+// override without applying it.
+type checker interface {
+	Check(request *model.IPUpdateRequest) error
+}
+
+// checkpointer is implemented by members that can report the version their
+// overrides are at, which their Rollback takes them back to.
+type checkpointer interface {
+	Checkpoint() (string, error)
+}
+
+// PartialError is returned when an update failed on a member after it was
+// applied to others that could not be rolled back.
+type PartialError struct {
+	// Applied names the members that kept the update.
+	Applied []string
+	Err     error
+	// RollbackErr holds why the members failed to roll back, if they tried.
+	RollbackErr error
+}
+
+func (e *PartialError) Error() string {
+	msg := fmt.Sprintf("%v; the update was kept by %s", e.Err, strings.Join(e.Applied, ", "))
+	if e.RollbackErr != nil {
+		msg += fmt.Sprintf(", which failed to roll back: %v", e.RollbackErr)
+	}
+	return msg
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Member is a named provider of the chain.
+type Member struct {
+	Name     string
+	Provider Provider
+}
+
+// metadataFields describe a lookup rather than the IP, so they are never
+// merged from the members.
+var metadataFields = []string{"ip", "db_version", "found", "network", "status", "message", "provider", "sources"}
+
+// mergeField is a top-level IPResult field, e.g. "city", that is taken as a
+// whole from the first member that has it.
+type mergeField struct {
+	name  string
+	index int
+}
+
+// Fields lists the names of the IPResult fields that are merged, which are
+// the keys accepted by the precedence configuration.
+var Fields, mergeFields = func() ([]string, []mergeField) {
+	var names []string
+	var fields []mergeField
+
+	typ := reflect.TypeOf(model.IPResult{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if len(name) == 0 || slices.Contains(metadataFields, name) {
+			continue
+		}
+		names = append(names, name)
+		fields = append(fields, mergeField{name: name, index: i})
+	}
+
+	return names, fields
+}()
+
+// Composite queries an ordered chain of providers and merges their results
+// field by field. Each field comes from the first member in its precedence
+// order that has a value for it; members not listed for a field follow in
+// chain order. IPResult.Sources records which member supplied each field.
+type Composite struct {
+	members    []Member
+	precedence map[string][]int
+
+	// writeMu serializes the changes made through the composite, so the
+	// members only ever hold the change being made since their checkpoints
+	// and rolling one back reverts nothing else.
+	writeMu sync.Mutex
+}
+
+// New chains members in order. precedence maps a field to the names of the
+// members that should supply it first.
+func New(members []Member, precedence map[string][]string) (*Composite, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("composite provider needs at least one provider")
+	}
+
+	c := &Composite{
+		members:    members,
+		precedence: make(map[string][]int, len(precedence)),
+	}
+
+	for field, names := range precedence {
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("unknown field %q in precedence, expected one of %v", field, Fields)
+		}
+
+		order := make([]int, 0, len(members))
+		for _, name := range names {
+			i := slices.IndexFunc(members, func(m Member) bool { return m.Name == name })
+			if i < 0 {
+				return nil, fmt.Errorf("provider %q in precedence of %q is not in the chain", name, field)
+			}
+			order = append(order, i)
+		}
+		for i := range members {
+			if !slices.Contains(order, i) {
+				order = append(order, i)
+			}
+		}
+		c.precedence[field] = order
+	}
+
+	return c, nil
+}
+
+func (c *Composite) order(field string) []int {
+	if order, ok := c.precedence[field]; ok {
+		return order
+	}
+
+	order := make([]int, len(c.members))
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// Version combines the versions of the members that report one.
+func (c *Composite) Version() string {
+	versions := make([]string, 0, len(c.members))
+	for _, m := range c.members {
+		if v, ok := m.Provider.(interface{ Version() string }); ok {
+			versions = append(versions, m.Name+"="+v.Version())
+		}
+	}
+	return strings.Join(versions, ",")
+}
+
+func (c *Composite) Lookup(ctx context.Context, request *model.IPLookupRequest) ([]*model.IPResult, error) {
+	results := make([][]*model.IPResult, len(c.members))
+	errs := make([]error, len(c.members))
+
+	var wg sync.WaitGroup
+	for i, m := range c.members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = m.Provider.Lookup(ctx, request)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.members[i].Name, err)
+		}
+		if len(results[i]) != len(request.IPs) {
+			return nil, fmt.Errorf("%s: expected %d results, got %d", c.members[i].Name, len(request.IPs), len(results[i]))
+		}
+	}
+
+	merged := make([]*model.IPResult, len(request.IPs))
+	for i := range request.IPs {
+		perMember := make([]*model.IPResult, len(c.members))
+		for j := range c.members {
+			perMember[j] = results[j][i]
+		}
+		merged[i] = c.merge(request.IPs[i], perMember)
+	}
+
+	return merged, nil
+}
+
+// merge combines the results of every member for one IP.
+func (c *Composite) merge(ip string, results []*model.IPResult) *model.IPResult {
+	merged := &model.IPResult{IP: ip, DBVersion: c.dbVersion(results)}
+	target := reflect.ValueOf(merged).Elem()
+
+	for _, field := range mergeFields {
+		for _, i := range c.order(field.name) {
+			result := results[i]
+			if !result.Found {
+				continue
+			}
+			value := reflect.ValueOf(result).Elem().Field(field.index)
+			if isEmpty(field.name, value) {
+				continue
+			}
+			target.Field(field.index).Set(value)
+			if merged.Sources == nil {
+				merged.Sources = make(map[string]string)
+			}
+			merged.Sources[field.name] = c.members[i].Name
+			break
+		}
+	}
+
+	// The lookup metadata follows the first member in chain order that found
+	// the IP, or the first member's outcome when none did.
+	primary := results[0]
+	for _, result := range results {
+		if result.Found {
+			primary = result
+			break
+		}
+	}
+	merged.Found = primary.Found
+	merged.Network = primary.Network
+	merged.Status = primary.Status
+	merged.Message = primary.Message
+	merged.Provider = primary.Provider
+
+	if merged.Traits != nil && len(primary.Network) > 0 {
+		traits := *merged.Traits
+		traits.Network = primary.Network
+		merged.Traits = &traits
+	}
+
+	return merged
+}
+
+func (c *Composite) dbVersion(results []*model.IPResult) string {
+	versions := make([]string, 0, len(results))
+	for i, result := range results {
+		if len(result.DBVersion) > 0 {
+			versions = append(versions, c.members[i].Name+"="+result.DBVersion)
+		}
+	}
+	return strings.Join(versions, ",")
+}
+
+// isEmpty reports whether value carries no data. Providers leave the
+// sub-objects a record does not have nil, so a present one counts even when
+// its values are zero, e.g. a location at 0, 0. Traits are the exception:
+// they always carry the network, which is lookup metadata rather than data.
+func isEmpty(name string, value reflect.Value) bool {
+	if name == "traits" && !value.IsNil() {
+		traits := *value.Interface().(*model.Traits)
+		traits.Network = ""
+		return isZero(reflect.ValueOf(traits))
+	}
+	if value.Kind() == reflect.Pointer {
+		return value.IsNil()
+	}
+	return isZero(value)
+}
+
+func isZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil() || isZero(value.Elem())
+	case reflect.Map, reflect.Slice:
+		return value.Len() == 0
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if !isZero(value.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return value.IsZero()
+	}
+}
+
+// Update is applied to every member, in chain order, so an override wins
+// whatever the precedence of the fields it sets. It returns the combined
+// version of the members.
+//
+// The update is not atomic across the members. Every member checks it before
+// any writes it, and when a member still fails, the members already updated
+// are rolled back to the version they were at. No other change is made
+// through the composite in between, so that undoes only this update. A member
+// that cannot be rolled back is named in a *PartialError.
+func (c *Composite) Update(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	for _, m := range c.members {
+		if checker, ok := m.Provider.(checker); ok {
+			if err := checker.Check(request); err != nil {
+				return "", fmt.Errorf("%s: %w", m.Name, err)
+			}
+		}
+	}
+
+	return c.apply(ctx, request.Author, func(p Provider) (string, error) {
+		return p.Update(ctx, request)
+	})
+}
+
+// BatchUpdate is applied to every member, in chain order, like Update, and is
+// not atomic across the members either. It returns the combined version of
+// the members.
+func (c *Composite) BatchUpdate(ctx context.Context, request *model.IPBatchUpdateRequest) (string, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	for _, m := range c.members {
+		checker, ok := m.Provider.(checker)
+		if !ok {
+			continue
+		}
+		for i, override := range request.Overrides {
+			if err := checker.Check(override); err != nil {
+				return "", fmt.Errorf("%s: overrides[%d]: %w", m.Name, i, err)
+			}
+		}
+	}
+
+	return c.apply(ctx, request.Author, func(p Provider) (string, error) {
+		return p.BatchUpdate(ctx, request)
+	})
+}
+
+// apply runs update on every member in chain order and returns the combined
+// version. When a member fails, the members already updated are rolled back.
+// c.writeMu must be held.
+func (c *Composite) apply(ctx context.Context, author string, update func(p Provider) (string, error)) (string, error) {
+	versions := make([]string, 0, len(c.members))
+	checkpoints := make([]string, len(c.members))
+	var applied []int
+
+	for i, m := range c.members {
+		if checkpointer, ok := m.Provider.(checkpointer); ok {
+			checkpoint, err := checkpointer.Checkpoint()
+			if err != nil {
+				return "", c.undo(ctx, applied, checkpoints, author, fmt.Errorf("%s: %w", m.Name, err))
+			}
+			checkpoints[i] = checkpoint
+		}
+
+		version, err := update(m.Provider)
+		if err != nil {
+			return "", c.undo(ctx, applied, checkpoints, author, fmt.Errorf("%s: %w", m.Name, err))
+		}
+		applied = append(applied, i)
+		versions = append(versions, m.Name+"="+version)
+	}
+
+	return strings.Join(versions, ","), nil
+}
+
+// undo rolls the members in applied back to their checkpoints after err, the
+// failure of another member. It returns err, or a *PartialError naming the
+// members that kept the update.
+func (c *Composite) undo(ctx context.Context, applied []int, checkpoints []string, author string, err error) error {
+	var kept []string
+	var rollbackErrs []error
+	for _, i := range slices.Backward(applied) {
+		m := c.members[i]
+		if len(checkpoints[i]) == 0 {
+			kept = append(kept, m.Name)
+			continue
+		}
+
+		request := &model.RollbackRequest{
+			Version: checkpoints[i],
+			Reason:  "undo an update that failed on another provider",
+			Author:  author,
+		}
+		if _, rerr := m.Provider.Rollback(ctx, request); rerr != nil {
+			kept = append(kept, m.Name)
+			rollbackErrs = append(rollbackErrs, fmt.Errorf("%s: %w", m.Name, rerr))
+		}
+	}
+
+	if len(kept) == 0 {
+		return err
+	}
+	slices.Reverse(kept)
+	return &PartialError{Applied: kept, Err: err, RollbackErr: errors.Join(rollbackErrs...)}
+}
+
+// Remove is applied to every member. Members without an override for the
+// target are skipped; it only fails with model.ErrOverrideNotFound when no
+// member had one.
+func (c *Composite) Remove(ctx context.Context, request *model.IPRemoveRequest) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	removed := false
+	for _, m := range c.members {
+		err := m.Provider.Remove(ctx, request)
+		if errors.Is(err, model.ErrOverrideNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.Name, err)
+		}
+		removed = true
+	}
+	if !removed {
+		return model.ErrOverrideNotFound
+	}
+	return nil
+}
+
+// ListOverrides lists the overrides of the first member. Every override is
+// applied to all members, so they all hold the same ones.
+func (c *Composite) ListOverrides(ctx context.Context, request *model.IPOverrideListRequest) (*model.IPOverrideList, error) {
+	return c.members[0].Provider.ListOverrides(ctx, request)
+}
+
+// OverrideHistory returns the change log of the first member, which records
+// every change like the others do.
+func (c *Composite) OverrideHistory(ctx context.Context, request *model.IPOverrideHistoryRequest) ([]*model.IPOverrideChange, error) {
+	return c.members[0].Provider.OverrideHistory(ctx, request)
+}
+
+// Rollback rolls back each member named in the request's version, a combined
+// version as reported in IPResult.DBVersion, to its own version. A plain
+// version, as found in the change log, is the first member's. It returns the
+// combined version of the members that were rolled back.
+func (c *Composite) Rollback(ctx context.Context, request *model.RollbackRequest) (string, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	targets := make(map[string]string)
+	if !strings.Contains(request.Version, "=") {
+		targets[c.members[0].Name] = request.Version
+	}
+	for _, part := range strings.Split(request.Version, ",") {
+		name, version, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		if !slices.ContainsFunc(c.members, func(m Member) bool { return m.Name == name }) {
+			return "", fmt.Errorf("%w: provider %q is not in the chain", model.ErrVersionNotFound, name)
+		}
+		targets[name] = version
+	}
+
+	versions := make([]string, 0, len(targets))
+	for _, m := range c.members {
+		version, ok := targets[m.Name]
+		if !ok {
+			continue
+		}
+		memberRequest := *request
+		memberRequest.Version = version
+		version, err := m.Provider.Rollback(ctx, &memberRequest)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", m.Name, err)
+		}
+		versions = append(versions, m.Name+"="+version)
+	}
+	return strings.Join(versions, ","), nil
+}
@@ -0,0 +1,320 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/memory"
+	"slices"
+	"testing"
+	"time"
+)
+
+var errRejected = errors.New("rejected")
+
+// failing is a member that fails the updates of one IP. Its Update can be
+// held until release is closed, to make another change while it runs.
+type failing struct {
+	*memory.Memory
+	ip      string
+	check   bool
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (f *failing) Check(request *model.IPUpdateRequest) error {
+	if f.check && request.IP == f.ip {
+		return errRejected
+	}
+	return f.Memory.Check(request)
+}
+
+func (f *failing) Update(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	if request.IP != f.ip {
+		return f.Memory.Update(ctx, request)
+	}
+	if f.entered != nil {
+		close(f.entered)
+		<-f.release
+	}
+	return "", errRejected
+}
+
+// uncheckpointed is a member that cannot be rolled back to where it was.
+type uncheckpointed struct {
+	Provider
+}
+
+func country(t *testing.T, p Provider, ip string) string {
+	t.Helper()
+
+	results, err := p.Lookup(context.Background(), &model.IPLookupRequest{IPs: []string{ip}})
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if results[0].Country == nil {
+		return ""
+	}
+	return results[0].Country.ISOCode
+}
+
+func override(ip, country string) *model.IPUpdateRequest {
+	return &model.IPUpdateRequest{IP: ip, Country: &model.Country{ISOCode: country}, Reason: "test"}
+}
+
+func TestUpdateRollsBackAppliedMembers(t *testing.T) {
+	first := memory.New("first", nil)
+	c, err := New([]Member{
+		{Name: "first", Provider: first},
+		{Name: "second", Provider: &failing{Memory: memory.New("second", nil), ip: "81.2.69.142"}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Update(context.Background(), override("81.2.69.142", "VN"))
+	var partial *PartialError
+	if !errors.Is(err, errRejected) || errors.As(err, &partial) {
+		t.Fatalf("Update() error = %v, want %v without a partial update", err, errRejected)
+	}
+	if got := country(t, first, "81.2.69.142"); got != "" {
+		t.Fatalf("first member kept country %q, want the update rolled back", got)
+	}
+}
+
+func TestUpdateCheckedBeforeWriting(t *testing.T) {
+	first := memory.New("first", nil)
+	c, err := New([]Member{
+		{Name: "first", Provider: first},
+		{Name: "second", Provider: &failing{Memory: memory.New("second", nil), ip: "81.2.69.142", check: true}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := first.Version()
+	if _, err = c.Update(context.Background(), override("81.2.69.142", "VN")); !errors.Is(err, errRejected) {
+		t.Fatalf("Update() error = %v, want %v", err, errRejected)
+	}
+	if after := first.Version(); after != before {
+		t.Fatalf("first member went from version %s to %s, want it untouched", before, after)
+	}
+}
+
+func TestUpdateReportsMembersKeepingIt(t *testing.T) {
+	first := memory.New("first", nil)
+	c, err := New([]Member{
+		{Name: "first", Provider: uncheckpointed{first}},
+		{Name: "second", Provider: &failing{Memory: memory.New("second", nil), ip: "81.2.69.142"}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Update(context.Background(), override("81.2.69.142", "VN"))
+	var partial *PartialError
+	if !errors.As(err, &partial) || !slices.Equal(partial.Applied, []string{"first"}) {
+		t.Fatalf("Update() error = %v, want a *PartialError applied to first", err)
+	}
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("Update() error = %v, want it to wrap %v", err, errRejected)
+	}
+	if got := country(t, first, "81.2.69.142"); got != "VN" {
+		t.Fatalf("first member has country %q, want the kept update", got)
+	}
+}
+
+// A failed update is rolled back without taking an update made by another
+// caller in the meantime with it.
+func TestUpdateRollbackKeepsConcurrentUpdates(t *testing.T) {
+	first := memory.New("first", nil)
+	second := &failing{
+		Memory:  memory.New("second", nil),
+		ip:      "81.2.69.142",
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	c, err := New([]Member{{Name: "first", Provider: first}, {Name: "second", Provider: second}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := make(chan error)
+	go func() {
+		_, err := c.Update(context.Background(), override("81.2.69.142", "VN"))
+		failed <- err
+	}()
+	<-second.entered
+
+	updated := make(chan error)
+	go func() {
+		_, err := c.Update(context.Background(), override("81.2.70.1", "FR"))
+		updated <- err
+	}()
+	select {
+	case err = <-updated:
+		t.Fatalf("Update() returned %v while another update was being made, want it to wait", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(second.release)
+	if err = <-failed; !errors.Is(err, errRejected) {
+		t.Fatalf("failing Update() error = %v, want %v", err, errRejected)
+	}
+	if err = <-updated; err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	for name, p := range map[string]Provider{"first": first, "second": second} {
+		if got := country(t, p, "81.2.70.1"); got != "FR" {
+			t.Fatalf("%s member has country %q for the concurrent update, want FR", name, got)
+		}
+		if got := country(t, p, "81.2.69.142"); got != "" {
+			t.Fatalf("%s member has country %q for the failed update, want none", name, got)
+		}
+	}
+}
+
+func lookupOne(t *testing.T, p Provider, ip string) *model.IPResult {
+	t.Helper()
+
+	results, err := p.Lookup(context.Background(), &model.IPLookupRequest{IPs: []string{ip}})
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	return results[0]
+}
+
+func update(t *testing.T, p Provider, request *model.IPUpdateRequest) string {
+	t.Helper()
+
+	version, err := p.Update(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	return version
+}
+
+func TestLookupMergesFieldsByPrecedence(t *testing.T) {
+	first, second := memory.New("first", nil), memory.New("second", nil)
+	update(t, first, &model.IPUpdateRequest{IP: "81.2.69.142", Country: &model.Country{ISOCode: "VN"}})
+	update(t, second, &model.IPUpdateRequest{
+		IP:      "81.2.69.142",
+		Country: &model.Country{ISOCode: "FR"},
+		City:    &model.City{Names: map[string]string{"en": "Paris"}},
+	})
+
+	c, err := New([]Member{{Name: "first", Provider: first}, {Name: "second", Provider: second}},
+		map[string][]string{"city": {"second"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := lookupOne(t, c, "81.2.69.142")
+	if result.Country == nil || result.Country.ISOCode != "VN" {
+		t.Fatalf("country = %+v, want VN of the first member", result.Country)
+	}
+	if result.City == nil || result.City.Names["en"] != "Paris" {
+		t.Fatalf("city = %+v, want Paris of the second member", result.City)
+	}
+	if result.Sources["country"] != "first" || result.Sources["city"] != "second" {
+		t.Fatalf("sources = %v, want country from first and city from second", result.Sources)
+	}
+	if result.DBVersion != "first="+first.Version()+",second="+second.Version() {
+		t.Fatalf("DBVersion = %q, want the versions of both members", result.DBVersion)
+	}
+
+	c, err = New([]Member{{Name: "first", Provider: first}, {Name: "second", Provider: second}},
+		map[string][]string{"country": {"second"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := country(t, c, "81.2.69.142"); got != "FR" {
+		t.Fatalf("country = %q, want FR of the member that takes precedence", got)
+	}
+}
+
+func TestLookupFallsBackToMemberThatFoundIt(t *testing.T) {
+	first, second := memory.New("first", nil), memory.New("second", nil)
+	update(t, second, &model.IPUpdateRequest{Network: "81.2.69.0/24", Country: &model.Country{ISOCode: "VN"}})
+
+	c, err := New([]Member{{Name: "first", Provider: first}, {Name: "second", Provider: second}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := lookupOne(t, c, "81.2.69.142")
+	if !result.Found || result.Status != model.LookupStatusOK {
+		t.Fatalf("result = %+v, want it found by the second member", result)
+	}
+	if result.Provider != "second" || result.Network != "81.2.69.0/24" {
+		t.Fatalf("provider, network = %q, %q, want second, 81.2.69.0/24", result.Provider, result.Network)
+	}
+
+	result = lookupOne(t, c, "89.160.20.112")
+	if result.Found || result.Status != model.LookupStatusNotFound {
+		t.Fatalf("result = %+v, want it not found", result)
+	}
+}
+
+func TestNewRejectsInvalidPrecedence(t *testing.T) {
+	members := []Member{{Name: "first", Provider: memory.New("first", nil)}}
+
+	if _, err := New(nil, nil); err == nil {
+		t.Fatal("New() without members error = nil")
+	}
+	if _, err := New(members, map[string][]string{"ip": {"first"}}); err == nil {
+		t.Fatal("New() with precedence of a metadata field error = nil")
+	}
+	if _, err := New(members, map[string][]string{"city": {"second"}}); err == nil {
+		t.Fatal("New() with precedence of an unknown member error = nil")
+	}
+}
+
+func TestRemoveFromEveryMember(t *testing.T) {
+	first, second := memory.New("first", nil), memory.New("second", nil)
+	c, err := New([]Member{{Name: "first", Provider: first}, {Name: "second", Provider: second}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(t, c, override("81.2.69.142", "VN"))
+
+	if err = c.Remove(context.Background(), &model.IPRemoveRequest{IP: "81.2.69.142"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	for name, p := range map[string]Provider{"first": first, "second": second} {
+		if got := country(t, p, "81.2.69.142"); got != "" {
+			t.Fatalf("%s member kept country %q, want the override removed", name, got)
+		}
+	}
+
+	err = c.Remove(context.Background(), &model.IPRemoveRequest{IP: "81.2.69.142"})
+	if !errors.Is(err, model.ErrOverrideNotFound) {
+		t.Fatalf("second Remove() error = %v, want %v", err, model.ErrOverrideNotFound)
+	}
+}
+
+func TestRollbackToCombinedVersion(t *testing.T) {
+	first, second := memory.New("first", nil), memory.New("second", nil)
+	c, err := New([]Member{{Name: "first", Provider: first}, {Name: "second", Provider: second}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := update(t, c, override("81.2.69.142", "VN"))
+	update(t, c, override("81.2.69.142", "FR"))
+
+	if _, err = c.Rollback(context.Background(), &model.RollbackRequest{Version: version, Reason: "test"}); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	for name, p := range map[string]Provider{"first": first, "second": second} {
+		if got := country(t, p, "81.2.69.142"); got != "VN" {
+			t.Fatalf("%s member has country %q, want VN of the version rolled back to", name, got)
+		}
+	}
+
+	_, err = c.Rollback(context.Background(), &model.RollbackRequest{Version: "third=1", Reason: "test"})
+	if !errors.Is(err, model.ErrVersionNotFound) {
+		t.Fatalf("Rollback() error = %v, want %v", err, model.ErrVersionNotFound)
+	}
+}
@@ -0,0 +1,70 @@
+package dbip
+
+import (
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/maxmind"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/memory"
+	"geolize/utilities/conf"
+	"net"
+	"net/netip"
+	"path/filepath"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Name identifies the DB-IP provider in IPResult.Provider.
+const Name = "dbip"
+
+const dbFolder = "data/db/"
+
+var db, _ = conf.GetString("geolize", "dbip_db", "dbip-city-lite.mmdb")
+
+// source reads the DB-IP IP to City Lite database, which is published in the
+// MMDB format with the same schema as GeoIP2 City.
+type source struct {
+	reader  *maxminddb.Reader
+	version string
+}
+
+func (s *source) Lookup(addr netip.Addr) (*model.IPResult, netip.Prefix, bool, error) {
+	if addr.Is6() && s.reader.Metadata.IPVersion == 4 {
+		return nil, netip.Prefix{}, false, fmt.Errorf("cannot look up IPv6 address %s in an IPv4-only database", addr)
+	}
+
+	var record maxmind.CityRecord
+	network, found, err := s.reader.LookupNetwork(net.IP(addr.AsSlice()), &record)
+	if err != nil {
+		return nil, netip.Prefix{}, false, err
+	}
+
+	prefix, err := netip.ParsePrefix(network.String())
+	if err != nil {
+		return nil, netip.Prefix{}, false, err
+	}
+
+	if !found {
+		return nil, prefix, false, nil
+	}
+	return record.Result(network), prefix, true, nil
+}
+
+func (s *source) Version() string {
+	return s.version
+}
+
+// New opens the DB-IP database configured by dbip_db. Overrides are kept in
+// memory on top of it.
+func New() (*memory.Memory, error) {
+	path := filepath.Join(dbFolder, db)
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DB-IP database: %w", err)
+	}
+
+	return memory.New(Name, &source{
+		reader:  reader,
+		version: fmt.Sprintf("%s@%d", db, reader.Metadata.BuildEpoch),
+	}), nil
+}
@@ -0,0 +1,255 @@
+package ip2location
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/memory"
+	"geolize/utilities/conf"
+	"io"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Name identifies the IP2Location provider in IPResult.Provider.
+const Name = "ip2location"
+
+const dbFolder = "data/db/"
+
+var db, _ = conf.GetString("geolize", "ip2location_db", "IP2LOCATION-LITE-DB11.CSV")
+
+// CSV columns of the IP2Location LITE databases. Lower editions stop early:
+// DB1 ends after country_name, DB3 after city_name and DB5 after longitude.
+const (
+	colFrom = iota
+	colTo
+	colCountryCode
+	colCountryName
+	colRegionName
+	colCityName
+	colLatitude
+	colLongitude
+	colZipCode
+)
+
+type row struct {
+	from, to    netip.Addr
+	countryCode string
+	countryName string
+	regionName  string
+	cityName    string
+	latitude    float64
+	longitude   float64
+	zipCode     string
+	// located is set when the edition has coordinates, which may be 0, 0.
+	located bool
+}
+
+// source is an IP2Location LITE CSV loaded into a sorted range table. Both
+// the IPv4 and the IPv6 editions are supported.
+type source struct {
+	rows    []row
+	version string
+}
+
+func (s *source) Lookup(addr netip.Addr) (*model.IPResult, netip.Prefix, bool, error) {
+	i := sort.Search(len(s.rows), func(i int) bool {
+		return s.rows[i].to.Compare(addr) >= 0
+	})
+	if i == len(s.rows) || s.rows[i].from.Compare(addr) > 0 {
+		return nil, netip.Prefix{}, false, nil
+	}
+
+	r := s.rows[i]
+	network := rangePrefix(addr, r.from, r.to)
+	if r.countryCode == "" {
+		return nil, network, false, nil
+	}
+
+	result := &model.IPResult{
+		Country: &model.Country{
+			ISOCode: r.countryCode,
+			Names:   names(r.countryName),
+		},
+	}
+	if r.located {
+		result.Location = &model.Location{Latitude: r.latitude, Longitude: r.longitude}
+	}
+	if len(r.cityName) > 0 {
+		result.City = &model.City{Names: names(r.cityName)}
+	}
+	if len(r.zipCode) > 0 {
+		result.Postal = &model.Postal{Code: r.zipCode}
+	}
+	if len(r.regionName) > 0 {
+		result.Subdivisions = []*model.Subdivision{{Names: names(r.regionName)}}
+	}
+
+	return result, network, true, nil
+}
+
+func (s *source) Version() string {
+	return s.version
+}
+
+func names(name string) map[string]string {
+	if len(name) == 0 {
+		return nil
+	}
+	return map[string]string{"en": name}
+}
+
+// rangePrefix returns the largest network around addr that fits in
+// [from, to]. IP2Location ranges are not always CIDR aligned.
+func rangePrefix(addr, from, to netip.Addr) netip.Prefix {
+	for bits := 0; bits <= addr.BitLen(); bits++ {
+		prefix, _ := addr.Prefix(bits)
+		if prefix.Addr().Compare(from) >= 0 && lastAddr(prefix).Compare(to) <= 0 {
+			return prefix
+		}
+	}
+	return netip.PrefixFrom(addr, addr.BitLen())
+}
+
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(bytes)*8; i++ {
+		bytes[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// parseRange converts the decimal IP numbers of a row into addresses. The
+// IPv6 editions number the whole table in 128 bits, so from takes the width
+// of to: a leading "0" is :: there, not 0.0.0.0. IPv4-mapped addresses, which
+// those editions use for the IPv4 space, become IPv4.
+func parseRange(fromValue, toValue string) (from, to netip.Addr, err error) {
+	toNumber, err := parseNumber(toValue)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, err
+	}
+	fromNumber, err := parseNumber(fromValue)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, err
+	}
+
+	ipv6 := toNumber.BitLen() > 32
+	return numberAddr(fromNumber, ipv6), numberAddr(toNumber, ipv6), nil
+}
+
+func parseNumber(value string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 128 {
+		return nil, fmt.Errorf("invalid IP number %q", value)
+	}
+	return n, nil
+}
+
+func numberAddr(n *big.Int, ipv6 bool) netip.Addr {
+	if !ipv6 {
+		var b [4]byte
+		n.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+
+	var b [16]byte
+	n.FillBytes(b[:])
+	return netip.AddrFrom16(b).Unmap()
+}
+
+func column(record []string, i int) string {
+	if i >= len(record) || record[i] == "-" {
+		return ""
+	}
+	return record[i]
+}
+
+func load(path string) ([]row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(bufio.NewReader(f))
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var rows []row
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < colCountryName+1 {
+			return nil, fmt.Errorf("line %d: expected at least %d columns, got %d", line, colCountryName+1, len(record))
+		}
+
+		from, to, err := parseRange(record[colFrom], record[colTo])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if from.BitLen() != to.BitLen() || from.Compare(to) > 0 {
+			return nil, fmt.Errorf("line %d: invalid range %s-%s", line, from, to)
+		}
+
+		r := row{
+			from:        from,
+			to:          to,
+			countryCode: column(record, colCountryCode),
+			countryName: column(record, colCountryName),
+			regionName:  column(record, colRegionName),
+			cityName:    column(record, colCityName),
+			zipCode:     column(record, colZipCode),
+		}
+		if v := column(record, colLatitude); len(v) > 0 {
+			if r.latitude, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid latitude %q", line, v)
+			}
+			r.located = true
+		}
+		if v := column(record, colLongitude); len(v) > 0 {
+			if r.longitude, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid longitude %q", line, v)
+			}
+		}
+
+		rows = append(rows, r)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].from.Less(rows[j].from)
+	})
+
+	return rows, nil
+}
+
+// New loads the IP2Location LITE CSV configured by ip2location_db. Overrides
+// are kept in memory on top of it.
+func New() (*memory.Memory, error) {
+	path := filepath.Join(dbFolder, db)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open IP2Location database: %w", err)
+	}
+
+	rows, err := load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IP2Location database: %w", err)
+	}
+
+	return memory.New(Name, &source{
+		rows:    rows,
+		version: fmt.Sprintf("%s@%d", db, info.ModTime().Unix()),
+	}), nil
+}
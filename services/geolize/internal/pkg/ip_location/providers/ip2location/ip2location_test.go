@@ -0,0 +1,99 @@
+package ip2location
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// DB11 rows of the IPv4 and the IPv6 LITE editions, time zone included.
+const (
+	ipv4Rows = `"0","16777215","-","-","-","-","0.000000","0.000000","-","-"
+"16777216","16777471","AU","Australia","Queensland","Brisbane","-27.467940","153.028090","4000","+10:00"
+"16777472","16778239","CN","China","Fujian","Fuzhou","26.061390","119.306110","350004","+08:00"
+`
+	ipv6Rows = `"0","281470681743359","-","-","-","-","0.000000","0.000000","-","-"
+"281470681743360","281470698520575","-","-","-","-","0.000000","0.000000","-","-"
+"281470698520576","281470698520831","AU","Australia","Queensland","Brisbane","-27.467940","153.028090","4000","+10:00"
+"42540528726795050063891204319802818560","42540528806023212578155541913346768895","JP","Japan","Tokyo","Tokyo","35.689500","139.691710","100-0001","+09:00"
+`
+)
+
+func testSource(t *testing.T, rows string) *source {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "IP2LOCATION-LITE-DB11.CSV")
+	if err := os.WriteFile(path, []byte(rows), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := load(path)
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if want := strings.Count(rows, "\n"); len(loaded) != want {
+		t.Fatalf("load() returned %d rows, want %d", len(loaded), want)
+	}
+	return &source{rows: loaded}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    string
+		ip      string
+		country string
+		network string
+	}{
+		{name: "IPv4", rows: ipv4Rows, ip: "1.0.0.1", country: "AU", network: "1.0.0.0/24"},
+		{name: "IPv4 unaligned range", rows: ipv4Rows, ip: "1.0.2.1", country: "CN", network: "1.0.2.0/23"},
+		{name: "IPv4 without data", rows: ipv4Rows, ip: "0.1.2.3", network: "0.0.0.0/8"},
+		{name: "IPv4 past the table", rows: ipv4Rows, ip: "8.8.8.8"},
+		{name: "IPv4 in the IPv6 edition", rows: ipv6Rows, ip: "1.0.0.1", country: "AU", network: "1.0.0.0/24"},
+		{name: "IPv4-mapped in the IPv6 edition", rows: ipv6Rows, ip: "::ffff:1.0.0.1", country: "AU", network: "1.0.0.0/24"},
+		{name: "IPv6", rows: ipv6Rows, ip: "2001:200::1", country: "JP", network: "2001:200::/32"},
+		{name: "IPv6 without data", rows: ipv6Rows, ip: "::1", network: "::/81"},
+		{name: "IPv6 past the table", rows: ipv6Rows, ip: "2a00::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSource(t, tt.rows)
+
+			result, network, found, err := s.Lookup(netip.MustParseAddr(tt.ip).Unmap())
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if found != (len(tt.country) > 0) {
+				t.Fatalf("Lookup() found = %v, want %v", found, len(tt.country) > 0)
+			}
+			if found && result.Country.ISOCode != tt.country {
+				t.Fatalf("country = %q, want %q", result.Country.ISOCode, tt.country)
+			}
+			if got := network.String(); len(tt.network) > 0 && got != tt.network {
+				t.Fatalf("network = %s, want %s", got, tt.network)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidRanges(t *testing.T) {
+	for name, rows := range map[string]string{
+		"reversed":             `"16777471","16777216","AU","Australia"` + "\n",
+		"across IPv4-mapped":   `"281470681743359","281470698520575","-","-"` + "\n",
+		"not a number":         `"1.0.0.0","16777471","AU","Australia"` + "\n",
+		"above the IPv6 space": `"0","340282366920938463463374607431768211456","-","-"` + "\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "IP2LOCATION-LITE-DB1.CSV")
+			if err := os.WriteFile(path, []byte(rows), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := load(path); err == nil {
+				t.Fatalf("load() accepted %q", rows)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package maxmind
+
+import (
+	"encoding/json"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"math/big"
+	"net"
+
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+)
+
+// record returns the record of the first address of network in the tree, or
+// an empty map when there is none.
+func (w *Writer) record(network *net.IPNet) mmdbtype.Map {
+	_, value := w.writer.Get(network.IP)
+	if record, ok := value.(mmdbtype.Map); ok {
+		return record.Copy().(mmdbtype.Map)
+	}
+	return mmdbtype.Map{}
+}
+
+// newAudit describes the change of the record of network from before to
+// after.
+func newAudit(network *net.IPNet, before, after mmdbtype.Map) (*audit, error) {
+	b, err := json.Marshal(plain(before))
+	if err != nil {
+		return nil, err
+	}
+	a, err := json.Marshal(plain(after))
+	if err != nil {
+		return nil, err
+	}
+	return &audit{Network: network.String(), Before: string(b), After: string(a)}, nil
+}
+
+// overrideAudit describes the change override makes to the record of its
+// network, without touching the tree.
+func (w *Writer) overrideAudit(override *model.IPUpdateRequest) (*audit, error) {
+	network, err := overrideNetwork(override)
+	if err != nil {
+		return nil, err
+	}
+
+	before := w.record(network)
+	after := before.Copy().(mmdbtype.Map)
+	applyOverride(after, override)
+
+	return newAudit(network, before, after)
+}
+
+// batchAudits describes the change overrides, applied in order, make to the
+// record of each of their networks, without touching the tree.
+func (w *Writer) batchAudits(overrides []*model.IPUpdateRequest) ([]*audit, error) {
+	var diffs []*audit
+	seen := make(map[string]bool)
+	for _, override := range overrides {
+		network, err := overrideNetwork(override)
+		if err != nil {
+			return nil, err
+		}
+		if seen[network.String()] {
+			continue
+		}
+		seen[network.String()] = true
+
+		// The record of the first address of network ends up with every
+		// override whose network contains it.
+		before := w.record(network)
+		after := before.Copy().(mmdbtype.Map)
+		for _, other := range overrides {
+			if otherNetwork, err := overrideNetwork(other); err == nil && otherNetwork.Contains(network.IP) {
+				applyOverride(after, other)
+			}
+		}
+
+		diff, err := newAudit(network, before, after)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// plain converts an mmdb value into the values encoding/json works with.
+func plain(value mmdbtype.DataType) any {
+	switch v := value.(type) {
+	case mmdbtype.Map:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[string(key)] = plain(item)
+		}
+		return m
+	case mmdbtype.Slice:
+		s := make([]any, len(v))
+		for i, item := range v {
+			s[i] = plain(item)
+		}
+		return s
+	case mmdbtype.String:
+		return string(v)
+	case mmdbtype.Bool:
+		return bool(v)
+	case mmdbtype.Bytes:
+		return []byte(v)
+	case mmdbtype.Float32:
+		return float32(v)
+	case mmdbtype.Float64:
+		return float64(v)
+	case mmdbtype.Int32:
+		return int32(v)
+	case mmdbtype.Uint16:
+		return uint16(v)
+	case mmdbtype.Uint32:
+		return uint32(v)
+	case mmdbtype.Uint64:
+		return uint64(v)
+	case *mmdbtype.Uint128:
+		return (*big.Int)(v).String()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+package maxmind
+
+import (
+	"context"
+	"fmt"
+	"geolize/utilities/conf"
+	"geolize/utilities/logging"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// baseSettle is how long a dropped-in database must stay unchanged before it
+// is picked up, so a file that is still being copied is never read.
+var baseSettle, _ = conf.GetDuration("geolize", "base_db_settle", 5*time.Second)
+
+// baseWatcher picks up new base databases dropped into dbFolder. Every .mmdb
+// file other than the active database and the edition databases is a
+// candidate; onSettled is called once it has stopped changing. Changes to the
+// active database itself are reported to onActive once settled, since the
+// writer rewrites it with every change.
+type baseWatcher struct {
+	logger    logging.Logger
+	ignore    func(file string) bool
+	onSettled func(path string)
+	onActive  func(path string)
+	settle    time.Duration
+	watcher   *fsnotify.Watcher
+	done      chan struct{}
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newBaseWatcher(logger logging.Logger, ignore func(file string) bool, onSettled, onActive func(path string)) (*baseWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+
+	if err = watcher.Add(dbFolder); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch database folder: %w", err)
+	}
+
+	w := &baseWatcher{
+		logger:    logger,
+		ignore:    ignore,
+		onSettled: onSettled,
+		onActive:  onActive,
+		settle:    baseSettle,
+		watcher:   watcher,
+		done:      make(chan struct{}),
+		timers:    make(map[string]*time.Timer),
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+func (w *baseWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			file := filepath.Base(event.Name)
+			if !strings.HasSuffix(file, ".mmdb") || w.ignore(file) {
+				continue
+			}
+			if file == db {
+				w.schedule(event.Name, w.onActive)
+				continue
+			}
+			w.schedule(event.Name, w.onSettled)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error(context.Background(), "Watcher error", logging.NewError(err)...)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// schedule (re)starts the settle timer of path, which calls fn once it fires.
+func (w *baseWatcher) schedule(path string, fn func(path string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if timer, ok := w.timers[path]; ok {
+		timer.Reset(w.settle)
+		return
+	}
+
+	w.timers[path] = time.AfterFunc(w.settle, func() {
+		w.mu.Lock()
+		delete(w.timers, path)
+		w.mu.Unlock()
+
+		fn(path)
+	})
+}
+
+func (w *baseWatcher) Close() {
+	w.watcher.Close()
+	close(w.done)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, timer := range w.timers {
+		timer.Stop()
+		delete(w.timers, path)
+	}
+}
+
+// validateBase checks that the database at path is intact and of the same
+// type as the active one, e.g. a GeoLite2-City file is never replaced by an
+// ASN file.
+func validateBase(path string, current *database) error {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer reader.Close()
+
+	if err = reader.Verify(); err != nil {
+		return fmt.Errorf("database is corrupt: %w", err)
+	}
+
+	if want := current.reader.Metadata.DatabaseType; reader.Metadata.DatabaseType != want {
+		return fmt.Errorf("database type %q does not match active database type %q", reader.Metadata.DatabaseType, want)
+	}
+
+	return nil
+}
+
+// installBase rebuilds the active database on top of the new base database at
+// path and moves the pristine file into dbBaseFolder. Lookups keep being
+// served from the current database until the Reader swaps in the rebuilt one.
+func (m *Maxmind) installBase(ctx context.Context, path string) error {
+	writer := m.writer.Load()
+	if writer == nil {
+		return fmt.Errorf("writer is not ready yet")
+	}
+
+	current, err := m.reader.Acquire()
+	if err != nil {
+		return err
+	}
+	err = validateBase(path, current)
+	current.Release()
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info(ctx, "New base database detected. Rebuilding...", logging.NewKeyVal("path", path))
+
+	if err = writer.Rebase(ctx, path); err != nil {
+		return fmt.Errorf("failed to rebuild database: %w", err)
+	}
+
+	m.logger.Info(ctx, "Database rebuilt on new base", logging.NewKeyVal("base", filepath.Base(path)))
+
+	return nil
+}
+
+// onActiveChanged warns when the active database was replaced in place
+// rather than written by the writer. The replacement is not rebuilt on, and
+// the next change overwrites it.
+func (m *Maxmind) onActiveChanged(path string) {
+	writer := m.writer.Load()
+	if writer == nil || writer.wrote(path) {
+		return
+	}
+
+	m.logger.Warn(context.Background(), "Active database was replaced in place and is ignored. Only new base databases are picked up: "+
+		"drop the new database into the database folder under another name to rebuild on it",
+		logging.NewKeyVal("path", path))
+}
+
+// onBaseDropped installs a database dropped into dbFolder.
+func (m *Maxmind) onBaseDropped(path string) {
+	if err := m.installBase(context.Background(), path); err != nil {
+		m.logger.Warn(context.Background(), "Ignoring database dropped into database folder",
+			append(logging.NewError(err), logging.NewKeyVal("path", path))...)
+	}
+}
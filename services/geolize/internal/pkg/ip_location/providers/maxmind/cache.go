@@ -0,0 +1,176 @@
+package maxmind
+
+import (
+	"container/list"
+	"context"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/conf"
+	"geolize/utilities/logging"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	cacheSize, _          = conf.GetInt32("geolize", "cache_size", 10000)
+	cacheTTL, _           = conf.GetDuration("geolize", "cache_ttl", 10*time.Minute)
+	cacheStatsInterval, _ = conf.GetDuration("geolize", "cache_stats_interval", time.Minute)
+)
+
+type cacheEntry struct {
+	key       string
+	result    *model.IPResult
+	expiresAt time.Time
+}
+
+// lookupCache is a bounded LRU of lookup results keyed by IP, DB version and
+// requested fields. Cached results are shared between callers and must not be
+// modified.
+type lookupCache struct {
+	mu      sync.Mutex
+	size    int32
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// newLookupCache returns nil when size is not positive, which disables
+// caching; every method is safe to call on a nil cache.
+func newLookupCache(size int32, ttl time.Duration) *lookupCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &lookupCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+func cacheKey(ip, version string, fields []string) string {
+	if len(fields) > 1 {
+		fields = slices.Clone(fields)
+		slices.Sort(fields)
+	}
+	return version + "|" + ip + "|" + strings.Join(fields, ",")
+}
+
+func (c *lookupCache) Get(ip, version string, fields []string) (*model.IPResult, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	key := cacheKey(ip, version, fields)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	c.hits.Add(1)
+	return entry.result, true
+}
+
+func (c *lookupCache) Add(ip, version string, fields []string, result *model.IPResult) {
+	if c == nil {
+		return
+	}
+
+	key := cacheKey(ip, version, fields)
+	expiresAt := time.Now().Add(c.ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.result = result
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result, expiresAt: expiresAt})
+
+	for c.order.Len() > int(c.size) {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Purge drops every entry. Hit and miss counters are kept.
+func (c *lookupCache) Purge() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.order.Init()
+}
+
+// Stats returns the hit and miss counts and the resulting hit ratio.
+func (c *lookupCache) Stats() (hits, misses uint64, ratio float64) {
+	if c == nil {
+		return 0, 0, 0
+	}
+
+	hits, misses = c.hits.Load(), c.misses.Load()
+	if total := hits + misses; total > 0 {
+		ratio = float64(hits) / float64(total)
+	}
+	return hits, misses, ratio
+}
+
+func (c *lookupCache) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// reportStats logs the cache hit ratio every interval.
+func (c *lookupCache) reportStats(logger logging.Logger, interval time.Duration) {
+	if c == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		hits, misses, ratio := c.Stats()
+		logger.Info(context.Background(), "Lookup cache stats",
+			logging.NewKeyVal("entries", c.Len()),
+			logging.NewKeyVal("hits", hits),
+			logging.NewKeyVal("misses", misses),
+			logging.NewKeyVal("hit_ratio", ratio))
+	}
+}
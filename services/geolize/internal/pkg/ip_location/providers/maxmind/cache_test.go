@@ -0,0 +1,81 @@
+package maxmind
+
+import (
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"testing"
+	"time"
+)
+
+func cachedResult(country string) *model.IPResult {
+	return &model.IPResult{Country: &model.Country{ISOCode: country}}
+}
+
+func TestLookupCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLookupCache(2, time.Minute)
+
+	c.Add("81.2.69.1", "v1", nil, cachedResult("GB"))
+	c.Add("89.160.20.1", "v1", nil, cachedResult("SE"))
+	if _, ok := c.Get("81.2.69.1", "v1", nil); !ok {
+		t.Fatal("Get() missed a cached result")
+	}
+	c.Add("2.125.160.1", "v1", nil, cachedResult("GB"))
+
+	if _, ok := c.Get("89.160.20.1", "v1", nil); ok {
+		t.Fatal("least recently used result was not evicted")
+	}
+	if result, ok := c.Get("81.2.69.1", "v1", nil); !ok || result.Country.ISOCode != "GB" {
+		t.Fatalf("Get() = %v, %v, want the recently used result", result, ok)
+	}
+	if n := c.Len(); n != 2 {
+		t.Fatalf("Len() = %d, want 2", n)
+	}
+}
+
+func TestLookupCacheKeys(t *testing.T) {
+	c := newLookupCache(10, time.Minute)
+	c.Add("81.2.69.1", "v1", []string{"country", "city"}, cachedResult("GB"))
+
+	if _, ok := c.Get("81.2.69.1", "v1", []string{"city", "country"}); !ok {
+		t.Fatal("Get() missed the same fields in another order")
+	}
+	if _, ok := c.Get("81.2.69.1", "v2", []string{"country", "city"}); ok {
+		t.Fatal("Get() hit a result of another version")
+	}
+	if _, ok := c.Get("81.2.69.1", "v1", nil); ok {
+		t.Fatal("Get() hit a result of other fields")
+	}
+
+	hits, misses, ratio := c.Stats()
+	if hits != 1 || misses != 2 || ratio != 1.0/3 {
+		t.Fatalf("Stats() = %d, %d, %v, want 1, 2, %v", hits, misses, ratio, 1.0/3)
+	}
+}
+
+func TestLookupCacheExpires(t *testing.T) {
+	c := newLookupCache(10, time.Millisecond)
+	c.Add("81.2.69.1", "v1", nil, cachedResult("GB"))
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get("81.2.69.1", "v1", nil); ok {
+		t.Fatal("Get() hit an expired result")
+	}
+	if n := c.Len(); n != 0 {
+		t.Fatalf("Len() = %d after expiry, want 0", n)
+	}
+}
+
+func TestLookupCacheDisabled(t *testing.T) {
+	c := newLookupCache(0, time.Minute)
+	if c != nil {
+		t.Fatal("newLookupCache(0) returned a cache")
+	}
+
+	c.Add("81.2.69.1", "v1", nil, cachedResult("GB"))
+	if _, ok := c.Get("81.2.69.1", "v1", nil); ok {
+		t.Fatal("disabled cache hit")
+	}
+	c.Purge()
+	if n := c.Len(); n != 0 {
+		t.Fatalf("Len() = %d, want 0", n)
+	}
+}
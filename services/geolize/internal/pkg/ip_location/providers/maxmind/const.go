@@ -8,8 +8,17 @@ const (
 	versionFilePath = "data/version"
 	dbFolder        = "data/db/"
 	dbHistories     = "data/histories/"
+	// dbBaseFolder keeps the pristine base databases that were dropped into
+	// dbFolder, before any override was applied to them.
+	dbBaseFolder = "data/base/"
 )
 
 var (
 	db, _ = conf.GetString("geolize", "db", "GeoLite2-City.mmdb")
+
+	// Optional databases merged into every lookup. Empty means not configured.
+	asnDB, _            = conf.GetString("geolize", "asn_db", "")
+	anonymousIPDB, _    = conf.GetString("geolize", "anonymous_ip_db", "")
+	connectionTypeDB, _ = conf.GetString("geolize", "connection_type_db", "")
+	countryDB, _        = conf.GetString("geolize", "country_db", "")
 )
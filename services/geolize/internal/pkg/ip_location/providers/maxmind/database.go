@@ -0,0 +1,80 @@
+package maxmind
+
+import (
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"net"
+	"sync/atomic"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// database is an open City database together with the version it was loaded
+// for. It is reference counted: the Reader holds one reference while the
+// database is published and every in-flight lookup holds another, so the mmap
+// is only closed once the database has been swapped out and drained.
+type database struct {
+	reader  *maxminddb.Reader
+	version string
+	refs    atomic.Int64
+}
+
+func openDatabase(path string, version string) (*database, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &database{reader: reader, version: version}
+	d.refs.Store(1)
+	return d, nil
+}
+
+// acquire takes a reference unless the database has already been drained.
+func (d *database) acquire() bool {
+	for {
+		refs := d.refs.Load()
+		if refs <= 0 {
+			return false
+		}
+		if d.refs.CompareAndSwap(refs, refs+1) {
+			return true
+		}
+	}
+}
+
+// Release drops a reference and closes the database after the last one.
+func (d *database) Release() {
+	if d.refs.Add(-1) == 0 {
+		d.reader.Close()
+	}
+}
+
+func (d *database) Version() string {
+	return d.version
+}
+
+// Lookup returns the City record for ip together with the network that
+// matched it. found is false when the database has no record for the address;
+// network is then the enclosing empty network, which is still safe to cache.
+// sections limits decoding to those top-level record keys; empty means all.
+func (d *database) Lookup(ip string, sections ...string) (record *CityRecord, network *net.IPNet, found bool, err error) {
+	addr, err := ipaddr.Parse(ip)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if addr.Is6() && d.reader.Metadata.IPVersion == 4 {
+		return nil, nil, false, fmt.Errorf("cannot look up IPv6 address %s in an IPv4-only database", addr)
+	}
+
+	record = &CityRecord{}
+	target, fill := decodeTarget(record, sections)
+	network, found, err = d.reader.LookupNetwork(net.IP(addr.AsSlice()), target)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	fill()
+
+	return record, network, found, nil
+}
@@ -0,0 +1,341 @@
+package maxmind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/logging"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Edition names a MaxMind database that can be merged into City lookups.
+type Edition string
+
+const (
+	EditionASN            Edition = "asn"
+	EditionAnonymousIP    Edition = "anonymous_ip"
+	EditionConnectionType Edition = "connection_type"
+	EditionCountry        Edition = "country"
+)
+
+// mergeFunc looks ip up in an edition database and merges the record into result.
+type mergeFunc func(reader *geoip2.Reader, ip net.IP, result *model.IPResult) error
+
+var editionMergers = map[Edition]mergeFunc{
+	EditionASN: func(reader *geoip2.Reader, ip net.IP, result *model.IPResult) error {
+		record, err := reader.ASN(ip)
+		if err != nil {
+			return err
+		}
+		result.ASN = record.AutonomousSystemNumber
+		result.ASOrg = record.AutonomousSystemOrganization
+		return nil
+	},
+	EditionAnonymousIP: func(reader *geoip2.Reader, ip net.IP, result *model.IPResult) error {
+		record, err := reader.AnonymousIP(ip)
+		if err != nil {
+			return err
+		}
+		result.IsAnonymous = record.IsAnonymous
+		result.IsVPN = record.IsAnonymousVPN
+		result.IsTor = record.IsTorExitNode
+		result.IsHostingProvider = record.IsHostingProvider
+		result.IsPublicProxy = record.IsPublicProxy
+		result.IsResidentialProxy = record.IsResidentialProxy
+		return nil
+	},
+	EditionConnectionType: func(reader *geoip2.Reader, ip net.IP, result *model.IPResult) error {
+		record, err := reader.ConnectionType(ip)
+		if err != nil {
+			return err
+		}
+		result.ConnectionType = record.ConnectionType
+		return nil
+	},
+	EditionCountry: func(reader *geoip2.Reader, ip net.IP, result *model.IPResult) error {
+		// The City database is a superset of Country, so only fill the gap
+		// when the City record has no country.
+		if result.Country != nil && len(result.Country.ISOCode) > 0 {
+			return nil
+		}
+
+		record, err := reader.Country(ip)
+		if err != nil {
+			return err
+		}
+		if len(record.Country.IsoCode) == 0 {
+			return nil
+		}
+
+		result.Continent = &model.Continent{
+			Code:  record.Continent.Code,
+			Names: record.Continent.Names,
+		}
+		result.Country = &model.Country{
+			ISOCode:           record.Country.IsoCode,
+			Names:             record.Country.Names,
+			IsInEuropeanUnion: record.Country.IsInEuropeanUnion,
+		}
+		result.RegisteredCountry = &model.RegisteredCountry{
+			ISOCode:           record.RegisteredCountry.IsoCode,
+			Names:             record.RegisteredCountry.Names,
+			IsInEuropeanUnion: record.RegisteredCountry.IsInEuropeanUnion,
+		}
+		return nil
+	},
+}
+
+// editionFields are the result fields each edition fills, used to skip
+// editions the caller did not ask for.
+var editionFields = map[Edition][]string{
+	EditionASN:            {"asn", "as_org"},
+	EditionAnonymousIP:    {"is_anonymous", "is_vpn", "is_tor", "is_hosting_provider", "is_public_proxy", "is_residential_proxy"},
+	EditionConnectionType: {"connection_type"},
+	EditionCountry:        {"country", "continent", "registered_country"},
+}
+
+type editionReader struct {
+	edition Edition
+	file    string
+
+	mu     sync.RWMutex
+	reader *geoip2.Reader
+}
+
+func (e *editionReader) load() error {
+	reader, err := geoip2.Open(filepath.Join(dbFolder, e.file))
+	if err != nil {
+		return err
+	}
+
+	// Opening the wrong edition only fails at lookup time, so probe it once.
+	var invalidMethod geoip2.InvalidMethodError
+	if err = editionMergers[e.edition](reader, net.IPv4zero, &model.IPResult{}); errors.As(err, &invalidMethod) {
+		reader.Close()
+		return fmt.Errorf("%s is not a %s database: %w", e.file, e.edition, err)
+	}
+
+	e.mu.Lock()
+	old := e.reader
+	e.reader = reader
+	e.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+
+	return nil
+}
+
+func (e *editionReader) merge(ip net.IP, result *model.IPResult) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.reader == nil {
+		return nil
+	}
+
+	return editionMergers[e.edition](e.reader, ip, result)
+}
+
+// epoch returns the build epoch of the loaded database, or 0 when none is
+// loaded.
+func (e *editionReader) epoch() uint {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.reader == nil {
+		return 0
+	}
+	return e.reader.Metadata().BuildEpoch
+}
+
+func (e *editionReader) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.reader != nil {
+		e.reader.Close()
+		e.reader = nil
+	}
+}
+
+// editionRegistry holds the optional MaxMind databases that are merged into
+// City lookups. Editions without a configured file are skipped, and a
+// configured file that is missing is picked up as soon as it is dropped into
+// dbFolder.
+type editionRegistry struct {
+	logger   logging.Logger
+	editions []*editionReader
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+
+	// onReload is set before the watcher starts and never changes.
+	onReload func(edition Edition)
+}
+
+// newEditionRegistry loads the edition files and watches them for changes.
+// onReload, which may be nil, is called after an edition has been reloaded.
+func newEditionRegistry(logger logging.Logger, files map[Edition]string, onReload func(edition Edition)) (*editionRegistry, error) {
+	registry := &editionRegistry{
+		logger:   logger,
+		onReload: onReload,
+	}
+
+	for _, edition := range []Edition{EditionCountry, EditionASN, EditionAnonymousIP, EditionConnectionType} {
+		file := files[edition]
+		if len(file) == 0 {
+			continue
+		}
+
+		e := &editionReader{edition: edition, file: file}
+		if err := e.load(); err != nil {
+			logger.Warn(context.Background(), "MaxMind edition is not available, skipping",
+				append(logging.NewError(err), logging.NewKeyVal("edition", edition), logging.NewKeyVal("file", file))...)
+		} else {
+			logger.Info(context.Background(), "MaxMind edition loaded", logging.NewKeyVal("edition", edition), logging.NewKeyVal("file", file))
+		}
+
+		registry.editions = append(registry.editions, e)
+	}
+
+	if len(registry.editions) == 0 {
+		return registry, nil
+	}
+
+	if err := registry.watch(); err != nil {
+		registry.Close()
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// Merge enriches result with every loaded edition that fills one of fields
+// (all of them when fields is empty). Failures are logged and skipped so a
+// broken optional database never fails the City lookup.
+func (r *editionRegistry) Merge(ctx context.Context, ip string, result *model.IPResult, fields []string) {
+	if r == nil || len(r.editions) == 0 {
+		return
+	}
+
+	addr, err := ipaddr.Parse(ip)
+	if err != nil {
+		return
+	}
+
+	for _, e := range r.editions {
+		if len(fields) > 0 && !slices.ContainsFunc(editionFields[e.edition], func(field string) bool {
+			return slices.Contains(fields, field)
+		}) {
+			continue
+		}
+		if err = e.merge(net.IP(addr.AsSlice()), result); err != nil {
+			r.logger.Error(ctx, "Failed to merge MaxMind edition",
+				append(logging.NewError(err), logging.NewKeyVal("edition", e.edition), logging.NewKeyVal("ip", ip))...)
+		}
+	}
+}
+
+func (r *editionRegistry) Close() {
+	if r.watcher != nil {
+		r.watcher.Close()
+	}
+	if r.done != nil {
+		close(r.done)
+	}
+	for _, e := range r.editions {
+		e.close()
+	}
+}
+
+// Epochs describes the loaded editions by their build epochs, e.g.
+// "+asn@1744012800+connection_type@1744012800", or returns empty when none is
+// loaded.
+func (r *editionRegistry) Epochs() string {
+	if r == nil {
+		return ""
+	}
+
+	var epochs strings.Builder
+	for _, e := range r.editions {
+		if epoch := e.epoch(); epoch > 0 {
+			fmt.Fprintf(&epochs, "+%s@%d", e.edition, epoch)
+		}
+	}
+	return epochs.String()
+}
+
+// Owns reports whether file is the database of one of the editions.
+func (r *editionRegistry) Owns(file string) bool {
+	if r == nil {
+		return false
+	}
+	return slices.ContainsFunc(r.editions, func(e *editionReader) bool {
+		return e.file == file
+	})
+}
+
+// watch reloads an edition whenever its file in dbFolder is written, created
+// or renamed into place.
+func (r *editionRegistry) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+
+	if err = watcher.Add(dbFolder); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch database folder: %w", err)
+	}
+
+	r.watcher = watcher
+	r.done = make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				for _, e := range r.editions {
+					if filepath.Base(event.Name) != e.file {
+						continue
+					}
+					r.logger.Info(context.Background(), "MaxMind edition changed. Reloading...", logging.NewKeyVal("edition", e.edition))
+					if err := e.load(); err != nil {
+						r.logger.Error(context.Background(), "Failed to reload MaxMind edition",
+							append(logging.NewError(err), logging.NewKeyVal("edition", e.edition))...)
+						continue
+					}
+					r.logger.Info(context.Background(), "MaxMind edition reloaded", logging.NewKeyVal("edition", e.edition))
+					if r.onReload != nil {
+						r.onReload(e.edition)
+					}
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				r.logger.Error(context.Background(), "Watcher error", logging.NewError(err)...)
+			case <-r.done:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -2,112 +2,255 @@ package maxmind
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/overrides"
 	"geolize/utilities/logging"
+	"net/http"
+	"sync/atomic"
 )
 
+// Name identifies the MaxMind provider in IPResult.Provider.
+const Name = "maxmind"
+
 type Maxmind struct {
-	logger logging.Logger
-	reader *Reader
-	writer *Writer
+	logger   logging.Logger
+	reader   *Reader
+	writer   atomic.Pointer[Writer]
+	history  *versionHistoryManager
+	editions *editionRegistry
+	cache    *lookupCache
+	base     *baseWatcher
 }
 
+// Lookup resolves every IP in the batch independently. A bad or unknown IP
+// never fails the batch; its outcome is reported in IPResult.Status instead.
 func (m *Maxmind) Lookup(ctx context.Context, request *model.IPLookupRequest) ([]*model.IPResult, error) {
-	var result []*model.IPResult
+	result := make([]*model.IPResult, 0, len(request.IPs))
 	for _, ip := range request.IPs {
-		record, err := m.reader.Lookup(ip)
-		if err != nil {
-			m.logger.Error(ctx, "reader.Lookup", logging.NewError(err)...)
-			return nil, err
-		}
-
-		result = append(result, &model.IPResult{
-			IP:        ip,
-			DBVersion: m.reader.Version(),
-			Country: &model.Country{
-				ISOCode:           record.Country.IsoCode,
-				Names:             record.Country.Names,
-				IsInEuropeanUnion: record.Country.IsInEuropeanUnion,
-			},
-			City: &model.City{
-				Names: record.City.Names,
-			},
-			Location: &model.Location{
-				Latitude:       record.Location.Latitude,
-				Longitude:      record.Location.Longitude,
-				AccuracyRadius: record.Location.AccuracyRadius,
-				TimeZone:       record.Location.TimeZone,
-			},
-			Postal: &model.Postal{
-				Code: record.Postal.Code,
-			},
-			Continent: &model.Continent{
-				Code:  record.Continent.Code,
-				Names: record.Continent.Names,
-			},
-			Subdivisions: func() []*model.Subdivision {
-				var subdivisions []*model.Subdivision
-				for _, subdivision := range record.Subdivisions {
-					subdivisions = append(subdivisions, &model.Subdivision{
-						ISOCode: subdivision.IsoCode,
-						Names:   subdivision.Names,
-					})
-				}
-				return subdivisions
-			}(),
-			RepresentedCountry: &model.RepresentedCountry{
-				ISOCode:           record.RepresentedCountry.IsoCode,
-				Names:             record.RepresentedCountry.Names,
-				Type:              record.RepresentedCountry.Type,
-				IsInEuropeanUnion: record.RepresentedCountry.IsInEuropeanUnion,
-			},
-			RegisteredCountry: &model.RegisteredCountry{
-				ISOCode:           record.RegisteredCountry.IsoCode,
-				Names:             record.RegisteredCountry.Names,
-				IsInEuropeanUnion: record.RegisteredCountry.IsInEuropeanUnion,
-			},
-			Traits: &model.Traits{
-				IsAnonymousProxy:    record.Traits.IsAnonymousProxy,
-				IsSatelliteProvider: record.Traits.IsSatelliteProvider,
-				IsAnycast:           record.Traits.IsAnycast,
-			},
-		})
+		result = append(result, m.lookup(ctx, ip, request.Fields))
 	}
 	return result, nil
 }
 
-func (m *Maxmind) Update(ctx context.Context, request *model.IPUpdateRequest) error {
-	if m.writer == nil {
-		return fmt.Errorf("writer is not ready yet")
+func (m *Maxmind) lookup(ctx context.Context, ip string, fields []string) *model.IPResult {
+	database, err := m.reader.Acquire()
+	if err != nil {
+		m.logger.Error(ctx, "reader.Acquire", append(logging.NewError(err), logging.NewKeyVal("ip", ip))...)
+		return &model.IPResult{IP: ip, Status: model.LookupStatusInternal, Message: err.Error(), Provider: Name}
+	}
+	defer database.Release()
+
+	version := database.Version()
+
+	if result, ok := m.cache.Get(ip, version, fields); ok {
+		return result
+	}
+
+	result := m.decode(ctx, database, ip, fields)
+	if result.Status != model.LookupStatusInternal {
+		m.cache.Add(ip, version, fields, result)
+	}
+	return result
+}
+
+// decode reads ip from database, which the caller holds, so the record and
+// the reported version always match.
+func (m *Maxmind) decode(ctx context.Context, database *database, ip string, fields []string) *model.IPResult {
+	version := database.Version()
+
+	record, network, found, err := database.Lookup(ip, fields...)
+	switch {
+	case errors.Is(err, model.ErrInvalidIP):
+		return &model.IPResult{IP: ip, DBVersion: version, Status: model.LookupStatusInvalidIP, Message: err.Error(), Provider: Name}
+	case err != nil:
+		m.logger.Error(ctx, "reader.Lookup", append(logging.NewError(err), logging.NewKeyVal("ip", ip))...)
+		return &model.IPResult{IP: ip, DBVersion: version, Status: model.LookupStatusInternal, Message: err.Error(), Provider: Name}
+	case !found:
+		result := &model.IPResult{IP: ip, DBVersion: version, Network: network.String(), Status: model.LookupStatusNotFound, Message: "no record found for IP", Provider: Name}
+		m.editions.Merge(ctx, ip, result, fields)
+		return result
+	}
+
+	result := record.Result(network)
+	result.IP = ip
+	result.DBVersion = version
+	result.Status = model.LookupStatusOK
+	result.Provider = Name
+	m.editions.Merge(ctx, ip, result, fields)
+
+	return result
+}
+
+// Version identifies the data lookups are currently served from: the
+// database version followed by the build epoch of every loaded edition, e.g.
+// "history__1744099200__rebase+asn@1744012800", so it also changes when an
+// edition is reloaded.
+func (m *Maxmind) Version() string {
+	return m.reader.Version() + m.editions.Epochs()
+}
+
+// Update queues the override of the request for the writer and returns the
+// version of the flush that wrote it.
+func (m *Maxmind) Update(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	writer := m.writer.Load()
+	if writer == nil {
+		return "", fmt.Errorf("writer is not ready yet")
 	}
-	err := m.writer.Update(ctx, request)
+	version, err := writer.Update(ctx, request)
 	if err != nil {
 		m.logger.Error(ctx, "writer.Update", logging.NewError(err)...)
+		return "", err
+	}
+	m.cache.Purge()
+	return version, nil
+}
+
+// BatchUpdate writes every override of the request into the database with a
+// single rebuild and returns the version it produces.
+func (m *Maxmind) BatchUpdate(ctx context.Context, request *model.IPBatchUpdateRequest) (string, error) {
+	writer := m.writer.Load()
+	if writer == nil {
+		return "", fmt.Errorf("writer is not ready yet")
+	}
+	version, err := writer.BatchUpdate(ctx, request)
+	if err != nil {
+		m.logger.Error(ctx, "writer.BatchUpdate", logging.NewError(err)...)
+		return "", err
+	}
+	m.cache.Purge()
+	return version, nil
+}
+
+// Check reports whether Update would accept the override of the request,
+// without writing it.
+func (m *Maxmind) Check(request *model.IPUpdateRequest) error {
+	writer := m.writer.Load()
+	if writer == nil {
+		return fmt.Errorf("writer is not ready yet")
+	}
+	return writer.Check(request)
+}
+
+// Checkpoint returns the version the database is built up to, which Rollback
+// takes it back to. It is empty before the first change.
+func (m *Maxmind) Checkpoint() (string, error) {
+	return m.history.GetVersion()
+}
+
+// Remove drops the overrides made for the request's IP or network and
+// restores its record from the pristine base database.
+func (m *Maxmind) Remove(ctx context.Context, request *model.IPRemoveRequest) error {
+	writer := m.writer.Load()
+	if writer == nil {
+		return fmt.Errorf("writer is not ready yet")
+	}
+	err := writer.Remove(ctx, request)
+	if err != nil {
+		m.logger.Error(ctx, "writer.Remove", logging.NewError(err)...)
 		return err
 	}
+	m.cache.Purge()
 	return nil
 }
 
+// Rollback rolls the database back to the request's version and returns the
+// version of the rollback.
+func (m *Maxmind) Rollback(ctx context.Context, request *model.RollbackRequest) (string, error) {
+	writer := m.writer.Load()
+	if writer == nil {
+		return "", fmt.Errorf("writer is not ready yet")
+	}
+	version, err := writer.Rollback(ctx, request)
+	if err != nil {
+		m.logger.Error(ctx, "writer.Rollback", logging.NewError(err)...)
+		return "", err
+	}
+	m.cache.Purge()
+	return version, nil
+}
+
+// ListOverrides lists the overrides recorded in the override store. They are
+// available before the writer is ready.
+func (m *Maxmind) ListOverrides(ctx context.Context, request *model.IPOverrideListRequest) (*model.IPOverrideList, error) {
+	changes, err := m.history.Overrides(ctx)
+	if err != nil {
+		m.logger.Error(ctx, "history.Overrides", logging.NewError(err)...)
+		return nil, err
+	}
+	return overrides.List(changes, request)
+}
+
+// OverrideHistory returns the change log recorded in the override store.
+func (m *Maxmind) OverrideHistory(ctx context.Context, request *model.IPOverrideHistoryRequest) ([]*model.IPOverrideChange, error) {
+	changes, err := m.history.Audits(ctx)
+	if err != nil {
+		m.logger.Error(ctx, "history.Audits", logging.NewError(err)...)
+		return nil, err
+	}
+	return overrides.History(changes, request)
+}
+
 func New(logger logging.Logger) *Maxmind {
 	m := &Maxmind{
 		logger: logger,
 	}
 
-	reader, err := NewReader(logger)
+	store, err := openOverrideStore(storePath)
+	if err != nil {
+		panic(err)
+	}
+	warnLegacyHistories(logger, store)
+
+	history := newVersionHistoryManager(store)
+	m.history = history
+
+	m.cache = newLookupCache(cacheSize, cacheTTL)
+	go m.cache.reportStats(logger, cacheStatsInterval)
+
+	reader, err := NewReader(logger, history, func(version string) {
+		m.cache.Purge()
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	m.reader = reader
 
+	editions, err := newEditionRegistry(logger, map[Edition]string{
+		EditionASN:            asnDB,
+		EditionAnonymousIP:    anonymousIPDB,
+		EditionConnectionType: connectionTypeDB,
+		EditionCountry:        countryDB,
+	}, func(edition Edition) {
+		m.cache.Purge()
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	m.editions = editions
+
 	go func() {
-		writer, err := NewWriter(logger)
+		writer, err := NewWriter(logger, history)
 		if err != nil {
 			panic(err)
 		}
 
-		m.writer = writer
+		m.writer.Store(writer)
+
+		// New base databases can only be rebuilt once the writer is ready.
+		base, err := newBaseWatcher(logger, editions.Owns, m.onBaseDropped, m.onActiveChanged)
+		if err != nil {
+			logger.Error(context.Background(), "Failed to watch for new base databases", logging.NewError(err)...)
+		}
+		m.base = base
+
+		if updateInterval > 0 {
+			updater := newUpdater(logger, http.DefaultClient, downloadURL, checksumURL, m.installBase)
+			go updater.Run(context.Background(), updateInterval)
+		}
 	}()
 
 	logger.Info(context.Background(), "Maxmind geolocation provider initialized")
@@ -0,0 +1,163 @@
+package maxmind
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"geolize/utilities/logging"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// legacyHistories lists the JSON history files written before the override
+// store existed, in the order they were applied.
+func legacyHistories() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dbHistories, "history*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// warnLegacyHistories points at the migration when JSON history files are
+// left over next to an empty override store.
+func warnLegacyHistories(logger logging.Logger, store *overrideStore) {
+	files, err := legacyHistories()
+	if err != nil || len(files) == 0 {
+		return
+	}
+
+	changes, err := store.Changes(context.Background(), 0)
+	if err != nil || len(changes) > 0 {
+		return
+	}
+
+	logger.Warn(context.Background(), "Found JSON history files that are not in the override store, run `geolize db migrate` to import them",
+		logging.NewKeyVal("files", len(files)))
+}
+
+// MigrateHistories imports the JSON history files of data/histories into the
+// override store. Each file keeps its name as the version it produces, so the
+// version of the active database stays valid. Files that were already
+// imported are skipped, which makes the migration safe to run again. When base
+// is set, it is kept in dbBaseFolder as the original database the histories
+// were applied to, which makes their overrides removable. It returns the
+// number of imported files.
+func MigrateHistories(ctx context.Context, logger logging.Logger, base string) (int, error) {
+	store, err := openOverrideStore(storePath)
+	if err != nil {
+		return 0, err
+	}
+	defer store.Close()
+
+	files, err := legacyHistories()
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		name := filepath.Base(file)
+		names = append(names, name)
+
+		exists, err := store.Exists(ctx, name)
+		if err != nil {
+			return imported, err
+		}
+		if exists {
+			logger.Debug(ctx, "History file is already imported", logging.NewKeyVal("file", name))
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return imported, err
+		}
+
+		var history History
+		if err = json.Unmarshal(data, &history); err != nil {
+			return imported, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		c := &change{
+			Name:      name,
+			Kind:      changeKindOverride,
+			Network:   history.Network,
+			Base:      history.Base,
+			Overrides: history.Overrides,
+		}
+		if len(history.Base) > 0 {
+			c.Kind = changeKindRebase
+		}
+		if info, err := os.Stat(file); err == nil {
+			c.CreatedAt = info.ModTime()
+		}
+
+		if _, err = store.Append(ctx, c); err != nil {
+			return imported, fmt.Errorf("failed to import %s: %w", name, err)
+		}
+
+		logger.Info(ctx, "History file imported", logging.NewKeyVal("file", name))
+		imported++
+	}
+
+	if len(names) == 0 {
+		return imported, nil
+	}
+
+	// The active database was built up to the version file when it names an
+	// imported entry, and up to the latest entry otherwise since every history
+	// file was applied on startup.
+	version := names[len(names)-1]
+	if data, err := os.ReadFile(versionFilePath); err == nil {
+		if current := strings.TrimSpace(string(data)); len(current) > 0 {
+			if exists, err := store.Exists(ctx, current); err == nil && exists {
+				version = current
+			}
+		}
+	}
+
+	if _, err = store.Checkpoint(ctx, version); err != nil {
+		return imported, err
+	}
+
+	if len(base) > 0 {
+		if err = keepMigratedBase(ctx, logger, store, base); err != nil {
+			return imported, fmt.Errorf("failed to keep base database: %w", err)
+		}
+	}
+
+	return imported, nil
+}
+
+// keepMigratedBase copies the database at path into dbBaseFolder under the
+// name removals rebuild from: the base of the latest rebase, or the configured
+// database. A base that is already there is kept.
+func keepMigratedBase(ctx context.Context, logger logging.Logger, store *overrideStore, path string) error {
+	name, err := store.Base(ctx)
+	if err != nil {
+		return err
+	}
+	if len(name) == 0 {
+		name = db
+	}
+
+	dst := filepath.Join(dbBaseFolder, name)
+	if _, err = os.Stat(dst); err == nil {
+		logger.Info(ctx, "Base database is already kept", logging.NewKeyVal("path", dst))
+		return nil
+	}
+
+	if err = keepBase(path, dst); err != nil {
+		return err
+	}
+
+	logger.Info(ctx, "Base database kept", logging.NewKeyVal("path", dst))
+	return nil
+}
@@ -0,0 +1,95 @@
+package maxmind
+
+import (
+	"context"
+	"encoding/json"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeLegacyHistory writes a JSON history file of overrides, as the service
+// did before the override store.
+func writeLegacyHistory(t *testing.T, name string, overrides ...*model.IPUpdateRequest) {
+	t.Helper()
+
+	data, err := json.Marshal(&History{ID: name, Name: name, Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(dbHistories, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dbHistories, name), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateHistoriesKeepsBase(t *testing.T) {
+	dir := chdirTemp(t)
+	ctx := context.Background()
+
+	// The active database already holds the override of the history file.
+	writeTestDatabase(t, filepath.Join(dbFolder, db), map[string]string{"81.2.69.0/24": "VN", "89.160.20.0/24": "SE"})
+	writeLegacyHistory(t, "history__1700000000.json", countryOverride("81.2.69.0/24", "VN"))
+	if err := os.WriteFile(versionFilePath, []byte("history__1700000000.json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	original := filepath.Join(dir, "original.mmdb")
+	writeTestDatabase(t, original, testRecords)
+
+	imported, err := MigrateHistories(ctx, testLogger(t), original)
+	if err != nil {
+		t.Fatalf("MigrateHistories() error = %v", err)
+	}
+	if imported != 1 {
+		t.Fatalf("MigrateHistories() = %d, want 1", imported)
+	}
+	if _, err = os.Stat(filepath.Join(dbBaseFolder, db)); err != nil {
+		t.Fatalf("base database not kept: %v", err)
+	}
+
+	w, _ := startTestWriter(t)
+	if err = w.Remove(ctx, &model.IPRemoveRequest{Network: "81.2.69.0/24"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "GB" {
+		t.Fatalf("country after Remove() = %q, want GB", got)
+	}
+}
+
+func TestMigrateHistoriesImportsOnce(t *testing.T) {
+	chdirTemp(t)
+	ctx := context.Background()
+	logger := testLogger(t)
+
+	writeLegacyHistory(t, "history__1700000000.json", countryOverride("81.2.69.0/24", "VN"))
+	writeLegacyHistory(t, "history__1700000100.json", countryOverride("89.160.20.0/24", "FR"))
+	// The database was only built up to the first file.
+	if err := os.WriteFile(versionFilePath, []byte("history__1700000000.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for want := 2; want >= 0; want -= 2 {
+		imported, err := MigrateHistories(ctx, logger, "")
+		if err != nil {
+			t.Fatalf("MigrateHistories() error = %v", err)
+		}
+		if imported != want {
+			t.Fatalf("MigrateHistories() = %d, want %d", imported, want)
+		}
+	}
+
+	history := openTestHistory(t)
+	if version, _ := history.GetVersion(); version != "history__1700000000.json" {
+		t.Fatalf("GetVersion() = %q, want the version of the version file", version)
+	}
+	changes, err := history.GetUpdatesFromVersion(ctx)
+	if err != nil {
+		t.Fatalf("GetUpdatesFromVersion() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].Name != "history__1700000100.json" {
+		t.Fatalf("GetUpdatesFromVersion() = %+v, want the second file left to apply", changes)
+	}
+}
@@ -0,0 +1,42 @@
+package maxmind
+
+import (
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"net"
+	"strings"
+)
+
+// overrideNetwork resolves the network an override applies to, see
+// ipaddr.OverrideNetwork, in the form the tree takes.
+func overrideNetwork(override *model.IPUpdateRequest) (*net.IPNet, error) {
+	prefix, err := ipaddr.OverrideNetwork(override)
+	if err != nil {
+		return nil, err
+	}
+
+	return &net.IPNet{
+		IP:   net.IP(prefix.Addr().AsSlice()),
+		Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+	}, nil
+}
+
+// normalizeOverride validates the target of an override and records its
+// canonical network, so history files always carry the network that was
+// written into the tree (e.g. 203.0.113.7/24 becomes 203.0.113.0/24).
+func normalizeOverride(override *model.IPUpdateRequest) error {
+	network, err := overrideNetwork(override)
+	if err != nil {
+		return err
+	}
+
+	override.Network = network.String()
+	return nil
+}
+
+// historyName turns a network into a string that is safe to use in a file
+// name on every platform: "/" separates the prefix length and ":" is not
+// allowed on Windows or macOS HFS.
+func historyName(network string) string {
+	return strings.NewReplacer("/", "_", ":", "-").Replace(network)
+}
@@ -0,0 +1,158 @@
+package maxmind
+
+import (
+	"context"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/conf"
+	"geolize/utilities/logging"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// writeWindow is how long the writer waits after an update for more to
+	// arrive before it flushes them together. Zero flushes right away, still
+	// coalescing the updates that queued up during the previous flush.
+	writeWindow, _        = conf.GetDuration("geolize", "write_window", 50*time.Millisecond)
+	writeMaxBatch, _      = conf.GetInt32("geolize", "write_max_batch", 500)
+	writeStatsInterval, _ = conf.GetDuration("geolize", "write_stats_interval", time.Minute)
+)
+
+// pendingUpdate is an update waiting in the write queue. done receives the
+// outcome of the flush that handled it.
+type pendingUpdate struct {
+	ctx     context.Context
+	request *model.IPUpdateRequest
+	done    chan updateResult
+}
+
+type updateResult struct {
+	version string
+	err     error
+}
+
+// writeQueue hands updates to a single goroutine that flushes them in
+// batches: the first update opens a window of writeWindow, and every update
+// that arrives within it, up to maxBatch, is flushed with it.
+type writeQueue struct {
+	window   time.Duration
+	maxBatch int
+	flush    func(updates []*pendingUpdate)
+	updates  chan *pendingUpdate
+
+	depth     atomic.Int64
+	flushes   atomic.Uint64
+	flushed   atomic.Uint64
+	latency   atomic.Int64
+	lastFlush atomic.Int64
+}
+
+func newWriteQueue(window time.Duration, maxBatch int32, flush func(updates []*pendingUpdate)) *writeQueue {
+	if maxBatch <= 0 {
+		maxBatch = 1
+	}
+
+	return &writeQueue{
+		window:   window,
+		maxBatch: int(maxBatch),
+		flush:    flush,
+		updates:  make(chan *pendingUpdate, maxBatch),
+	}
+}
+
+// Submit queues request and waits for the flush that handles it. It returns
+// the version that contains the change. Once queued, the update is flushed
+// even when ctx is canceled, so the caller always learns its outcome.
+func (q *writeQueue) Submit(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	update := &pendingUpdate{
+		ctx:     context.WithoutCancel(ctx),
+		request: request,
+		done:    make(chan updateResult, 1),
+	}
+
+	q.depth.Add(1)
+	select {
+	case q.updates <- update:
+	case <-ctx.Done():
+		q.depth.Add(-1)
+		return "", ctx.Err()
+	}
+
+	result := <-update.done
+	return result.version, result.err
+}
+
+// run flushes queued updates until the process exits.
+func (q *writeQueue) run() {
+	for first := range q.updates {
+		batch := q.collect(first)
+
+		start := time.Now()
+		q.flush(batch)
+		elapsed := time.Since(start)
+
+		q.depth.Add(-int64(len(batch)))
+		q.flushes.Add(1)
+		q.flushed.Add(uint64(len(batch)))
+		q.latency.Add(int64(elapsed))
+		q.lastFlush.Store(int64(elapsed))
+	}
+}
+
+// collect gathers the updates that arrive within the window opened by first.
+func (q *writeQueue) collect(first *pendingUpdate) []*pendingUpdate {
+	batch := []*pendingUpdate{first}
+
+	timer := time.NewTimer(q.window)
+	defer timer.Stop()
+
+	for len(batch) < q.maxBatch {
+		select {
+		case update := <-q.updates:
+			batch = append(batch, update)
+		case <-timer.C:
+			// Take what queued up in the meantime without waiting any longer.
+			for len(batch) < q.maxBatch {
+				select {
+				case update := <-q.updates:
+					batch = append(batch, update)
+				default:
+					return batch
+				}
+			}
+			return batch
+		}
+	}
+
+	return batch
+}
+
+// Stats returns the number of updates waiting or being flushed, the number of
+// flushes and updates flushed so far, and the average and last flush latency.
+func (q *writeQueue) Stats() (depth int64, flushes, flushed uint64, average, last time.Duration) {
+	depth, flushes, flushed = q.depth.Load(), q.flushes.Load(), q.flushed.Load()
+	if flushes > 0 {
+		average = time.Duration(q.latency.Load() / int64(flushes))
+	}
+	return depth, flushes, flushed, average, time.Duration(q.lastFlush.Load())
+}
+
+// reportStats logs the queue depth and flush latency every interval.
+func (q *writeQueue) reportStats(logger logging.Logger, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		depth, flushes, flushed, average, last := q.Stats()
+		logger.Info(context.Background(), "Write queue stats",
+			logging.NewKeyVal("depth", depth),
+			logging.NewKeyVal("flushes", flushes),
+			logging.NewKeyVal("updates_flushed", flushed),
+			logging.NewKeyVal("avg_flush_latency", average.String()),
+			logging.NewKeyVal("last_flush_latency", last.String()))
+	}
+}
@@ -0,0 +1,162 @@
+package maxmind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingFlush answers every update with the number of its flush and
+// records the size of each flush.
+type recordingFlush struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (f *recordingFlush) flush(updates []*pendingUpdate) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batches = append(f.batches, len(updates))
+	for _, update := range updates {
+		update.done <- updateResult{version: fmt.Sprintf("flush-%d", len(f.batches))}
+	}
+}
+
+func (f *recordingFlush) sizes() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]int(nil), f.batches...)
+}
+
+// submitAll submits n updates at once and returns their versions.
+func submitAll(t *testing.T, q *writeQueue, n int) []string {
+	t.Helper()
+
+	versions := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			version, err := q.Submit(context.Background(), &model.IPUpdateRequest{IP: "81.2.69.142"})
+			if err != nil {
+				t.Errorf("Submit() error = %v", err)
+			}
+			versions[i] = version
+		}()
+	}
+	wg.Wait()
+
+	return versions
+}
+
+func TestWriteQueueCoalescesUpdatesWithinWindow(t *testing.T) {
+	f := &recordingFlush{}
+	q := newWriteQueue(200*time.Millisecond, 100, f.flush)
+	go q.run()
+
+	versions := submitAll(t, q, 5)
+	if sizes := f.sizes(); len(sizes) != 1 || sizes[0] != 5 {
+		t.Fatalf("flushes = %v, want one flush of 5 updates", sizes)
+	}
+	for _, version := range versions {
+		if version != "flush-1" {
+			t.Fatalf("versions = %q, want every update in the first flush", versions)
+		}
+	}
+
+	depth, flushes, flushed, _, _ := q.Stats()
+	if depth != 0 || flushes != 1 || flushed != 5 {
+		t.Fatalf("Stats() = %d, %d, %d, want 0, 1, 5", depth, flushes, flushed)
+	}
+}
+
+func TestWriteQueueCapsBatches(t *testing.T) {
+	f := &recordingFlush{}
+	q := newWriteQueue(200*time.Millisecond, 2, f.flush)
+	go q.run()
+
+	submitAll(t, q, 5)
+
+	total := 0
+	for _, size := range f.sizes() {
+		if size > 2 {
+			t.Fatalf("flushes = %v, want at most 2 updates each", f.sizes())
+		}
+		total += size
+	}
+	if total != 5 {
+		t.Fatalf("flushes = %v, want all 5 updates flushed", f.sizes())
+	}
+}
+
+func TestWriteQueueSubmitCanceledWhileFull(t *testing.T) {
+	q := newWriteQueue(time.Millisecond, 1, (&recordingFlush{}).flush)
+	// Nothing flushes yet, so one update fills the queue.
+	q.updates <- &pendingUpdate{done: make(chan updateResult, 1)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Submit(ctx, &model.IPUpdateRequest{IP: "81.2.69.142"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if depth, _, _, _, _ := q.Stats(); depth != 0 {
+		t.Fatalf("depth = %d, want the canceled update not counted", depth)
+	}
+}
+
+func TestWriterFlushesQueuedUpdatesTogether(t *testing.T) {
+	window := writeWindow
+	writeWindow = 200 * time.Millisecond
+	t.Cleanup(func() { writeWindow = window })
+
+	w, history := testWriter(t)
+	ctx := context.Background()
+
+	requests := []*model.IPUpdateRequest{
+		countryOverride("81.2.69.0/24", "VN"),
+		countryOverride("not a network", "VN"),
+		countryOverride("89.160.20.0/24", "FR"),
+	}
+	versions := make([]string, len(requests))
+	errs := make([]error, len(requests))
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			versions[i], errs[i] = w.Update(ctx, request)
+		}()
+	}
+	wg.Wait()
+
+	if !errors.Is(errs[1], model.ErrInvalidIP) {
+		t.Fatalf("Update() of an invalid target error = %v, want %v", errs[1], model.ErrInvalidIP)
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("Update() errors = %v, want the valid updates written", errs)
+	}
+	if versions[0] != versions[2] {
+		t.Fatalf("versions = %q, want one version for the flush", versions)
+	}
+	if got := countryOf(t, "81.2.69.1") + countryOf(t, "89.160.20.1"); got != "VNFR" {
+		t.Fatalf("countries = %q, want VN and FR", got)
+	}
+
+	changes, err := history.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("GetAll() = %+v, want each valid update recorded as its own change", changes)
+	}
+	if current, _ := history.GetVersion(); current != versions[0] || changes[1].Name != current {
+		t.Fatalf("GetVersion() = %q, want the last change of the flush %q", current, changes[1].Name)
+	}
+}
@@ -2,31 +2,35 @@ package maxmind
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"geolize/utilities/conf"
 	"geolize/utilities/logging"
-	"io"
 	"net"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/oschwald/geoip2-golang"
 )
 
+// Reader serves lookups from the current database and hot swaps it when the
+// version changes. The database and its version are published together, so a
+// lookup always sees a consistent pair, and a replaced database stays open
+// until the lookups still using it have finished.
 type Reader struct {
-	reader  *geoip2.Reader
-	version string
-	logger  logging.Logger
-	watcher *fsnotify.Watcher
-	history *versionHistoryManager
-	done    chan struct{}
+	current  atomic.Pointer[database]
+	reloadMu sync.Mutex
+	logger   logging.Logger
+	watcher  *fsnotify.Watcher
+	history  *versionHistoryManager
+	done     chan struct{}
+
+	// onReload is set before the watcher starts and never changes.
+	onReload func(version string)
 }
 
-func newReader(logger logging.Logger, db string) (*Reader, error) {
+func newReader(logger logging.Logger, db string, vhm *versionHistoryManager, onReload func(version string)) (*Reader, error) {
 	// Check if the MaxMind database file exists
 	if _, err := os.Stat(filepath.Join(dbFolder, db)); os.IsNotExist(err) {
 		err := fmt.Errorf("maxmind database file does not exist: %s", db)
@@ -34,30 +38,28 @@ func newReader(logger logging.Logger, db string) (*Reader, error) {
 		return nil, err
 	}
 
-	gReader, err := geoip2.Open(filepath.Join(dbFolder, db))
+	version, err := vhm.GetVersion()
 	if err != nil {
+		logger.Fatal(context.Background(), "Failed to get version", logging.NewError(err)...)
 		return nil, err
 	}
 
-	vhm := newVersionHistoryManager()
-	version, err := vhm.GetVersion()
+	// The version file is what the watcher listens to, so it has to exist.
+	if err = vhm.SetVersion(version); err != nil {
+		return nil, err
+	}
+
+	database, err := openDatabase(filepath.Join(dbFolder, db), version)
 	if err != nil {
-		if os.IsNotExist(err) {
-			if err = vhm.SetVersion(""); err != nil {
-				return nil, err
-			}
-		} else {
-			logger.Fatal(context.Background(), "Failed to get version file", logging.NewError(err)...)
-			return nil, err
-		}
+		return nil, err
 	}
 
 	reader := &Reader{
-		reader:  gReader,
-		version: version,
-		history: vhm,
-		logger:  logger,
+		history:  vhm,
+		logger:   logger,
+		onReload: onReload,
 	}
+	reader.current.Store(database)
 
 	// Start watching for version changes
 	if err = reader.watch(); err != nil {
@@ -75,64 +77,90 @@ func (r *Reader) Close() {
 	if r.done != nil {
 		close(r.done)
 	}
-	if r.reader != nil {
-		r.reader.Close()
+	if database := r.current.Swap(nil); database != nil {
+		database.Release()
 	}
 }
 
-func (r *Reader) Lookup(ip string) (*geoip2.City, error) {
-	ip = strings.TrimSpace(ip)
-	return r.reader.City(net.ParseIP(ip))
+// Acquire returns the current database with a reference held on it. The
+// caller must Release it when done; until then it stays open even if a newer
+// version is swapped in.
+func (r *Reader) Acquire() (*database, error) {
+	for {
+		database := r.current.Load()
+		if database == nil {
+			return nil, errors.New("reader is closed")
+		}
+		if database.acquire() {
+			return database, nil
+		}
+	}
+}
+
+// Lookup is a single lookup against the current database. Use Acquire when
+// the version of the answer matters.
+func (r *Reader) Lookup(ip string, sections ...string) (record *CityRecord, network *net.IPNet, found bool, err error) {
+	database, err := r.Acquire()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer database.Release()
+
+	return database.Lookup(ip, sections...)
 }
 
 func (r *Reader) reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
 	newVersion, err := r.history.GetVersion()
 	if err != nil {
 		return err
 	}
 
-	if newVersion == r.version {
+	if newVersion == r.Version() {
 		return nil // No version change
 	}
 
-	if r.version == newVersion {
-		return nil
-	}
-
-	// Create a copy of the current reader
-	oldReader := r.reader
-
 	// Open new database file
 	dbPath := filepath.Join(dbFolder, db)
-	newReader, err := geoip2.Open(dbPath)
+	newDatabase, err := openDatabase(dbPath, newVersion)
 	if err != nil {
 		return fmt.Errorf("failed to open new database: %w", err)
 	}
 
-	// Update reader and version
-	r.reader = newReader
-	r.version = newVersion
+	// Publish the new database and version together. The old one is closed
+	// once the lookups still holding it have released it.
+	if oldDatabase := r.current.Swap(newDatabase); oldDatabase != nil {
+		oldDatabase.Release()
+	}
 
-	// Close the old reader after the new one is successfully loaded
-	if oldReader != nil {
-		defer oldReader.Close()
+	if r.onReload != nil {
+		r.onReload(newVersion)
 	}
 
 	return nil
 }
 
 func (r *Reader) Version() string {
-	return r.version
+	database := r.current.Load()
+	if database == nil {
+		return ""
+	}
+	return database.Version()
 }
 
-func NewReader(logger logging.Logger) (*Reader, error) {
+// NewReader opens the current database and watches the version file for
+// changes. onReload, which may be nil, is called after a new database version
+// has been loaded.
+func NewReader(logger logging.Logger, history *versionHistoryManager, onReload func(version string)) (*Reader, error) {
 	if logger == nil {
 		panic("Reader: logger is nil")
 	}
 
 	logger.Debug(context.Background(), "IPGeolite Reader is being initializing...")
 
-	reader, err := newReader(logger, db)
+	reader, err := newReader(logger, db, history, onReload)
 	if err != nil {
 		logger.Fatal(context.Background(), "Failed to create reader", logging.NewError(err)...)
 		return nil, err
@@ -170,14 +198,14 @@ func (r *Reader) watch() error {
 					if err = r.history.SetVersion(r.Version()); err != nil {
 						r.logger.Error(context.Background(), "Failed to create version file", logging.NewError(err)...)
 					}
-					r.logger.Info(context.Background(), "Version file recreated", logging.NewKeyVal("version", r.version))
+					r.logger.Info(context.Background(), "Version file recreated", logging.NewKeyVal("version", r.Version()))
 				}
 				if event.Has(fsnotify.Write) {
 					r.logger.Info(context.Background(), "Version file changed. Reloading database...")
 					if err = r.reload(); err != nil {
 						r.logger.Error(context.Background(), "Failed to reload database", logging.NewError(err)...)
 					}
-					r.logger.Info(context.Background(), "Database is up to date with new version", logging.NewKeyVal("version", r.version))
+					r.logger.Info(context.Background(), "Database is up to date with new version", logging.NewKeyVal("version", r.Version()))
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
@@ -192,89 +220,3 @@ func (r *Reader) watch() error {
 
 	return nil
 }
-
-// downloadMaxMindDB downloads and extracts the MaxMind database
-func downloadMaxMindDB(dbPath string, logger logging.Logger) error {
-	// Get license key and download URL from configuration
-	licenseKey, _ := conf.GetString("geolize", "maxmind_license_key", "")
-	if licenseKey == "" {
-		return fmt.Errorf("MaxMind license key not configured")
-	}
-
-	// Get download URL from config or use default
-	downloadURLTemplate, _ := conf.GetString("geolize", "maxmind_download_url",
-		"https://download.maxmind.com/app/geoip_download?edition_id=GeoLite2-City&license_key=%s&suffix=tar.gz")
-	downloadURL := fmt.Sprintf(downloadURLTemplate, licenseKey)
-
-	// Create a temporary directory for the download
-	tempDir, err := os.MkdirTemp("", "maxmind")
-	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	// Download the database
-	tarGzPath := filepath.Join(tempDir, "GeoLite2-City.tar.gz")
-
-	logger.Info(context.Background(), "Downloading MaxMind database...")
-	resp, err := http.Get(downloadURL)
-	if err != nil {
-		return fmt.Errorf("failed to download database: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download database, status code: %d", resp.StatusCode)
-	}
-
-	out, err := os.Create(tarGzPath)
-	if err != nil {
-		return fmt.Errorf("failed to create download file: %w", err)
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to save downloaded file: %w", err)
-	}
-
-	// Extract the database
-	logger.Info(context.Background(), "Extracting MaxMind database...")
-	cmd := exec.Command("tar", "-xzf", tarGzPath, "-C", tempDir)
-	if err = cmd.Run(); err != nil {
-		return fmt.Errorf("failed to extract database: %w", err)
-	}
-
-	// Find the extracted mmdb file
-	var mmdbPath string
-	err = filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if strings.HasSuffix(path, ".mmdb") {
-			mmdbPath = path
-			return filepath.SkipAll
-		}
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("failed to find extracted database: %w", err)
-	}
-
-	if mmdbPath == "" {
-		return fmt.Errorf("could not find .mmdb file in extracted archive")
-	}
-
-	// Copy the database to the destination
-	data, err := os.ReadFile(mmdbPath)
-	if err != nil {
-		return fmt.Errorf("failed to read extracted database: %w", err)
-	}
-
-	if err := os.WriteFile(dbPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write database to destination: %w", err)
-	}
-
-	logger.Info(context.Background(), "MaxMind database installed successfully")
-	return nil
-}
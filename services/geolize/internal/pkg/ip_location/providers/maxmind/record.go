@@ -0,0 +1,250 @@
+package maxmind
+
+import (
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CityRecord mirrors the full GeoIP2 City/Enterprise record schema. geoip2.City
+// drops confidences, population density and most traits, so the reader decodes
+// into this struct instead. Sections are pointers so that a section the record
+// does not have stays nil, and is told apart from one with zero values such
+// as a location at latitude 0, longitude 0.
+type CityRecord struct {
+	City *struct {
+		Names      map[string]string `maxminddb:"names"`
+		GeoNameID  uint              `maxminddb:"geoname_id"`
+		Confidence uint8             `maxminddb:"confidence"`
+	} `maxminddb:"city"`
+	Continent *struct {
+		Names     map[string]string `maxminddb:"names"`
+		Code      string            `maxminddb:"code"`
+		GeoNameID uint              `maxminddb:"geoname_id"`
+	} `maxminddb:"continent"`
+	Country *struct {
+		Names             map[string]string `maxminddb:"names"`
+		IsoCode           string            `maxminddb:"iso_code"`
+		GeoNameID         uint              `maxminddb:"geoname_id"`
+		Confidence        uint8             `maxminddb:"confidence"`
+		IsInEuropeanUnion bool              `maxminddb:"is_in_european_union"`
+	} `maxminddb:"country"`
+	Location *struct {
+		TimeZone          string  `maxminddb:"time_zone"`
+		Latitude          float64 `maxminddb:"latitude"`
+		Longitude         float64 `maxminddb:"longitude"`
+		MetroCode         uint    `maxminddb:"metro_code"`
+		PopulationDensity uint    `maxminddb:"population_density"`
+		AverageIncome     uint    `maxminddb:"average_income"`
+		AccuracyRadius    uint16  `maxminddb:"accuracy_radius"`
+	} `maxminddb:"location"`
+	Postal *struct {
+		Code       string `maxminddb:"code"`
+		Confidence uint8  `maxminddb:"confidence"`
+	} `maxminddb:"postal"`
+	RegisteredCountry *struct {
+		Names             map[string]string `maxminddb:"names"`
+		IsoCode           string            `maxminddb:"iso_code"`
+		GeoNameID         uint              `maxminddb:"geoname_id"`
+		Confidence        uint8             `maxminddb:"confidence"`
+		IsInEuropeanUnion bool              `maxminddb:"is_in_european_union"`
+	} `maxminddb:"registered_country"`
+	RepresentedCountry *struct {
+		Names             map[string]string `maxminddb:"names"`
+		IsoCode           string            `maxminddb:"iso_code"`
+		Type              string            `maxminddb:"type"`
+		GeoNameID         uint              `maxminddb:"geoname_id"`
+		IsInEuropeanUnion bool              `maxminddb:"is_in_european_union"`
+	} `maxminddb:"represented_country"`
+	Subdivisions []struct {
+		Names      map[string]string `maxminddb:"names"`
+		IsoCode    string            `maxminddb:"iso_code"`
+		GeoNameID  uint              `maxminddb:"geoname_id"`
+		Confidence uint8             `maxminddb:"confidence"`
+	} `maxminddb:"subdivisions"`
+	Traits *struct {
+		AutonomousSystemOrganization string  `maxminddb:"autonomous_system_organization"`
+		ConnectionType               string  `maxminddb:"connection_type"`
+		Domain                       string  `maxminddb:"domain"`
+		ISP                          string  `maxminddb:"isp"`
+		MobileCountryCode            string  `maxminddb:"mobile_country_code"`
+		MobileNetworkCode            string  `maxminddb:"mobile_network_code"`
+		Organization                 string  `maxminddb:"organization"`
+		UserType                     string  `maxminddb:"user_type"`
+		AutonomousSystemNumber       uint    `maxminddb:"autonomous_system_number"`
+		StaticIPScore                float64 `maxminddb:"static_ip_score"`
+		IsAnonymous                  bool    `maxminddb:"is_anonymous"`
+		IsAnonymousProxy             bool    `maxminddb:"is_anonymous_proxy"`
+		IsAnonymousVPN               bool    `maxminddb:"is_anonymous_vpn"`
+		IsAnycast                    bool    `maxminddb:"is_anycast"`
+		IsHostingProvider            bool    `maxminddb:"is_hosting_provider"`
+		IsLegitimateProxy            bool    `maxminddb:"is_legitimate_proxy"`
+		IsPublicProxy                bool    `maxminddb:"is_public_proxy"`
+		IsResidentialProxy           bool    `maxminddb:"is_residential_proxy"`
+		IsSatelliteProvider          bool    `maxminddb:"is_satellite_provider"`
+		IsTorExitNode                bool    `maxminddb:"is_tor_exit_node"`
+	} `maxminddb:"traits"`
+}
+
+// partialRecord is a struct type holding a subset of CityRecord's sections,
+// built with reflection so the decoder never allocates the sections (and
+// their names maps) a caller did not ask for.
+type partialRecord struct {
+	typ   reflect.Type
+	index []int // CityRecord field index for each field of typ
+}
+
+var (
+	cityRecordType = reflect.TypeOf(CityRecord{})
+	partialRecords sync.Map // sorted, comma-joined sections -> *partialRecord
+)
+
+func newPartialRecord(sections []string) *partialRecord {
+	key := strings.Join(sections, ",")
+	if cached, ok := partialRecords.Load(key); ok {
+		return cached.(*partialRecord)
+	}
+
+	partial := &partialRecord{}
+	var fields []reflect.StructField
+	for i := 0; i < cityRecordType.NumField(); i++ {
+		field := cityRecordType.Field(i)
+		if !slices.Contains(sections, field.Tag.Get("maxminddb")) {
+			continue
+		}
+		fields = append(fields, field)
+		partial.index = append(partial.index, i)
+	}
+	partial.typ = reflect.StructOf(fields)
+
+	cached, _ := partialRecords.LoadOrStore(key, partial)
+	return cached.(*partialRecord)
+}
+
+// decodeTarget returns what to decode into for record: record itself when all
+// sections are wanted, or a partial struct plus a func copying it back.
+func decodeTarget(record *CityRecord, sections []string) (target any, fill func()) {
+	if len(sections) == 0 {
+		return record, func() {}
+	}
+
+	sections = slices.Clone(sections)
+	slices.Sort(sections)
+
+	partial := newPartialRecord(sections)
+	value := reflect.New(partial.typ)
+	return value.Interface(), func() {
+		out := reflect.ValueOf(record).Elem()
+		for i, index := range partial.index {
+			out.Field(index).Set(value.Elem().Field(i))
+		}
+	}
+}
+
+// Result maps the record into the shared model. network is the network the
+// record was matched on; the caller fills in the lookup metadata. Sections
+// the record does not have are left nil.
+func (r *CityRecord) Result(network *net.IPNet) *model.IPResult {
+	result := &model.IPResult{
+		Found:   true,
+		Network: network.String(),
+		Traits:  &model.Traits{Network: network.String()},
+	}
+
+	if r.Country != nil {
+		result.Country = &model.Country{
+			ISOCode:           r.Country.IsoCode,
+			Names:             r.Country.Names,
+			IsInEuropeanUnion: r.Country.IsInEuropeanUnion,
+			GeoNameID:         r.Country.GeoNameID,
+			Confidence:        r.Country.Confidence,
+		}
+	}
+	if r.City != nil {
+		result.City = &model.City{
+			Names:      r.City.Names,
+			GeoNameID:  r.City.GeoNameID,
+			Confidence: r.City.Confidence,
+		}
+	}
+	if r.Location != nil {
+		result.Location = &model.Location{
+			Latitude:          r.Location.Latitude,
+			Longitude:         r.Location.Longitude,
+			AccuracyRadius:    r.Location.AccuracyRadius,
+			TimeZone:          r.Location.TimeZone,
+			MetroCode:         r.Location.MetroCode,
+			PopulationDensity: r.Location.PopulationDensity,
+			AverageIncome:     r.Location.AverageIncome,
+		}
+	}
+	if r.Postal != nil {
+		result.Postal = &model.Postal{
+			Code:       r.Postal.Code,
+			Confidence: r.Postal.Confidence,
+		}
+	}
+	if r.Continent != nil {
+		result.Continent = &model.Continent{
+			Code:      r.Continent.Code,
+			Names:     r.Continent.Names,
+			GeoNameID: r.Continent.GeoNameID,
+		}
+	}
+	for _, subdivision := range r.Subdivisions {
+		result.Subdivisions = append(result.Subdivisions, &model.Subdivision{
+			ISOCode:    subdivision.IsoCode,
+			Names:      subdivision.Names,
+			GeoNameID:  subdivision.GeoNameID,
+			Confidence: subdivision.Confidence,
+		})
+	}
+	if r.RepresentedCountry != nil {
+		result.RepresentedCountry = &model.RepresentedCountry{
+			ISOCode:           r.RepresentedCountry.IsoCode,
+			Names:             r.RepresentedCountry.Names,
+			Type:              r.RepresentedCountry.Type,
+			IsInEuropeanUnion: r.RepresentedCountry.IsInEuropeanUnion,
+			GeoNameID:         r.RepresentedCountry.GeoNameID,
+		}
+	}
+	if r.RegisteredCountry != nil {
+		result.RegisteredCountry = &model.RegisteredCountry{
+			ISOCode:           r.RegisteredCountry.IsoCode,
+			Names:             r.RegisteredCountry.Names,
+			IsInEuropeanUnion: r.RegisteredCountry.IsInEuropeanUnion,
+			GeoNameID:         r.RegisteredCountry.GeoNameID,
+			Confidence:        r.RegisteredCountry.Confidence,
+		}
+	}
+	if r.Traits != nil {
+		result.Traits = &model.Traits{
+			IsAnonymousProxy:             r.Traits.IsAnonymousProxy,
+			IsSatelliteProvider:          r.Traits.IsSatelliteProvider,
+			IsAnycast:                    r.Traits.IsAnycast,
+			IsAnonymous:                  r.Traits.IsAnonymous,
+			IsAnonymousVPN:               r.Traits.IsAnonymousVPN,
+			IsHostingProvider:            r.Traits.IsHostingProvider,
+			IsLegitimateProxy:            r.Traits.IsLegitimateProxy,
+			IsPublicProxy:                r.Traits.IsPublicProxy,
+			IsResidentialProxy:           r.Traits.IsResidentialProxy,
+			IsTorExitNode:                r.Traits.IsTorExitNode,
+			AutonomousSystemNumber:       r.Traits.AutonomousSystemNumber,
+			AutonomousSystemOrganization: r.Traits.AutonomousSystemOrganization,
+			ConnectionType:               r.Traits.ConnectionType,
+			Domain:                       r.Traits.Domain,
+			ISP:                          r.Traits.ISP,
+			MobileCountryCode:            r.Traits.MobileCountryCode,
+			MobileNetworkCode:            r.Traits.MobileNetworkCode,
+			Organization:                 r.Traits.Organization,
+			UserType:                     r.Traits.UserType,
+			StaticIPScore:                r.Traits.StaticIPScore,
+			Network:                      network.String(),
+		}
+	}
+
+	return result
+}
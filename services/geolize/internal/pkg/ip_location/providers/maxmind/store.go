@@ -0,0 +1,522 @@
+package maxmind
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/overrides"
+	"geolize/utilities/conf"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var storePath, _ = conf.GetString("geolize", "store", "data/geolize.db")
+
+const (
+	changeKindOverride = model.OverrideChangeKindOverride
+	changeKindRebase   = "rebase"
+	changeKindRemove   = model.OverrideChangeKindRemove
+	changeKindRollback = model.OverrideChangeKindRollback
+)
+
+// schema of the override store:
+//   - history is the change log, one entry per ModifyIP call, removal,
+//     rebase or rollback. Its name is the database version that the change
+//     produces. Entries undone by a rollback point to it with reverted_by
+//     and are no longer applied.
+//   - overrides holds the overrides of each change, in the order they apply.
+//     Removed overrides point to the removal with removed_by, so a rollback
+//     past the removal brings them back.
+//   - checkpoints records every version the active database was built up to;
+//     the latest one is the current version.
+//   - audit holds the record of each network a change touched, before and
+//     after the change. Entries outlive the overrides they describe.
+//
+// migrations are applied in order and PRAGMA user_version counts the ones a
+// store already has. Only ever append to it.
+var migrations = []string{`
+CREATE TABLE IF NOT EXISTS history (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT    NOT NULL UNIQUE,
+	kind       TEXT    NOT NULL,
+	network    TEXT    NOT NULL DEFAULT '',
+	base       TEXT    NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS overrides (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	history_id INTEGER NOT NULL REFERENCES history (id) ON DELETE CASCADE,
+	network    TEXT    NOT NULL,
+	payload    TEXT    NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS overrides_history_id ON overrides (history_id);
+CREATE INDEX IF NOT EXISTS overrides_network ON overrides (network);
+
+CREATE TABLE IF NOT EXISTS checkpoints (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	version    TEXT    NOT NULL,
+	created_at INTEGER NOT NULL
+);
+`, `
+ALTER TABLE history ADD COLUMN author TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN ticket TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE audit (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	history_id INTEGER NOT NULL REFERENCES history (id) ON DELETE CASCADE,
+	network    TEXT    NOT NULL,
+	before     TEXT    NOT NULL,
+	after      TEXT    NOT NULL
+);
+
+CREATE INDEX audit_history_id ON audit (history_id);
+`, `
+ALTER TABLE history ADD COLUMN reverted_by INTEGER REFERENCES history (id) ON DELETE SET NULL;
+ALTER TABLE overrides ADD COLUMN removed_by INTEGER REFERENCES history (id) ON DELETE SET NULL;
+`}
+
+// errChangeNotFound is returned when a version does not name a history entry.
+var errChangeNotFound = errors.New("change not found")
+
+// change is a history entry together with its overrides.
+type change struct {
+	ID        int64
+	Name      string
+	Kind      string
+	Network   string
+	Base      string
+	Author    string
+	Reason    string
+	Ticket    string
+	CreatedAt time.Time
+	Overrides []*model.IPUpdateRequest
+	// RemovedBy holds, for each override, the id of the change that removed
+	// it, or 0.
+	RemovedBy []int64
+	Audits    []*audit
+}
+
+// removedBy returns the id of the change that removed the i-th override, or 0.
+func (c *change) removedBy(i int) int64 {
+	if i < len(c.RemovedBy) {
+		return c.RemovedBy[i]
+	}
+	return 0
+}
+
+// audit is the record of a network before and after a change, as JSON.
+type audit struct {
+	Network string
+	Before  string
+	After   string
+}
+
+// overrideStore keeps the override history in SQLite. Every change is written
+// in a single transaction, so the history never holds half of a change.
+type overrideStore struct {
+	db *sql.DB
+}
+
+func openOverrideStore(path string) (*overrideStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open override store: %w", err)
+	}
+	// SQLite allows a single writer; one connection keeps writers queued
+	// instead of failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	if err = migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate override store: %w", err)
+	}
+
+	return &overrideStore{db: db}, nil
+}
+
+// migrate applies the migrations the store does not have yet, each in its
+// own transaction.
+func migrate(db *sql.DB) error {
+	var applied int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&applied); err != nil {
+		return err
+	}
+
+	for i := applied; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(migrations[i]); err == nil {
+			_, err = tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1))
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *overrideStore) Close() error {
+	return s.db.Close()
+}
+
+// Append records c and its overrides and returns its id.
+func (s *overrideStore) Append(ctx context.Context, c *change) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+
+	id, err := insertChange(ctx, tx, c)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, override := range c.Overrides {
+		payload, err := json.Marshal(override)
+		if err != nil {
+			return 0, err
+		}
+		if _, err = tx.ExecContext(ctx,
+			`INSERT INTO overrides (history_id, network, payload, created_at) VALUES (?, ?, ?, ?)`,
+			id, storedNetwork(override), string(payload), c.CreatedAt.Unix()); err != nil {
+			return 0, fmt.Errorf("failed to insert override: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	c.ID = id
+	return id, nil
+}
+
+// insertChange inserts the history entry of c and its audit entries.
+func insertChange(ctx context.Context, tx *sql.Tx, c *change) (int64, error) {
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO history (name, kind, network, base, author, reason, ticket, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.Name, c.Kind, c.Network, c.Base, c.Author, c.Reason, c.Ticket, c.CreatedAt.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert history: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, a := range c.Audits {
+		if _, err = tx.ExecContext(ctx,
+			`INSERT INTO audit (history_id, network, before, after) VALUES (?, ?, ?, ?)`,
+			id, a.Network, a.Before, a.After); err != nil {
+			return 0, fmt.Errorf("failed to insert audit: %w", err)
+		}
+	}
+
+	return id, nil
+}
+
+// storedNetwork is the canonical network of override, which is what removals
+// match on. Overrides recorded before targets were normalized may only carry
+// an IP.
+func storedNetwork(override *model.IPUpdateRequest) string {
+	network, err := overrideNetwork(override)
+	if err != nil {
+		return override.Network
+	}
+	return network.String()
+}
+
+// RemoveOverrides records c, the change that removes every override made for
+// network, and marks those overrides as removed by it, in the same
+// transaction. It returns model.ErrOverrideNotFound when there is no override
+// for network. The overrides are kept for the audit trail and for rollbacks.
+func (s *overrideStore) RemoveOverrides(ctx context.Context, network string, c *change) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	id, err := insertChange(ctx, tx, c)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE overrides SET removed_by = ? WHERE network = ?
+		AND history_id IN (SELECT id FROM history WHERE reverted_by IS NULL)
+		AND (removed_by IS NULL OR removed_by IN (SELECT id FROM history WHERE reverted_by IS NOT NULL))`, id, network)
+	if err != nil {
+		return fmt.Errorf("failed to remove overrides: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w for %s", model.ErrOverrideNotFound, network)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	c.ID = id
+	return nil
+}
+
+// Rollback records c, the rollback to the change named target, and marks every
+// change made after target as reverted by it, in one transaction.
+func (s *overrideStore) Rollback(ctx context.Context, target string, c *change) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var targetID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM history WHERE name = ? AND reverted_by IS NULL`, target).Scan(&targetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", model.ErrVersionNotFound, target)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	if c.ID, err = insertChange(ctx, tx, c); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		`UPDATE history SET reverted_by = ? WHERE id > ? AND id < ? AND reverted_by IS NULL`,
+		c.ID, targetID, c.ID); err != nil {
+		return fmt.Errorf("failed to revert history: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Remove deletes the change named name together with its overrides.
+func (s *overrideStore) Remove(ctx context.Context, name string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM history WHERE name = ?`, name)
+	return err
+}
+
+// Exists reports whether a change named name is recorded.
+func (s *overrideStore) Exists(ctx context.Context, name string) (bool, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM history WHERE name = ?`, name).Scan(&n)
+	return n > 0, err
+}
+
+// changeID returns the id of the change named name.
+func (s *overrideStore) changeID(ctx context.Context, name string) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM history WHERE name = ?`, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", errChangeNotFound, name)
+	}
+	return id, err
+}
+
+// Changes returns the changes with an id above after that were not reverted,
+// in the order they were made, with their overrides. Overrides keep the id of
+// the change that removed them unless that change was reverted.
+func (s *overrideStore) Changes(ctx context.Context, after int64) ([]*change, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, kind, network, base, created_at FROM history WHERE id > ? AND reverted_by IS NULL ORDER BY id`, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*change
+	byID := make(map[int64]*change)
+	for rows.Next() {
+		c := &change{}
+		var createdAt int64
+		if err = rows.Scan(&c.ID, &c.Name, &c.Kind, &c.Network, &c.Base, &createdAt); err != nil {
+			return nil, err
+		}
+		c.CreatedAt = time.Unix(createdAt, 0)
+		changes = append(changes, c)
+		byID[c.ID] = c
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.db.QueryContext(ctx,
+		`SELECT o.history_id, o.payload, CASE WHEN r.reverted_by IS NULL THEN COALESCE(o.removed_by, 0) ELSE 0 END
+		FROM overrides o LEFT JOIN history r ON r.id = o.removed_by
+		WHERE o.history_id > ? ORDER BY o.history_id, o.id`, after)
+	if err != nil {
+		return nil, err
+	}
+	defer overrides.Close()
+
+	for overrides.Next() {
+		var historyID, removedBy int64
+		var payload string
+		if err = overrides.Scan(&historyID, &payload, &removedBy); err != nil {
+			return nil, err
+		}
+		c, ok := byID[historyID]
+		if !ok {
+			continue
+		}
+		override := &model.IPUpdateRequest{}
+		if err = json.Unmarshal([]byte(payload), override); err != nil {
+			return nil, fmt.Errorf("failed to parse override of %s: %w", c.Name, err)
+		}
+		c.Overrides = append(c.Overrides, override)
+		c.RemovedBy = append(c.RemovedBy, removedBy)
+	}
+
+	return changes, overrides.Err()
+}
+
+// Base returns the base database file of the latest rebase that was not
+// reverted, or empty when there is none.
+func (s *overrideStore) Base(ctx context.Context) (string, error) {
+	var base string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT base FROM history WHERE kind = ? AND reverted_by IS NULL ORDER BY id DESC LIMIT 1`, changeKindRebase).Scan(&base)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return base, err
+}
+
+// Overrides returns every override that was neither reverted nor removed with
+// the change that introduced it, in the order they apply.
+func (s *overrideStore) Overrides(ctx context.Context) ([]overrides.Change, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT o.payload, o.created_at, h.name FROM overrides o JOIN history h ON h.id = o.history_id
+		LEFT JOIN history r ON r.id = o.removed_by
+		WHERE h.reverted_by IS NULL AND (o.removed_by IS NULL OR r.reverted_by IS NOT NULL)
+		ORDER BY o.history_id, o.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []overrides.Change
+	for rows.Next() {
+		var payload, name string
+		var createdAt int64
+		if err = rows.Scan(&payload, &createdAt, &name); err != nil {
+			return nil, err
+		}
+		override := &model.IPUpdateRequest{}
+		if err = json.Unmarshal([]byte(payload), override); err != nil {
+			return nil, fmt.Errorf("failed to parse override of %s: %w", name, err)
+		}
+		changes = append(changes, overrides.Change{
+			Override:  override,
+			Version:   name,
+			CreatedAt: time.Unix(createdAt, 0),
+		})
+	}
+
+	return changes, rows.Err()
+}
+
+// Audits returns the audit entries with the change they belong to, in the
+// order the changes were made.
+func (s *overrideStore) Audits(ctx context.Context) ([]*model.IPOverrideChange, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT h.name, h.kind, a.network, h.author, h.reason, h.ticket, h.created_at, h.reverted_by IS NOT NULL, a.before, a.after
+		FROM audit a JOIN history h ON h.id = a.history_id ORDER BY a.history_id, a.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*model.IPOverrideChange
+	for rows.Next() {
+		c := &model.IPOverrideChange{}
+		var createdAt int64
+		var before, after string
+		if err = rows.Scan(&c.Version, &c.Kind, &c.Network, &c.Author, &c.Reason, &c.Ticket, &createdAt, &c.Reverted, &before, &after); err != nil {
+			return nil, err
+		}
+		c.CreatedAt = time.Unix(createdAt, 0)
+		if err = json.Unmarshal([]byte(before), &c.Before); err != nil {
+			return nil, fmt.Errorf("failed to parse audit of %s: %w", c.Version, err)
+		}
+		if err = json.Unmarshal([]byte(after), &c.After); err != nil {
+			return nil, fmt.Errorf("failed to parse audit of %s: %w", c.Version, err)
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+// Checkpoint records version as the version the active database is built up
+// to, unless it already is. It returns the id of the checkpoint it recorded,
+// or 0.
+func (s *overrideStore) Checkpoint(ctx context.Context, version string) (int64, error) {
+	current, err := s.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if current == version {
+		return 0, nil
+	}
+
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO checkpoints (version, created_at) VALUES (?, ?)`, version, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// RemoveCheckpoint deletes the checkpoint with id, which makes the one before
+// it the current version again.
+func (s *overrideStore) RemoveCheckpoint(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM checkpoints WHERE id = ?`, id)
+	return err
+}
+
+// Version is the latest checkpoint, or empty when nothing was applied yet.
+func (s *overrideStore) Version(ctx context.Context) (string, error) {
+	var version string
+	err := s.db.QueryRowContext(ctx, `SELECT version FROM checkpoints ORDER BY id DESC LIMIT 1`).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return version, err
+}
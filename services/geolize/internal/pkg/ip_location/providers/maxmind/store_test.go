@@ -0,0 +1,138 @@
+package maxmind
+
+import (
+	"context"
+	"database/sql"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"path/filepath"
+	"testing"
+)
+
+func userVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func TestOpenOverrideStoreMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geolize.db")
+
+	// A store made before the audit trail, with one change in it.
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(migrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(`PRAGMA user_version = 1`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(`INSERT INTO history (name, kind, network, created_at) VALUES ('history__1', 'override', '81.2.69.0/24', 1)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	for i := 0; i < 2; i++ {
+		store, err := openOverrideStore(path)
+		if err != nil {
+			t.Fatalf("openOverrideStore() error = %v", err)
+		}
+		if got := userVersion(t, store.db); got != len(migrations) {
+			t.Fatalf("user_version = %d, want %d", got, len(migrations))
+		}
+
+		changes, err := store.Changes(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("Changes() error = %v", err)
+		}
+		if len(changes) != 1 || changes[0].Name != "history__1" {
+			t.Fatalf("Changes() = %+v, want the change made before the migration", changes)
+		}
+		store.Close()
+	}
+}
+
+func TestOverrideStoreChanges(t *testing.T) {
+	store, err := openOverrideStore(filepath.Join(t.TempDir(), "geolize.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	first := &change{
+		Name:      "history__1",
+		Kind:      changeKindOverride,
+		Overrides: []*model.IPUpdateRequest{countryOverride("81.2.69.0/24", "VN"), countryOverride("89.160.20.0/24", "FR")},
+	}
+	if _, err = store.Append(ctx, first); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if _, err = store.Append(ctx, &change{Name: "history__2", Kind: changeKindRebase, Base: testRelease + ".mmdb"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if _, err = store.Append(ctx, &change{Name: "history__1", Kind: changeKindOverride}); err == nil {
+		t.Fatal("Append() of a version that exists error = nil")
+	}
+
+	changes, err := store.Changes(ctx, 0)
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if len(changes) != 2 || len(changes[0].Overrides) != 2 || changes[0].Overrides[1].Country.ISOCode != "FR" {
+		t.Fatalf("Changes() = %+v, want both changes with the overrides in order", changes)
+	}
+	if changes, _ = store.Changes(ctx, first.ID); len(changes) != 1 || changes[0].Name != "history__2" {
+		t.Fatalf("Changes(after) = %+v, want the rebase only", changes)
+	}
+	if base, _ := store.Base(ctx); base != testRelease+".mmdb" {
+		t.Fatalf("Base() = %q, want the base of the rebase", base)
+	}
+
+	if err = store.Remove(ctx, "history__1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if overrides, _ := store.Overrides(ctx); len(overrides) != 0 {
+		t.Fatalf("Overrides() = %+v after the change was removed, want none", overrides)
+	}
+}
+
+func TestOverrideStoreCheckpoints(t *testing.T) {
+	store, err := openOverrideStore(filepath.Join(t.TempDir(), "geolize.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	if version, _ := store.Version(ctx); version != "" {
+		t.Fatalf("Version() = %q of a new store, want none", version)
+	}
+
+	first, err := store.Checkpoint(ctx, "history__1")
+	if err != nil || first == 0 {
+		t.Fatalf("Checkpoint() = %d, %v, want a new checkpoint", first, err)
+	}
+	if id, _ := store.Checkpoint(ctx, "history__1"); id != 0 {
+		t.Fatalf("Checkpoint() of the current version = %d, want 0", id)
+	}
+	second, err := store.Checkpoint(ctx, "history__2")
+	if err != nil {
+		t.Fatalf("Checkpoint() error = %v", err)
+	}
+	if version, _ := store.Version(ctx); version != "history__2" {
+		t.Fatalf("Version() = %q, want history__2", version)
+	}
+
+	if err = store.RemoveCheckpoint(ctx, second); err != nil {
+		t.Fatalf("RemoveCheckpoint() error = %v", err)
+	}
+	if version, _ := store.Version(ctx); version != "history__1" {
+		t.Fatalf("Version() = %q after RemoveCheckpoint(), want history__1", version)
+	}
+}
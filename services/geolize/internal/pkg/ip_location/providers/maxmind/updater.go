@@ -0,0 +1,347 @@
+package maxmind
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geolize/utilities/conf"
+	"geolize/utilities/logging"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const updateStateFilePath = "data/update.json"
+
+var (
+	// updateInterval is how often the database is checked for updates. Zero
+	// disables automatic updates.
+	updateInterval, _  = conf.GetDuration("geolize", "update_interval", 0)
+	updateTimeout, _   = conf.GetDuration("geolize", "update_timeout", 10*time.Minute)
+	updateMaxSizeMB, _ = conf.GetInt32("geolize", "update_max_db_size_mb", 1024)
+	licenseKey, _      = conf.GetString("geolize", "maxmind_license_key", "")
+	downloadURL, _     = conf.GetString("geolize", "maxmind_download_url",
+		"https://download.maxmind.com/app/geoip_download?edition_id=GeoLite2-City&license_key=%s&suffix=tar.gz")
+	checksumURL, _ = conf.GetString("geolize", "maxmind_checksum_url",
+		"https://download.maxmind.com/app/geoip_download?edition_id=GeoLite2-City&license_key=%s&suffix=tar.gz.sha256")
+)
+
+// maxDatabaseSize is the largest database extracted from an archive.
+var maxDatabaseSize = int64(updateMaxSizeMB) << 20
+
+// errNotModified is returned by download when the archive has not changed
+// since the last installed one.
+var errNotModified = errors.New("database is not modified")
+
+// updateState remembers the last installed archive so unchanged databases are
+// not downloaded again, including across restarts.
+type updateState struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SHA256       string `json:"sha256,omitempty"`
+}
+
+// updater periodically downloads the database archive and hands the extracted
+// mmdb to install, which re-applies the overrides and triggers the reload.
+type updater struct {
+	logger      logging.Logger
+	client      *http.Client
+	downloadURL string
+	checksumURL string
+	stateFile   string
+	install     func(ctx context.Context, path string) error
+}
+
+func newUpdater(logger logging.Logger, client *http.Client, downloadURL string, checksumURL string, install func(ctx context.Context, path string) error) *updater {
+	return &updater{
+		logger:      logger,
+		client:      client,
+		downloadURL: downloadURL,
+		checksumURL: checksumURL,
+		stateFile:   updateStateFilePath,
+		install:     install,
+	}
+}
+
+// licensedURL fills the license key into template when it has a placeholder.
+func licensedURL(template string) (string, error) {
+	if !strings.Contains(template, "%s") {
+		return template, nil
+	}
+	if len(licenseKey) == 0 {
+		return "", fmt.Errorf("MaxMind license key not configured")
+	}
+	return fmt.Sprintf(template, licenseKey), nil
+}
+
+// Run checks for an update right away and then every interval until ctx is
+// done.
+func (u *updater) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		u.update(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (u *updater) update(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	u.logger.Info(ctx, "Checking for MaxMind database update...")
+
+	if err := u.Update(ctx); err != nil {
+		if errors.Is(err, errNotModified) {
+			u.logger.Info(ctx, "MaxMind database is up to date")
+			return
+		}
+		u.logger.Error(ctx, "Failed to update MaxMind database", logging.NewError(err)...)
+	}
+}
+
+// Update downloads, verifies, extracts and installs the database archive.
+func (u *updater) Update(ctx context.Context) error {
+	state := u.loadState()
+
+	tempDir, err := os.MkdirTemp(filepath.Dir(filepath.Clean(dbBaseFolder)), "download")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	archivePath := filepath.Join(tempDir, "database.tar.gz")
+	next, err := u.download(ctx, state, archivePath)
+	if err != nil {
+		return err
+	}
+
+	mmdbPath, err := extractMMDB(archivePath, tempDir)
+	if err != nil {
+		return err
+	}
+
+	u.logger.Info(ctx, "Installing MaxMind database...", logging.NewKeyVal("file", filepath.Base(mmdbPath)))
+
+	if err = u.install(ctx, mmdbPath); err != nil {
+		return err
+	}
+
+	if err = u.saveState(next); err != nil {
+		u.logger.Error(ctx, "Failed to save update state", logging.NewError(err)...)
+	}
+
+	u.logger.Info(ctx, "MaxMind database installed successfully")
+	return nil
+}
+
+// download stores the archive at archivePath and verifies it against the
+// published checksum. It returns errNotModified when the server or the
+// checksum says the archive is the one installed last.
+func (u *updater) download(ctx context.Context, state updateState, archivePath string) (updateState, error) {
+	url, err := licensedURL(u.downloadURL)
+	if err != nil {
+		return state, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return state, err
+	}
+	if len(state.ETag) > 0 {
+		request.Header.Set("If-None-Match", state.ETag)
+	}
+	if len(state.LastModified) > 0 {
+		request.Header.Set("If-Modified-Since", state.LastModified)
+	}
+
+	u.logger.Info(ctx, "Downloading MaxMind database...")
+	resp, err := u.client.Do(request)
+	if err != nil {
+		return state, fmt.Errorf("failed to download database: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return state, errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return state, fmt.Errorf("failed to download database, status code: %d", resp.StatusCode)
+	}
+
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return state, fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(out, hash), resp.Body); err != nil {
+		return state, fmt.Errorf("failed to save downloaded file: %w", err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	expected, err := u.checksum(ctx)
+	if err != nil {
+		return state, err
+	}
+	if !strings.EqualFold(sum, expected) {
+		return state, fmt.Errorf("checksum mismatch: got %s, want %s", sum, expected)
+	}
+
+	next := updateState{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		SHA256:       sum,
+	}
+	if sum == state.SHA256 {
+		// The server ignored the conditional request but the archive is
+		// unchanged; remember the new validators and skip the install.
+		if err = u.saveState(next); err != nil {
+			u.logger.Error(ctx, "Failed to save update state", logging.NewError(err)...)
+		}
+		return state, errNotModified
+	}
+
+	return next, nil
+}
+
+// checksum fetches the .sha256 companion file, which holds the hex digest
+// followed by the archive name.
+func (u *updater) checksum(ctx context.Context) (string, error) {
+	url, err := licensedURL(u.checksumURL)
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := u.client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download checksum, status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksum: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum file is empty")
+	}
+	if _, err = hex.DecodeString(fields[0]); err != nil || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum %q", fields[0])
+	}
+
+	return fields[0], nil
+}
+
+// extractMMDB extracts the first .mmdb file of the tar.gz archive into dir.
+// The file is named after its directory in the archive, e.g.
+// GeoLite2-City_20250408.mmdb, so every release keeps a distinct name.
+func extractMMDB(archivePath string, dir string) (string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract database: %w", err)
+	}
+	defer gz.Close()
+
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("could not find .mmdb file in extracted archive")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to extract database: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, ".mmdb") {
+			continue
+		}
+
+		if header.Size > maxDatabaseSize {
+			return "", fmt.Errorf("database %s is %d bytes, more than the limit of %d", header.Name, header.Size, maxDatabaseSize)
+		}
+
+		name := path.Base(header.Name)
+		if release := path.Base(path.Dir(header.Name)); release != "." && release != "/" {
+			name = release + ".mmdb"
+		}
+
+		mmdbPath := filepath.Join(dir, name)
+		out, err := os.Create(mmdbPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to create database file: %w", err)
+		}
+		// The header size is not trusted; stop reading past the limit.
+		n, err := io.Copy(out, io.LimitReader(archive, maxDatabaseSize+1))
+		if err == nil && n > maxDatabaseSize {
+			err = fmt.Errorf("database %s is more than the limit of %d bytes", header.Name, maxDatabaseSize)
+		}
+		if err != nil {
+			out.Close()
+			return "", fmt.Errorf("failed to extract database: %w", err)
+		}
+		if err = out.Close(); err != nil {
+			return "", err
+		}
+
+		return mmdbPath, nil
+	}
+}
+
+func (u *updater) loadState() updateState {
+	var state updateState
+
+	data, err := os.ReadFile(u.stateFile)
+	if err != nil {
+		return state
+	}
+	_ = json.Unmarshal(data, &state)
+
+	return state
+}
+
+func (u *updater) saveState(state updateState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := u.stateFile + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, u.stateFile)
+}
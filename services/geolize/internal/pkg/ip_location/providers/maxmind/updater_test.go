@@ -0,0 +1,157 @@
+package maxmind
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRelease = "GeoLite2-City_20250408"
+
+// testArchive returns a tar.gz holding data as the release's mmdb file.
+func testArchive(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+
+	header := &tar.Header{
+		Name:     testRelease + "/GeoLite2-City.mmdb",
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     int64(len(data)),
+	}
+	if err := archive.WriteHeader(header); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archive.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// testServer serves archive at /db with an ETag and checksum at /sha256.
+func testServer(t *testing.T, archive []byte, checksum string) *httptest.Server {
+	t.Helper()
+
+	const etag = `"release-1"`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/db", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write(archive)
+	})
+	mux.HandleFunc("/sha256", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(checksum + "  " + testRelease + ".tar.gz\n"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testUpdater returns an updater for server that records what it installs.
+// It runs in a temporary directory, where the downloads are made.
+func testUpdater(t *testing.T, server *httptest.Server) (*updater, *[]string) {
+	t.Helper()
+
+	dir := chdirTemp(t)
+	if err := os.MkdirAll(filepath.Dir(filepath.Clean(dbBaseFolder)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := testLogger(t)
+
+	var installed []string
+	install := func(ctx context.Context, path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		installed = append(installed, filepath.Base(path)+":"+string(data))
+		return nil
+	}
+
+	u := newUpdater(logger, server.Client(), server.URL+"/db", server.URL+"/sha256", install)
+	u.stateFile = filepath.Join(dir, "update.json")
+	return u, &installed
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUpdaterInstallsAndSkipsUnmodified(t *testing.T) {
+	archive := testArchive(t, []byte("database"))
+	u, installed := testUpdater(t, testServer(t, archive, sha256Hex(archive)))
+
+	if err := u.Update(context.Background()); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	want := testRelease + ".mmdb:database"
+	if len(*installed) != 1 || (*installed)[0] != want {
+		t.Fatalf("installed = %q, want [%q]", *installed, want)
+	}
+
+	if err := u.Update(context.Background()); !errors.Is(err, errNotModified) {
+		t.Fatalf("second Update() error = %v, want %v", err, errNotModified)
+	}
+	if len(*installed) != 1 {
+		t.Fatalf("unmodified database installed again: %q", *installed)
+	}
+}
+
+func TestUpdaterRejectsChecksumMismatch(t *testing.T) {
+	archive := testArchive(t, []byte("database"))
+	u, installed := testUpdater(t, testServer(t, archive, sha256Hex([]byte("other"))))
+
+	err := u.Update(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Update() error = %v, want checksum mismatch", err)
+	}
+	if len(*installed) != 0 {
+		t.Fatalf("installed = %q, want nothing", *installed)
+	}
+	if _, err = os.Stat(u.stateFile); !os.IsNotExist(err) {
+		t.Fatalf("update state saved after a failed update")
+	}
+}
+
+func TestUpdaterRejectsOversizeDatabase(t *testing.T) {
+	limit := maxDatabaseSize
+	maxDatabaseSize = 4
+	t.Cleanup(func() { maxDatabaseSize = limit })
+
+	archive := testArchive(t, []byte("database"))
+	u, installed := testUpdater(t, testServer(t, archive, sha256Hex(archive)))
+
+	err := u.Update(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Fatalf("Update() error = %v, want size limit error", err)
+	}
+	if len(*installed) != 0 {
+		t.Fatalf("installed = %q, want nothing", *installed)
+	}
+}
@@ -1,120 +1,252 @@
 package maxmind
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/overrides"
 	"os"
-	"path/filepath"
-	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
-type versionHistoryManager struct{}
+// versionHistoryManager names database versions and keeps their history in
+// the override store. The version file mirrors the current version so the
+// Reader can watch it for reloads.
+type versionHistoryManager struct {
+	store *overrideStore
 
-func newVersionHistoryManager() *versionHistoryManager {
-	return &versionHistoryManager{}
+	// mu guards the names issued during the current second.
+	mu     sync.Mutex
+	second int64
+	issued map[string]int
 }
 
-func (m *versionHistoryManager) CreateFile(payload *model.IPUpdateRequest) (string, error) {
-	file := fmt.Sprintf("history__%d__%s.json", time.Now().Unix(), payload.IP)
+func newVersionHistoryManager(store *overrideStore) *versionHistoryManager {
+	return &versionHistoryManager{store: store}
+}
 
-	var historyFile History = History{
-		ID:        payload.IP,
-		Name:      payload.IP,
-		Overrides: make([]*model.IPUpdateRequest, 0),
+// name returns the version name of a change described by suffix, e.g.
+// "history__1744099200__rebase". Versions are unique, so a name already
+// issued during the same second gets a counter, e.g.
+// "history__1744099200__rebase__2".
+func (m *versionHistoryManager) name(suffix string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().Unix()
+	if now != m.second {
+		m.second = now
+		m.issued = make(map[string]int)
 	}
 
-	overrideBytes, err := json.Marshal([]*model.IPUpdateRequest{payload})
-	if err != nil {
-		return file, err
+	name := fmt.Sprintf("history__%d__%s", now, suffix)
+	m.issued[name]++
+	if n := m.issued[name]; n > 1 {
+		name = fmt.Sprintf("%s__%d", name, n)
 	}
+	return name
+}
 
-	err = json.Unmarshal(overrideBytes, &historyFile.Overrides)
+// Create records a change made of the single override payload, with the
+// change it makes to the record of its network, and returns the version it
+// produces.
+func (m *versionHistoryManager) Create(ctx context.Context, payload *model.IPUpdateRequest, diff *audit) (string, error) {
+	version := m.name(historyName(payload.Network))
+
+	_, err := m.store.Append(ctx, &change{
+		Name:      version,
+		Kind:      changeKindOverride,
+		Network:   payload.Network,
+		Author:    payload.Author,
+		Reason:    payload.Reason,
+		Ticket:    payload.Ticket,
+		Overrides: []*model.IPUpdateRequest{payload},
+		Audits:    []*audit{diff},
+	})
 	if err != nil {
-		return file, err
+		return version, err
 	}
 
-	data, err := json.Marshal(historyFile)
+	return version, nil
+}
+
+// CreateBatch records a change made of every override of request, with the
+// changes they make to the records of their networks, and returns the version
+// it produces.
+func (m *versionHistoryManager) CreateBatch(ctx context.Context, request *model.IPBatchUpdateRequest, diffs []*audit) (string, error) {
+	version := m.name("batch")
+
+	_, err := m.store.Append(ctx, &change{
+		Name:      version,
+		Kind:      changeKindOverride,
+		Author:    request.Author,
+		Reason:    request.Reason,
+		Ticket:    request.Ticket,
+		Overrides: request.Overrides,
+		Audits:    diffs,
+	})
 	if err != nil {
-		return file, err
+		return version, err
 	}
 
-	err = os.WriteFile(filepath.Join(dbHistories, file), data, 0644)
+	return version, nil
+}
+
+// CreateRebase records that the database was rebuilt on top of the base
+// database file base. The change has no overrides of its own.
+func (m *versionHistoryManager) CreateRebase(ctx context.Context, base string) (string, error) {
+	version := m.name("rebase")
+
+	_, err := m.store.Append(ctx, &change{
+		Name: version,
+		Kind: changeKindRebase,
+		Base: base,
+	})
 	if err != nil {
-		return file, err
+		return version, err
 	}
 
-	return file, nil
+	return version, nil
 }
 
-func (m *versionHistoryManager) RemoveFile(file string) error {
-	return os.Remove(filepath.Join(dbHistories, file))
+// CreateRemoval records the removal of every override made for the network of
+// request and marks them as removed, in one transaction, and returns the
+// version it produces.
+func (m *versionHistoryManager) CreateRemoval(ctx context.Context, request *model.IPRemoveRequest, diff *audit) (string, error) {
+	version := m.name("remove__" + historyName(diff.Network))
+
+	err := m.store.RemoveOverrides(ctx, diff.Network, &change{
+		Name:    version,
+		Kind:    changeKindRemove,
+		Network: diff.Network,
+		Author:  request.Author,
+		Reason:  request.Reason,
+		Ticket:  request.Ticket,
+		Audits:  []*audit{diff},
+	})
+	if err != nil {
+		return version, err
+	}
+
+	return version, nil
 }
 
-func (m *versionHistoryManager) GetAllFiles() ([]string, error) {
-	files, err := filepath.Glob(filepath.Join(dbHistories, "history*.json"))
+// CreateRollback marks every change made after the version of request as
+// reverted and records the rollback with the changes it makes to the records
+// of diffs, in one transaction, and returns the version it produces.
+func (m *versionHistoryManager) CreateRollback(ctx context.Context, request *model.RollbackRequest, diffs []*audit) (string, error) {
+	version := m.name("rollback")
+
+	err := m.store.Rollback(ctx, request.Version, &change{
+		Name:   version,
+		Kind:   changeKindRollback,
+		Author: request.Author,
+		Reason: request.Reason,
+		Ticket: request.Ticket,
+		Audits: diffs,
+	})
 	if err != nil {
-		return nil, err
+		return version, err
 	}
 
-	sort.Strings(files)
+	return version, nil
+}
 
-	return files, nil
+// Base returns the name of the base database file of the latest rebase, or
+// empty when the database was never rebased.
+func (m *versionHistoryManager) Base(ctx context.Context) (string, error) {
+	return m.store.Base(ctx)
 }
 
-func (m *versionHistoryManager) GetUpdateFilesFrom(version string) ([]string, error) {
-	allFiles, err := m.GetAllFiles()
-	if err != nil {
-		return nil, err
-	}
+// Overrides returns every override with the version that introduced it.
+func (m *versionHistoryManager) Overrides(ctx context.Context) ([]overrides.Change, error) {
+	return m.store.Overrides(ctx)
+}
 
-	if len(allFiles) == 0 {
-		return nil, nil
-	}
+// Audits returns the change log of every network, oldest first.
+func (m *versionHistoryManager) Audits(ctx context.Context) ([]*model.IPOverrideChange, error) {
+	return m.store.Audits(ctx)
+}
 
-	if len(version) == 0 {
-		return allFiles, nil
-	}
+func (m *versionHistoryManager) Remove(ctx context.Context, version string) error {
+	return m.store.Remove(ctx, version)
+}
 
-	_, err = os.Stat(filepath.Join(dbHistories, version))
-	if os.IsNotExist(err) {
-		return allFiles, nil
-	}
+// GetAll returns every change, oldest first.
+func (m *versionHistoryManager) GetAll(ctx context.Context) ([]*change, error) {
+	return m.store.Changes(ctx, 0)
+}
 
-	checkpointIndex := -1
-	for i, file := range allFiles {
-		if filepath.Base(file) == version {
-			checkpointIndex = i
-			break
-		}
+// GetUpdatesFrom returns the changes made after version, oldest first. Every
+// change is returned when version is empty or unknown.
+func (m *versionHistoryManager) GetUpdatesFrom(ctx context.Context, version string) ([]*change, error) {
+	if len(version) == 0 {
+		return m.GetAll(ctx)
 	}
 
-	if checkpointIndex == -1 {
-		return allFiles, nil
+	id, err := m.store.changeID(ctx, version)
+	if err != nil {
+		return m.GetAll(ctx)
 	}
 
-	return allFiles[checkpointIndex:], nil
+	return m.store.Changes(ctx, id)
 }
 
-func (m *versionHistoryManager) GetUpdateFilesFromVersion() ([]string, error) {
+func (m *versionHistoryManager) GetUpdatesFromVersion(ctx context.Context) ([]*change, error) {
 	version, err := m.GetVersion()
 	if err != nil {
 		return nil, err
 	}
 
-	return m.GetUpdateFilesFrom(version)
+	return m.GetUpdatesFrom(ctx, version)
 }
 
 func (m *versionHistoryManager) GetVersion() (string, error) {
-	versionBytes, err := os.ReadFile(versionFilePath)
+	return m.store.Version(context.Background())
+}
+
+// SetVersion checkpoints version and publishes it to the version file, which
+// makes the Reader reload. The checkpoint is taken back when the version file
+// cannot be written, so a failed SetVersion leaves the current version as it
+// was.
+func (m *versionHistoryManager) SetVersion(version string) error {
+	ctx := context.Background()
+	version = strings.TrimSpace(version)
+	checkpoint, err := m.store.Checkpoint(ctx, version)
 	if err != nil {
-		return "", err
+		return err
 	}
-	return strings.TrimSpace(string(versionBytes)), nil
+
+	if err = os.WriteFile(versionFilePath, []byte(version), 0644); err != nil {
+		if checkpoint != 0 {
+			if rerr := m.store.RemoveCheckpoint(ctx, checkpoint); rerr != nil {
+				return errors.Join(err, fmt.Errorf("failed to remove checkpoint of version %s: %w", version, rerr))
+			}
+		}
+		return err
+	}
+	return nil
 }
 
-func (m *versionHistoryManager) SetVersion(version string) error {
-	return os.WriteFile(versionFilePath, []byte(strings.TrimSpace(version)), 0644)
+// overridesOf flattens the overrides of changes in the order they apply,
+// leaving out the ones removed by one of changes.
+func overridesOf(changes []*change) []*model.IPUpdateRequest {
+	ids := make(map[int64]bool, len(changes))
+	for _, c := range changes {
+		ids[c.ID] = true
+	}
+
+	var overrides []*model.IPUpdateRequest
+	for _, c := range changes {
+		for i, override := range c.Overrides {
+			if removedBy := c.removedBy(i); removedBy != 0 && ids[removedBy] {
+				continue
+			}
+			overrides = append(overrides, override)
+		}
+	}
+	return overrides
 }
@@ -2,16 +2,19 @@ package maxmind
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/overrides"
 	jsonhelper "geolize/utilities/json_helper"
 	"geolize/utilities/logging"
-	"log"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/oschwald/geoip2-golang"
@@ -20,33 +23,523 @@ import (
 	"github.com/maxmind/mmdbwriter/mmdbtype"
 )
 
+// previousSuffix names the link write keeps to the database file it replaced
+// until the version of the new one is set.
+const previousSuffix = ".previous"
+
+// errNotPublished is returned for a change that was written into the database
+// when neither its version could be set nor the write undone. The change
+// stays in the history and is published with the next version.
+var errNotPublished = errors.New("change was written but not published")
+
 type Writer struct {
-	writer  *mmdbwriter.Tree
-	history *versionHistoryManager
-	logger  logging.Logger
-	once    *sync.Once
+	// mu serializes changes to the tree and the database file.
+	mu        sync.Mutex
+	writer    *mmdbwriter.Tree
+	ipVersion uint
+	history   *versionHistoryManager
+	logger    logging.Logger
+	once      *sync.Once
+	// queue serializes updates and coalesces them into flushes.
+	queue *writeQueue
+	// written holds the os.FileInfo of the database file last written, to
+	// tell the writer's own writes from a file replaced by someone else.
+	written atomic.Value
+}
+
+// Update queues request for the single writer goroutine and returns the
+// version of the flush that wrote it. Updates that arrive within the write
+// window are coalesced into one database write.
+func (w *Writer) Update(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	return w.queue.Submit(ctx, request)
+}
+
+// flush writes updates into the tree in the order they were queued and then
+// writes the database once. Each update is recorded as its own change, and
+// every caller gets back the version of the last one, which contains all of
+// them. An update with an invalid target fails alone.
+func (w *Writer) flush(updates []*pendingUpdate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var written []*pendingUpdate
+	var versions []string
+	for _, update := range updates {
+		version, err := w.apply(update.ctx, update.request)
+		if err != nil {
+			update.done <- updateResult{err: err}
+			continue
+		}
+		written = append(written, update)
+		versions = append(versions, version)
+	}
+	if len(written) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	output := filepath.Join(dbFolder, db)
+	if err := w.write(output); err != nil {
+		for _, version := range versions {
+			w.history.Remove(ctx, version)
+		}
+		w.logger.Error(ctx, "Failed to override database", append(logging.NewError(err), logging.NewKeyVal("number_updates", len(written)))...)
+		w.discard(ctx, output)
+		for _, update := range written {
+			update.done <- updateResult{err: err}
+		}
+		return
+	}
+
+	latest := versions[len(versions)-1]
+	err := w.setVersion(ctx, output, latest)
+	if err != nil && !errors.Is(err, errNotPublished) {
+		for _, version := range versions {
+			w.history.Remove(ctx, version)
+		}
+	}
+
+	for _, update := range written {
+		update.done <- updateResult{version: latest, err: err}
+	}
 }
 
-func (w *Writer) Update(ctx context.Context, request *model.IPUpdateRequest) error {
-	file, err := w.history.CreateFile(request)
+// apply checks request, records it in the history and inserts it into the
+// tree, without writing the database. It returns the version of the change.
+func (w *Writer) apply(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	if err := normalizeOverride(request); err != nil {
+		w.logger.Error(ctx, "Invalid override target", logging.NewError(err)...)
+		return "", err
+	}
+
+	if err := w.checkIPVersion(request); err != nil {
+		w.logger.Error(ctx, "Invalid override target", logging.NewError(err)...)
+		return "", err
+	}
+
+	diff, err := w.overrideAudit(request)
 	if err != nil {
-		w.logger.Error(ctx, "Failed to create history file", logging.NewError(err)...)
-		return err
+		w.logger.Error(ctx, "Failed to describe override", logging.NewError(err)...)
+		return "", err
+	}
+
+	version, err := w.history.Create(ctx, request, diff)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to record override", logging.NewError(err)...)
+		return "", err
+	}
+
+	if err = w.insert([]*model.IPUpdateRequest{request}); err != nil {
+		w.history.Remove(ctx, version)
+		w.logger.Error(ctx, "Failed to override database", append(logging.NewError(err), logging.NewKeyVal("version", version))...)
+		return "", err
+	}
+
+	return version, nil
+}
+
+// discard reloads the tree from the database at output, dropping the
+// overrides inserted into it since output was last written.
+func (w *Writer) discard(ctx context.Context, output string) {
+	tree, err := mmdbwriter.Load(output, mmdbwriter.Options{})
+	if err != nil {
+		w.logger.Error(ctx, "Failed to reload database", logging.NewError(err)...)
+		return
+	}
+	w.writer = tree
+}
+
+// BatchUpdate writes every override of request with a single pass over the
+// tree, one database write and one version. Every override is checked first,
+// so either all of them are applied or none is.
+func (w *Writer) BatchUpdate(ctx context.Context, request *model.IPBatchUpdateRequest) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, override := range request.Overrides {
+		err := normalizeOverride(override)
+		if err == nil {
+			err = w.checkIPVersion(override)
+		}
+		if err != nil {
+			w.logger.Error(ctx, "Invalid override target", append(logging.NewError(err), logging.NewKeyVal("index", i))...)
+			return "", fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+	}
+
+	diffs, err := w.batchAudits(request.Overrides)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to describe overrides", logging.NewError(err)...)
+		return "", err
 	}
 
+	version, err := w.history.CreateBatch(ctx, request, diffs)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to record overrides", logging.NewError(err)...)
+		return "", err
+	}
+
+	w.logger.Info(ctx, "Applying batch of overrides",
+		logging.NewKeyVal("version", version), logging.NewKeyVal("number_overrides", len(request.Overrides)))
+
 	output := filepath.Join(dbFolder, db)
-	err = w.override(file, output)
+	if err = w.override(request.Overrides, output); err != nil {
+		w.history.Remove(ctx, version)
+		w.logger.Error(ctx, "Failed to override database", append(logging.NewError(err), logging.NewKeyVal("version", version))...)
+		// The database file was not replaced, so it still has none of the
+		// overrides the tree may already hold.
+		w.discard(ctx, output)
+		return "", err
+	}
+
+	if err = w.setVersion(ctx, output, version); err != nil {
+		if !errors.Is(err, errNotPublished) {
+			w.history.Remove(ctx, version)
+		}
+		return version, err
+	}
+
+	return version, nil
+}
+
+// Rebase rebuilds the database on top of the base database at path: every
+// change in the history is re-applied to it, the result replaces the active database
+// and a rebase change becomes the new version, which makes the Reader
+// hot swap it. path is then moved into dbBaseFolder, where removals and
+// rollbacks rebuild from it.
+func (w *Writer) Rebase(ctx context.Context, path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// The rebase entry carries no overrides. It only makes the version change
+	// and marks where the new base starts in the history.
+	version, err := w.history.CreateRebase(ctx, filepath.Base(path))
 	if err != nil {
-		w.logger.Error(ctx, "Failed to override database", append(logging.NewError(err), logging.NewKeyVal("file", file))...)
+		w.logger.Error(ctx, "Failed to record rebase", logging.NewError(err)...)
 		return err
 	}
 
-	err = w.history.SetVersion(file)
+	changes, err := w.history.GetAll(ctx)
 	if err != nil {
-		w.logger.Error(ctx, "Failed to set version", logging.NewError(err)...)
+		w.history.Remove(ctx, version)
+		w.logger.Error(ctx, "Failed to get history", logging.NewError(err)...)
+		return err
+	}
+
+	w.logger.Info(ctx, "Re-applying history onto new base database",
+		logging.NewKeyVal("base", path), logging.NewKeyVal("number_changes", len(changes)))
+
+	if err = w.rebuild(ctx, path, overridesOf(changes)); err != nil {
+		w.history.Remove(ctx, version)
 		return err
 	}
 
+	if err = w.setVersion(ctx, filepath.Join(dbFolder, db), version); err != nil {
+		if !errors.Is(err, errNotPublished) {
+			w.history.Remove(ctx, version)
+		}
+		return err
+	}
+
+	if err = os.MkdirAll(dbBaseFolder, 0755); err == nil {
+		err = os.Rename(path, filepath.Join(dbBaseFolder, filepath.Base(path)))
+	}
+	if err != nil {
+		w.logger.Error(ctx, "Failed to move base database", append(logging.NewError(err), logging.NewKeyVal("path", path))...)
+	}
+
+	return nil
+}
+
+// Remove removes every override made for the request's IP or network. The
+// database is rebuilt from the pristine base database with the
+// remaining overrides, so the network gets its original record back, and the
+// removal becomes the new version.
+func (w *Writer) Remove(ctx context.Context, request *model.IPRemoveRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	network, err := overrideNetwork(&model.IPUpdateRequest{IP: request.IP, Network: request.Network})
+	if err != nil {
+		w.logger.Error(ctx, "Invalid override target", logging.NewError(err)...)
+		return err
+	}
+	target := network.String()
+
+	base, err := w.pristineBase(ctx)
+	if err != nil {
+		w.logger.Error(ctx, "No pristine base database to restore from", logging.NewError(err)...)
+		return err
+	}
+
+	changes, err := w.history.GetAll(ctx)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to get history", logging.NewError(err)...)
+		return err
+	}
+
+	var remaining []*model.IPUpdateRequest
+	for _, override := range overridesOf(changes) {
+		if storedNetwork(override) != target {
+			remaining = append(remaining, override)
+		}
+	}
+	if len(remaining) == len(overridesOf(changes)) {
+		return fmt.Errorf("%w for %s", model.ErrOverrideNotFound, target)
+	}
+
+	w.logger.Info(ctx, "Rebuilding database without override",
+		logging.NewKeyVal("network", target), logging.NewKeyVal("base", base))
+
+	before := w.record(network)
+	previous, previousIPVersion := w.writer, w.ipVersion
+	if err = w.rebuild(ctx, base, remaining); err != nil {
+		return err
+	}
+
+	diff, err := newAudit(network, before, w.record(network))
+	if err != nil {
+		w.logger.Error(ctx, "Failed to describe removal", logging.NewError(err)...)
+		w.restore(ctx, previous, previousIPVersion)
+		return err
+	}
+
+	version, err := w.history.CreateRemoval(ctx, request, diff)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to record removal", logging.NewError(err)...)
+		w.restore(ctx, previous, previousIPVersion)
+		return err
+	}
+
+	if err = w.setVersion(ctx, filepath.Join(dbFolder, db), version); err != nil {
+		if !errors.Is(err, errNotPublished) {
+			w.history.Remove(ctx, version)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Rollback rebuilds the database from the base database and the history up
+// to the version of request. The changes made after it are marked as
+// reverted, not deleted, and the rollback becomes the new version, which the
+// Reader picks up like any other. It returns that version.
+func (w *Writer) Rollback(ctx context.Context, request *model.RollbackRequest) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changes, err := w.history.GetAll(ctx)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to get history", logging.NewError(err)...)
+		return "", err
+	}
+
+	i := slices.IndexFunc(changes, func(c *change) bool { return c.Name == request.Version })
+	if i < 0 {
+		return "", fmt.Errorf("%w: %s", model.ErrVersionNotFound, request.Version)
+	}
+	kept, reverted := changes[:i+1], changes[i+1:]
+	if len(reverted) == 0 {
+		// Already at that version.
+		return w.history.GetVersion()
+	}
+
+	// The base is the one the kept history was applied to, since a rebase
+	// made after the version is reverted too.
+	base := db
+	for _, c := range kept {
+		if c.Kind == changeKindRebase {
+			base = c.Base
+		}
+	}
+	path, err := w.basePath(base)
+	if err != nil {
+		w.logger.Error(ctx, "No pristine base database to restore from", logging.NewError(err)...)
+		return "", err
+	}
+
+	// Every network a reverted change touched gets an entry in the change log.
+	var networks []*net.IPNet
+	seen := make(map[string]bool)
+	for _, c := range reverted {
+		if len(c.Network) == 0 || seen[c.Network] {
+			continue
+		}
+		seen[c.Network] = true
+		if network, err := overrideNetwork(&model.IPUpdateRequest{Network: c.Network}); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	befores := make([]mmdbtype.Map, len(networks))
+	for j, network := range networks {
+		befores[j] = w.record(network)
+	}
+
+	w.logger.Info(ctx, "Rolling database back",
+		logging.NewKeyVal("version", request.Version), logging.NewKeyVal("number_reverted", len(reverted)),
+		logging.NewKeyVal("base", path))
+
+	previous, previousIPVersion := w.writer, w.ipVersion
+	if err = w.rebuild(ctx, path, overridesOf(kept)); err != nil {
+		return "", err
+	}
+
+	diffs := make([]*audit, 0, len(networks))
+	for j, network := range networks {
+		diff, err := newAudit(network, befores[j], w.record(network))
+		if err != nil {
+			w.logger.Error(ctx, "Failed to describe rollback", logging.NewError(err)...)
+			w.restore(ctx, previous, previousIPVersion)
+			return "", err
+		}
+		diffs = append(diffs, diff)
+	}
+
+	version, err := w.history.CreateRollback(ctx, request, diffs)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to record rollback", logging.NewError(err)...)
+		w.restore(ctx, previous, previousIPVersion)
+		return "", err
+	}
+
+	if err = w.setVersion(ctx, filepath.Join(dbFolder, db), version); err != nil {
+		if !errors.Is(err, errNotPublished) {
+			w.history.Remove(ctx, version)
+		}
+		return version, err
+	}
+
+	return version, nil
+}
+
+// restore puts the tree that was active before a failed change back into
+// the database file, since the history still describes it.
+func (w *Writer) restore(ctx context.Context, previous *mmdbwriter.Tree, ipVersion uint) {
+	w.writer, w.ipVersion = previous, ipVersion
+	if err := w.override(nil, filepath.Join(dbFolder, db)); err != nil {
+		w.logger.Error(ctx, "Failed to restore database", logging.NewError(err)...)
+	}
+}
+
+// pristineBase returns the path of the base database that the history
+// applies to: the base of the latest rebase, or the copy of the configured
+// database taken before any override was written into it.
+func (w *Writer) pristineBase(ctx context.Context) (string, error) {
+	base, err := w.history.Base(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(base) == 0 {
+		base = db
+	}
+
+	return w.basePath(base)
+}
+
+// basePath returns the path of the base database file base in
+// dbBaseFolder. The error matches model.ErrBaseUnavailable when it is not
+// there.
+func (w *Writer) basePath(base string) (string, error) {
+	path := filepath.Join(dbBaseFolder, base)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%w: %s, drop the original database into %s or install a new one to make it available: %v",
+			model.ErrBaseUnavailable, base, dbFolder, err)
+	}
+
+	return path, nil
+}
+
+// keepPristineBase copies the configured database into dbBaseFolder while no
+// override was written into it yet, so removals can restore original records.
+// Once overrides were written without a copy, it warns that they cannot be
+// removed until a base database is installed.
+func (w *Writer) keepPristineBase(ctx context.Context) error {
+	version, err := w.history.GetVersion()
+	if err != nil {
+		return err
+	}
+	if len(version) > 0 {
+		if _, err = w.pristineBase(ctx); errors.Is(err, model.ErrBaseUnavailable) {
+			w.logger.Warn(ctx, "No pristine copy of the database, overrides cannot be removed or rolled back until "+
+				"the original database or a new release is dropped into the database folder", logging.NewError(err)...)
+			return nil
+		}
+		return err
+	}
+
+	path := filepath.Join(dbBaseFolder, db)
+	if _, err = os.Stat(path); err == nil {
+		return nil
+	}
+
+	w.logger.Info(ctx, "Keeping a pristine copy of the database", logging.NewKeyVal("path", path))
+
+	return keepBase(filepath.Join(dbFolder, db), path)
+}
+
+// keepBase copies the database at src to dst in dbBaseFolder.
+func keepBase(src, dst string) error {
+	if err := os.MkdirAll(dbBaseFolder, 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	return out.Close()
+}
+
+// rebuild loads the base database at path, applies overrides to it and
+// replaces the active database with the result. The tree of the writer is
+// only replaced when the database was written. path is left untouched.
+func (w *Writer) rebuild(ctx context.Context, path string, overrides []*model.IPUpdateRequest) error {
+	tree, err := mmdbwriter.Load(path, mmdbwriter.Options{})
+	if err != nil {
+		w.logger.Error(ctx, "Failed to load base database", append(logging.NewError(err), logging.NewKeyVal("path", path))...)
+		return err
+	}
+
+	ipVersion, err := databaseIPVersion(path)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to read base database metadata", append(logging.NewError(err), logging.NewKeyVal("path", path))...)
+		return err
+	}
+
+	rebuilt := &Writer{
+		writer:    tree,
+		ipVersion: ipVersion,
+		history:   w.history,
+		logger:    w.logger,
+		once:      w.once,
+	}
+
+	output := filepath.Join(dbFolder, db)
+	if err = rebuilt.override(overrides, output); err != nil {
+		w.logger.Error(ctx, "Failed to override database", logging.NewError(err)...)
+		return err
+	}
+
+	w.writer = tree
+	w.ipVersion = ipVersion
+	if info, ok := rebuilt.written.Load().(os.FileInfo); ok {
+		w.written.Store(info)
+	}
+
 	return nil
 }
 
@@ -57,7 +550,7 @@ func (w *Writer) Lookup(ctx context.Context, ip string) (*geoip2.City, error) {
 	return nil, nil
 }
 
-func NewWriter(logger logging.Logger) (*Writer, error) {
+func NewWriter(logger logging.Logger, history *versionHistoryManager) (*Writer, error) {
 	if logger == nil {
 		panic("logger is nil")
 	}
@@ -75,114 +568,127 @@ func NewWriter(logger logging.Logger) (*Writer, error) {
 		return nil, err
 	}
 
+	ipVersion, err := databaseIPVersion(filepath.Join(dbFolder, db))
+	if err != nil {
+		logger.Fatal(context.Background(), "Failed to read database metadata", logging.NewError(err)...)
+		return nil, err
+	}
+
 	logger.Info(context.Background(), "IPGeolite Writer is initialized", logging.NewKeyVal("ip_usecase", db))
 
 	w := &Writer{
-		writer:  writer,
-		logger:  logger,
-		history: newVersionHistoryManager(),
-		once:    &sync.Once{},
+		writer:    writer,
+		ipVersion: ipVersion,
+		logger:    logger,
+		history:   history,
+		once:      &sync.Once{},
+	}
+
+	if err = w.keepPristineBase(context.Background()); err != nil {
+		logger.Error(context.Background(), "Failed to keep a pristine copy of the database", logging.NewError(err)...)
 	}
 
 	w.loadToLatest()
 
+	w.queue = newWriteQueue(writeWindow, writeMaxBatch, w.flush)
+	go w.queue.run()
+	go w.queue.reportStats(logger, writeStatsInterval)
+
 	return w, nil
 }
 
-func (w *Writer) loadToLatest() {
-	w.once.Do(func() {
-		w.logger.Debug(context.Background(), "Database is being updated...")
-		files, err := w.history.GetAllFiles()
-		if err != nil {
-			w.logger.Fatal(context.Background(), "Failed to get history files", logging.NewError(err)...)
-			return
-		}
-
-		defer func() {
-			if err != nil {
-				w.logger.Fatal(context.Background(), "Failed to load history files", logging.NewError(err)...)
-			}
+// Check reports whether the writer accepts override, without writing it.
+func (w *Writer) Check(override *model.IPUpdateRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-			w.logger.Debug(context.Background(), "Database is up to date")
-		}()
-
-		if len(files) == 0 {
-			return
-		}
+	return w.checkIPVersion(override)
+}
 
-		updatedFiles, err := w.history.GetUpdateFilesFromVersion()
-		if err != nil {
-			w.logger.Fatal(context.Background(), "Failed to get update history files", logging.NewError(err)...)
-			return
-		}
+// databaseIPVersion reports whether the database at path is an IPv4-only (4)
+// or a dual-stack (6) database.
+func databaseIPVersion(path string) (uint, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
 
-		if len(updatedFiles) == 0 {
-			return
-		}
+	return reader.Metadata().IPVersion, nil
+}
 
-		w.logger.Debug(context.Background(), "Updating database with files", logging.NewKeyVal("number_files", len(updatedFiles)))
+// checkIPVersion rejects IPv6 overrides against an IPv4-only database, which
+// mmdbwriter would otherwise truncate into an unrelated IPv4 network.
+func (w *Writer) checkIPVersion(override *model.IPUpdateRequest) error {
+	network, err := overrideNetwork(override)
+	if err != nil {
+		return err
+	}
 
-		mergeFile, err := mergeFiles(updatedFiles)
-		if err != nil {
-			w.logger.Fatal(context.Background(), "Failed to merge history files", logging.NewError(err)...)
-			return
-		}
+	if network.IP.To4() == nil && w.ipVersion == 4 {
+		return fmt.Errorf("%w: cannot override IPv6 network %s in an IPv4-only database", model.ErrInvalidIP, network)
+	}
 
-		defer func() {
-			w.history.RemoveFile(mergeFile)
-		}()
+	return nil
+}
 
-		w.logger.Info(context.Background(), "Updating database with file", logging.NewKeyVal("file", mergeFile))
+func (w *Writer) loadToLatest() {
+	w.once.Do(func() {
+		ctx := context.Background()
+		w.logger.Debug(ctx, "Database is being updated...")
 
-		var config History
-		mergedFileBytes, err := os.ReadFile(filepath.Join(dbHistories, mergeFile))
+		changes, err := w.history.GetUpdatesFromVersion(ctx)
 		if err != nil {
-			w.logger.Fatal(context.Background(), "Failed to read history file", logging.NewError(err)...)
+			w.logger.Fatal(ctx, "Failed to get history", logging.NewError(err)...)
 			return
 		}
-		if err = json.Unmarshal(mergedFileBytes, &config); err != nil {
-			w.logger.Fatal(context.Background(), "Failed to parse history file", logging.NewError(err)...)
+
+		if len(changes) == 0 {
+			w.logger.Debug(ctx, "Database is up to date")
 			return
 		}
 
+		latest := changes[len(changes)-1].Name
+		w.logger.Info(ctx, "Updating database with history", logging.NewKeyVal("number_changes", len(changes)))
+
 		output := filepath.Join(dbFolder, db)
-		err = w.override(mergeFile, output)
-		if err != nil {
-			w.logger.Fatal(context.Background(), "Failed to override database", logging.NewError(err)...)
+		if err = w.override(overridesOf(changes), output); err != nil {
+			w.logger.Fatal(ctx, "Failed to override database", logging.NewError(err)...)
 			return
 		}
 
-		// update version file
-		err = w.history.SetVersion(config.Name)
-		if err != nil {
-			w.logger.Fatal(context.Background(), "Failed to update version file", logging.NewError(err)...)
+		if err = w.setVersion(ctx, output, latest); err != nil {
+			w.logger.Fatal(ctx, "Failed to update version", logging.NewError(err)...)
 			return
 		}
 
-		w.logger.Info(context.Background(), "Database has an update to version", logging.KeyVal{Key: "version", Val: config.Name})
+		w.logger.Info(ctx, "Database has an update to version", logging.KeyVal{Key: "version", Val: latest})
 	})
 }
 
-func (w *Writer) override(mergedFile string, output string) error {
-	// Read and parse the override file
-	overrideData, err := os.ReadFile(filepath.Join(dbHistories, mergedFile))
-	if err != nil {
-		log.Fatalf("Error reading override file: %v", err)
+// override applies overrides to the tree and writes it to output.
+func (w *Writer) override(overrides []*model.IPUpdateRequest, output string) error {
+	if err := w.insert(overrides); err != nil {
 		return err
 	}
 
-	var config History
-	if err = json.Unmarshal(overrideData, &config); err != nil {
-		log.Fatalf("Error parsing override file: %v", err)
-		return err
-	}
+	return w.write(output)
+}
 
-	// Process each override
-	for _, override := range config.Overrides {
-		_, network, err := net.ParseCIDR(fmt.Sprintf("%s/32", override.IP))
+// insert applies overrides to the tree.
+func (w *Writer) insert(overrides []*model.IPUpdateRequest) error {
+	// Process each override in history order. InsertFunc calls the inserter
+	// once for every existing record inside the network, so a broader override
+	// is layered on top of each more-specific network it covers, and a later
+	// override always wins over an earlier one for the addresses they share.
+	for _, override := range overrides {
+		network, err := overrideNetwork(override)
+		if err == nil {
+			err = w.checkIPVersion(override)
+		}
 		if err != nil {
-			log.Fatalf("Error parsing IP: %v", err)
-			return err
+			w.logger.Error(context.Background(), "Skipping override with invalid network", logging.NewError(err)...)
+			continue
 		}
 
 		err = w.writer.InsertFunc(network, func(value mmdbtype.DataType) (mmdbtype.DataType, error) {
@@ -205,30 +711,35 @@ func (w *Writer) override(mergedFile string, output string) error {
 			return newMap, nil
 		})
 		if err != nil {
-			log.Fatalf("Error inserting override: %v", err)
-			return err
+			return fmt.Errorf("failed to insert override for %s: %w", network, err)
 		}
 	}
 
+	return nil
+}
+
+// write writes the tree to output, through a temporary file so readers never
+// see a partial database.
+// The file it replaces is kept as output+previousSuffix for setVersion.
+func (w *Writer) write(output string) (err error) {
 	tmpOutput := fmt.Sprintf("%s__%d.tmp", output, time.Now().Unix())
 	fh, err := os.Create(tmpOutput)
 	if err != nil {
-		log.Fatalf("Error creating output file: %v", err)
-		return err
+		return fmt.Errorf("failed to create output file: %w", err)
 	}
 
 	defer func() {
 		if r := recover(); r != nil {
-			w.logger.Error(context.Background(), "Failed to override database", logging.NewError(fmt.Errorf("%v", r))...)
-			os.Remove(tmpOutput)
+			err = fmt.Errorf("failed to write output file: %v", r)
 		}
 		_ = fh.Close()
+		if err != nil {
+			os.Remove(tmpOutput)
+		}
 	}()
 
-	_, err = w.writer.WriteTo(fh)
-	if err != nil {
-		log.Fatalf("Error writing to output file: %v", err)
-		return err
+	if _, err = w.writer.WriteTo(fh); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
 	}
 
 	// Close the file before moving
@@ -237,6 +748,13 @@ func (w *Writer) override(mergedFile string, output string) error {
 		return err
 	}
 
+	previous := output + previousSuffix
+	os.Remove(previous)
+	if err = os.Link(output, previous); err != nil && !os.IsNotExist(err) {
+		w.logger.Warn(context.Background(), "Failed to keep the previous database, a failure to set the version cannot be undone",
+			logging.NewError(err)...)
+	}
+
 	// Move the temporary file to the final destination
 	if err = os.Rename(tmpOutput, output); err != nil {
 		w.logger.Error(context.Background(), "Failed to move temporary file to output",
@@ -246,177 +764,234 @@ func (w *Writer) override(mergedFile string, output string) error {
 		return err
 	}
 
-	return nil
-}
+	if info, err := os.Stat(output); err == nil {
+		w.written.Store(info)
+	}
 
-type History struct {
-	ID        string                   `json:"id"`
-	Name      string                   `json:"name"`
-	Overrides []*model.IPUpdateRequest `json:"overrides"`
+	return nil
 }
 
-func mergeFiles(files []string) (mergeFile string, err error) {
-	var (
-		mergedOverrides = make([]*model.IPUpdateRequest, 0)
-	)
-
-	// Iterate over the files and merge them into a single file
-	for i, file := range files {
-
-		// Read the file
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return mergeFile, fmt.Errorf("error reading file %s: %v", file, err)
-		}
+// setVersion makes version, whose changes the database at output already
+// holds, the current one. When that fails, the previous database file is put
+// back and the tree reloaded from it, so the database keeps matching the
+// history once the caller drops version from it. When the write cannot be
+// undone either, the error matches errNotPublished and version must be kept.
+func (w *Writer) setVersion(ctx context.Context, output, version string) error {
+	previous := output + previousSuffix
+
+	err := w.history.SetVersion(version)
+	if err == nil {
+		os.Remove(previous)
+		return nil
+	}
+	w.logger.Error(ctx, "Failed to set version", append(logging.NewError(err), logging.NewKeyVal("version", version))...)
 
-		var config History
-		if err := json.Unmarshal(data, &config); err != nil {
-			return mergeFile, fmt.Errorf("error parsing file %s: %v", file, err)
-		}
+	if rerr := os.Rename(previous, output); rerr != nil {
+		w.logger.Error(ctx, "Failed to put the previous database back", logging.NewError(rerr)...)
+		return fmt.Errorf("%w in version %s: %w", errNotPublished, version, err)
+	}
+	if info, serr := os.Stat(output); serr == nil {
+		w.written.Store(info)
+	}
+	w.discard(ctx, output)
+	if ipVersion, verr := databaseIPVersion(output); verr == nil {
+		w.ipVersion = ipVersion
+	}
 
-		// Merge the overrides
-		mergedOverrides = append(mergedOverrides, config.Overrides...)
+	return err
+}
 
-		if i == len(files)-1 {
-			err := func() error {
-				mergeFile = fmt.Sprintf("merged_%s", filepath.Base(file))
-				f, err := os.Create(filepath.Join(dbHistories, mergeFile))
-				if err != nil {
-					return fmt.Errorf("error creating merged file: %v", err)
-				}
-				defer f.Close()
+// wrote reports whether the file at path is still the database file the
+// writer last wrote.
+func (w *Writer) wrote(path string) bool {
+	written, ok := w.written.Load().(os.FileInfo)
+	if !ok {
+		return false
+	}
 
-				// Write the merged overrides to the file
-				mergedData, err := json.Marshal(History{ID: config.ID, Name: filepath.Base(file), Overrides: mergedOverrides})
-				if err != nil {
-					return fmt.Errorf("error marshaling merged data: %v", err)
-				}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
 
-				_, err = f.Write(mergedData)
-				if err != nil {
-					return fmt.Errorf("error writing merged data to file: %v", err)
-				}
+	return os.SameFile(info, written) && info.ModTime().Equal(written.ModTime()) && info.Size() == written.Size()
+}
 
-				return nil
-			}()
-			if err != nil {
-				return mergeFile, err
-			}
+// History is an entry of the legacy JSON history files, which MigrateHistories
+// imports into the override store.
+type History struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Network string `json:"network,omitempty"`
+	// Base is set on rebase entries to the base database file they started.
+	Base      string                   `json:"base,omitempty"`
+	Overrides []*model.IPUpdateRequest `json:"overrides"`
+}
 
-			break
-		}
+// applyOverride writes overrideIP into original. A whole-object override
+// replaces every sub-object it sets, a partial one only writes its fields.
+func applyOverride(original mmdbtype.Map, overrideIP *model.IPUpdateRequest) {
+	if len(overrideIP.Fields) == 0 {
+		replaceObjects(original, overrideIP)
+	} else {
+		patch := mmdbtype.Map{}
+		replaceObjects(patch, overrideIP)
+		overrides.Patch(original, patch, overrideIP.Fields)
 	}
-
-	return mergeFile, nil
+	overrides.Remove(original, overrideIP.RemoveFields)
 }
 
-func applyOverride(original mmdbtype.Map, overrideIP *model.IPUpdateRequest) {
+func replaceObjects(original mmdbtype.Map, overrideIP *model.IPUpdateRequest) {
 	// Apply overrides
 	if overrideIP.Continent != nil {
-		original["continent"] = mmdbtype.Map{
-			"code": mmdbtype.String(overrideIP.Continent.Code),
-			"names": func() mmdbtype.Map {
-				names := make(mmdbtype.Map)
-				for lang, name := range overrideIP.Continent.Names {
-					names[mmdbtype.String(lang)] = mmdbtype.String(name)
-				}
-				return names
-			}(),
+		continent := mmdbtype.Map{
+			"code":  mmdbtype.String(overrideIP.Continent.Code),
+			"names": toNames(overrideIP.Continent.Names),
 		}
+		setUint32(continent, "geoname_id", overrideIP.Continent.GeoNameID)
+		original["continent"] = continent
 	}
 
 	if overrideIP.Country != nil {
-		original["country"] = mmdbtype.Map{
-			"iso_code": mmdbtype.String(overrideIP.Country.ISOCode),
-			"names": func() mmdbtype.Map {
-				names := make(mmdbtype.Map)
-				for lang, name := range overrideIP.Country.Names {
-					names[mmdbtype.String(lang)] = mmdbtype.String(name)
-				}
-				return names
-			}(),
+		country := mmdbtype.Map{
+			"iso_code":             mmdbtype.String(overrideIP.Country.ISOCode),
+			"names":                toNames(overrideIP.Country.Names),
 			"is_in_european_union": mmdbtype.Bool(overrideIP.Country.IsInEuropeanUnion),
 		}
+		setUint32(country, "geoname_id", overrideIP.Country.GeoNameID)
+		setUint16(country, "confidence", uint(overrideIP.Country.Confidence))
+		original["country"] = country
 	}
 
 	if overrideIP.Subdivisions != nil {
 		original["subdivisions"] = func() mmdbtype.Slice {
 			subdivisions := make(mmdbtype.Slice, len(overrideIP.Subdivisions))
 			for i, subdivision := range overrideIP.Subdivisions {
-				subdivisions[i] = mmdbtype.Map{
+				value := mmdbtype.Map{
 					"iso_code": mmdbtype.String(subdivision.ISOCode),
-					"names": func() mmdbtype.Map {
-						names := make(mmdbtype.Map)
-						for lang, name := range subdivision.Names {
-							names[mmdbtype.String(lang)] = mmdbtype.String(name)
-						}
-						return names
-					}(),
+					"names":    toNames(subdivision.Names),
 				}
+				setUint32(value, "geoname_id", subdivision.GeoNameID)
+				setUint16(value, "confidence", uint(subdivision.Confidence))
+				subdivisions[i] = value
 			}
 			return subdivisions
 		}()
 	}
 
 	if overrideIP.City != nil {
-		original["city"] = mmdbtype.Map{
-			"names": func() mmdbtype.Map {
-				names := make(mmdbtype.Map)
-				for lang, name := range overrideIP.City.Names {
-					names[mmdbtype.String(lang)] = mmdbtype.String(name)
-				}
-				return names
-			}(),
+		city := mmdbtype.Map{
+			"names": toNames(overrideIP.City.Names),
 		}
+		setUint32(city, "geoname_id", overrideIP.City.GeoNameID)
+		setUint16(city, "confidence", uint(overrideIP.City.Confidence))
+		original["city"] = city
 	}
+
 	if overrideIP.Location != nil {
-		original["location"] = mmdbtype.Map{
+		location := mmdbtype.Map{
 			"latitude":        mmdbtype.Float64(overrideIP.Location.Latitude),
 			"longitude":       mmdbtype.Float64(overrideIP.Location.Longitude),
 			"accuracy_radius": mmdbtype.Uint16(overrideIP.Location.AccuracyRadius),
 			"time_zone":       mmdbtype.String(overrideIP.Location.TimeZone),
 		}
+		setUint16(location, "metro_code", overrideIP.Location.MetroCode)
+		setUint32(location, "population_density", overrideIP.Location.PopulationDensity)
+		setUint32(location, "average_income", overrideIP.Location.AverageIncome)
+		original["location"] = location
 	}
 
 	if overrideIP.Postal != nil {
-		original["postal"] = mmdbtype.Map{
+		postal := mmdbtype.Map{
 			"code": mmdbtype.String(overrideIP.Postal.Code),
 		}
+		setUint16(postal, "confidence", uint(overrideIP.Postal.Confidence))
+		original["postal"] = postal
 	}
 
 	if overrideIP.RepresentedCountry != nil {
-		original["represented_country"] = mmdbtype.Map{
-			"iso_code": mmdbtype.String(overrideIP.RepresentedCountry.ISOCode),
-			"names": func() mmdbtype.Map {
-				names := make(mmdbtype.Map)
-				for lang, name := range overrideIP.RepresentedCountry.Names {
-					names[mmdbtype.String(lang)] = mmdbtype.String(name)
-				}
-				return names
-			}(),
+		representedCountry := mmdbtype.Map{
+			"iso_code":             mmdbtype.String(overrideIP.RepresentedCountry.ISOCode),
+			"names":                toNames(overrideIP.RepresentedCountry.Names),
 			"is_in_european_union": mmdbtype.Bool(overrideIP.RepresentedCountry.IsInEuropeanUnion),
 		}
+		setString(representedCountry, "type", overrideIP.RepresentedCountry.Type)
+		setUint32(representedCountry, "geoname_id", overrideIP.RepresentedCountry.GeoNameID)
+		original["represented_country"] = representedCountry
 	}
 
 	if overrideIP.RegisteredCountry != nil {
-		original["registered_country"] = mmdbtype.Map{
-			"iso_code": mmdbtype.String(overrideIP.RegisteredCountry.ISOCode),
-			"names": func() mmdbtype.Map {
-				names := make(mmdbtype.Map)
-				for lang, name := range overrideIP.RegisteredCountry.Names {
-					names[mmdbtype.String(lang)] = mmdbtype.String(name)
-				}
-				return names
-			}(),
+		registeredCountry := mmdbtype.Map{
+			"iso_code":             mmdbtype.String(overrideIP.RegisteredCountry.ISOCode),
+			"names":                toNames(overrideIP.RegisteredCountry.Names),
 			"is_in_european_union": mmdbtype.Bool(overrideIP.RegisteredCountry.IsInEuropeanUnion),
 		}
+		setUint32(registeredCountry, "geoname_id", overrideIP.RegisteredCountry.GeoNameID)
+		setUint16(registeredCountry, "confidence", uint(overrideIP.RegisteredCountry.Confidence))
+		original["registered_country"] = registeredCountry
 	}
 
 	if overrideIP.Traits != nil {
-		original["traits"] = mmdbtype.Map{
+		traits := mmdbtype.Map{
 			"is_anonymous_proxy":    mmdbtype.Bool(overrideIP.Traits.IsAnonymousProxy),
 			"is_satellite_provider": mmdbtype.Bool(overrideIP.Traits.IsSatelliteProvider),
 			"is_anycast":            mmdbtype.Bool(overrideIP.Traits.IsAnycast),
 		}
+		setBool(traits, "is_anonymous", overrideIP.Traits.IsAnonymous)
+		setBool(traits, "is_anonymous_vpn", overrideIP.Traits.IsAnonymousVPN)
+		setBool(traits, "is_hosting_provider", overrideIP.Traits.IsHostingProvider)
+		setBool(traits, "is_legitimate_proxy", overrideIP.Traits.IsLegitimateProxy)
+		setBool(traits, "is_public_proxy", overrideIP.Traits.IsPublicProxy)
+		setBool(traits, "is_residential_proxy", overrideIP.Traits.IsResidentialProxy)
+		setBool(traits, "is_tor_exit_node", overrideIP.Traits.IsTorExitNode)
+		setUint32(traits, "autonomous_system_number", overrideIP.Traits.AutonomousSystemNumber)
+		setString(traits, "autonomous_system_organization", overrideIP.Traits.AutonomousSystemOrganization)
+		setString(traits, "connection_type", overrideIP.Traits.ConnectionType)
+		setString(traits, "domain", overrideIP.Traits.Domain)
+		setString(traits, "isp", overrideIP.Traits.ISP)
+		setString(traits, "mobile_country_code", overrideIP.Traits.MobileCountryCode)
+		setString(traits, "mobile_network_code", overrideIP.Traits.MobileNetworkCode)
+		setString(traits, "organization", overrideIP.Traits.Organization)
+		setString(traits, "user_type", overrideIP.Traits.UserType)
+		if overrideIP.Traits.StaticIPScore != 0 {
+			traits["static_ip_score"] = mmdbtype.Float64(overrideIP.Traits.StaticIPScore)
+		}
+		original["traits"] = traits
+	}
+}
+
+func toNames(names map[string]string) mmdbtype.Map {
+	values := make(mmdbtype.Map, len(names))
+	for lang, name := range names {
+		values[mmdbtype.String(lang)] = mmdbtype.String(name)
+	}
+	return values
+}
+
+// The set helpers below only write optional fields that carry a value, the
+// same way MaxMind omits them from its own records. The integer widths follow
+// the GeoIP2 database spec.
+
+func setString(m mmdbtype.Map, key string, value string) {
+	if len(value) > 0 {
+		m[mmdbtype.String(key)] = mmdbtype.String(value)
+	}
+}
+
+func setBool(m mmdbtype.Map, key string, value bool) {
+	if value {
+		m[mmdbtype.String(key)] = mmdbtype.Bool(true)
+	}
+}
+
+func setUint16(m mmdbtype.Map, key string, value uint) {
+	if value > 0 {
+		m[mmdbtype.String(key)] = mmdbtype.Uint16(value)
+	}
+}
+
+func setUint32(m mmdbtype.Map, key string, value uint) {
+	if value > 0 {
+		m[mmdbtype.String(key)] = mmdbtype.Uint32(value)
 	}
 }
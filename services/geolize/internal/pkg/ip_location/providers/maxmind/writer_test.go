@@ -0,0 +1,392 @@
+package maxmind
+
+import (
+	"context"
+	"errors"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/logging"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// testRecords are the countries of the test database by network.
+var testRecords = map[string]string{
+	"81.2.69.0/24":   "GB",
+	"89.160.20.0/24": "SE",
+}
+
+// chdirTemp runs the test in a temporary directory, where the writer keeps
+// its data folder, and returns it.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	return dir
+}
+
+// writeTestDatabase writes a City database with the countries of records to
+// path.
+func writeTestDatabase(t *testing.T, path string, records map[string]string) {
+	t.Helper()
+
+	tree, err := mmdbwriter.New(mmdbwriter.Options{DatabaseType: "GeoLite2-City", RecordSize: 28})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cidr, country := range records {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record := mmdbtype.Map{"country": mmdbtype.Map{"iso_code": mmdbtype.String(country)}}
+		if err = tree.Insert(network, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err = tree.WriteTo(f); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testLogger(t *testing.T) logging.Logger {
+	t.Helper()
+
+	logger, err := logging.NewLogger(logging.ZapLoggerType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// openTestHistory opens the override store of the working directory.
+func openTestHistory(t *testing.T) *versionHistoryManager {
+	t.Helper()
+
+	store, err := openOverrideStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return newVersionHistoryManager(store)
+}
+
+// testWriter returns a writer over a new database with testRecords and an
+// empty override store, in a temporary directory.
+func testWriter(t *testing.T) (*Writer, *versionHistoryManager) {
+	t.Helper()
+
+	chdirTemp(t)
+	writeTestDatabase(t, filepath.Join(dbFolder, db), testRecords)
+
+	return startTestWriter(t)
+}
+
+// startTestWriter starts a writer on the data folder of the working directory,
+// as a restart of the service does.
+func startTestWriter(t *testing.T) (*Writer, *versionHistoryManager) {
+	t.Helper()
+
+	history := openTestHistory(t)
+	w, err := NewWriter(testLogger(t), history)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return w, history
+}
+
+// countryOf returns the country of ip in the active database, or empty when
+// it has none.
+func countryOf(t *testing.T, ip string) string {
+	t.Helper()
+
+	reader, err := geoip2.Open(filepath.Join(dbFolder, db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	record, err := reader.City(net.ParseIP(ip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return record.Country.IsoCode
+}
+
+func countryOverride(network, country string) *model.IPUpdateRequest {
+	return &model.IPUpdateRequest{Network: network, Country: &model.Country{ISOCode: country}}
+}
+
+func TestSetVersionKeepsVersionWhenVersionFileFails(t *testing.T) {
+	w, history := testWriter(t)
+	ctx := context.Background()
+
+	version, err := w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{
+		Overrides: []*model.IPUpdateRequest{countryOverride("81.2.69.0/24", "VN")},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	// A directory in place of the version file makes writing it fail.
+	if err = os.Remove(versionFilePath); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(versionFilePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = history.SetVersion("history__1__next"); err == nil {
+		t.Fatal("SetVersion() error = nil, want the version file error")
+	}
+	if got, _ := history.GetVersion(); got != version {
+		t.Fatalf("GetVersion() = %q after a failed SetVersion, want %q", got, version)
+	}
+
+	_, err = w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{
+		Overrides: []*model.IPUpdateRequest{countryOverride("89.160.20.0/24", "VN")},
+	})
+	if err == nil {
+		t.Fatal("BatchUpdate() error = nil, want the version file error")
+	}
+	if got, _ := history.GetVersion(); got != version {
+		t.Fatalf("GetVersion() = %q after a failed BatchUpdate, want %q", got, version)
+	}
+	if got := countryOf(t, "89.160.20.1"); got != "SE" {
+		t.Fatalf("country of the failed override = %q, want SE", got)
+	}
+}
+
+func TestRemoveOverrideWithoutPristineBase(t *testing.T) {
+	w, _ := testWriter(t)
+	ctx := context.Background()
+
+	if _, err := w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{
+		Overrides: []*model.IPUpdateRequest{countryOverride("81.2.69.0/24", "VN")},
+	}); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	// A deployment that wrote its overrides before the pristine copy was
+	// kept has none, and a restart cannot make one from the overridden file.
+	if err := os.RemoveAll(dbBaseFolder); err != nil {
+		t.Fatal(err)
+	}
+	w, _ = startTestWriter(t)
+	if _, err := os.Stat(filepath.Join(dbBaseFolder, db)); !os.IsNotExist(err) {
+		t.Fatalf("pristine copy kept from an overridden database")
+	}
+
+	remove := &model.IPRemoveRequest{Network: "81.2.69.0/24"}
+	if err := w.Remove(ctx, remove); !errors.Is(err, model.ErrBaseUnavailable) {
+		t.Fatalf("Remove() error = %v, want %v", err, model.ErrBaseUnavailable)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "VN" {
+		t.Fatalf("country after a failed Remove() = %q, want VN", got)
+	}
+
+	// Installing a release makes it the base.
+	release := filepath.Join(dbFolder, testRelease+".mmdb")
+	writeTestDatabase(t, release, testRecords)
+	if err := w.Rebase(ctx, release); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "VN" {
+		t.Fatalf("country after Rebase() = %q, want VN", got)
+	}
+
+	if err := w.Remove(ctx, remove); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "GB" {
+		t.Fatalf("country after Remove() = %q, want GB", got)
+	}
+}
+
+func TestRemoveOverrideRestoresOriginalRecord(t *testing.T) {
+	w, history := testWriter(t)
+	ctx := context.Background()
+
+	if _, err := w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{Overrides: []*model.IPUpdateRequest{
+		countryOverride("81.2.69.0/24", "VN"),
+		countryOverride("89.160.20.0/24", "FR"),
+	}}); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+	if _, err := w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{
+		Overrides: []*model.IPUpdateRequest{countryOverride("81.2.69.0/24", "US")},
+	}); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	// The target is matched by its network, however it is spelled.
+	if err := w.Remove(ctx, &model.IPRemoveRequest{Network: "81.2.69.7/24", Reason: "test"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "GB" {
+		t.Fatalf("country after Remove() = %q, want the original GB", got)
+	}
+	if got := countryOf(t, "89.160.20.1"); got != "FR" {
+		t.Fatalf("country of another override = %q, want FR", got)
+	}
+
+	audits, err := history.Audits(ctx)
+	if err != nil {
+		t.Fatalf("Audits() error = %v", err)
+	}
+	last := audits[len(audits)-1]
+	if last.Kind != changeKindRemove || last.Network != "81.2.69.0/24" || last.Reason != "test" {
+		t.Fatalf("last audit = %+v, want the removal of 81.2.69.0/24", last)
+	}
+	if version, _ := history.GetVersion(); version != last.Version {
+		t.Fatalf("GetVersion() = %q, want the version of the removal %q", version, last.Version)
+	}
+
+	err = w.Remove(ctx, &model.IPRemoveRequest{Network: "81.2.69.0/24"})
+	if !errors.Is(err, model.ErrOverrideNotFound) {
+		t.Fatalf("second Remove() error = %v, want %v", err, model.ErrOverrideNotFound)
+	}
+	err = w.Remove(ctx, &model.IPRemoveRequest{IP: "not an ip"})
+	if !errors.Is(err, model.ErrInvalidIP) {
+		t.Fatalf("Remove() of an invalid target error = %v, want %v", err, model.ErrInvalidIP)
+	}
+
+	// A restart does not bring the removed override back.
+	startTestWriter(t)
+	if got := countryOf(t, "81.2.69.1"); got != "GB" {
+		t.Fatalf("country after a restart = %q, want GB", got)
+	}
+}
+
+func TestRollbackToVersion(t *testing.T) {
+	w, history := testWriter(t)
+	ctx := context.Background()
+
+	batch := func(overrides ...*model.IPUpdateRequest) string {
+		t.Helper()
+
+		version, err := w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{Overrides: overrides})
+		if err != nil {
+			t.Fatalf("BatchUpdate() error = %v", err)
+		}
+		return version
+	}
+
+	target := batch(countryOverride("81.2.69.0/24", "VN"))
+	batch(countryOverride("89.160.20.0/24", "FR"))
+	if err := w.Remove(ctx, &model.IPRemoveRequest{Network: "81.2.69.0/24"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	version, err := w.Rollback(ctx, &model.RollbackRequest{Version: target, Reason: "test"})
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "VN" {
+		t.Fatalf("country of the removed override = %q, want VN back", got)
+	}
+	if got := countryOf(t, "89.160.20.1"); got != "SE" {
+		t.Fatalf("country of a later override = %q, want the original SE", got)
+	}
+	if current, _ := history.GetVersion(); current != version {
+		t.Fatalf("GetVersion() = %q, want the rollback %q", current, version)
+	}
+
+	changes, err := history.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(changes) != 2 || changes[0].Name != target || changes[1].Kind != changeKindRollback {
+		t.Fatalf("GetAll() = %+v, want the target and the rollback only", changes)
+	}
+
+	if again, err := w.Rollback(ctx, &model.RollbackRequest{Version: version}); err != nil || again != version {
+		t.Fatalf("Rollback() to the current version = %q, %v, want %q", again, err, version)
+	}
+	if _, err = w.Rollback(ctx, &model.RollbackRequest{Version: "history__1__unknown"}); !errors.Is(err, model.ErrVersionNotFound) {
+		t.Fatalf("Rollback() error = %v, want %v", err, model.ErrVersionNotFound)
+	}
+
+	// A restart rebuilds nothing that was rolled back.
+	startTestWriter(t)
+	if got := countryOf(t, "89.160.20.1"); got != "SE" {
+		t.Fatalf("country after a restart = %q, want SE", got)
+	}
+}
+
+func TestBatchUpdateIsAllOrNothing(t *testing.T) {
+	w, history := testWriter(t)
+	ctx := context.Background()
+
+	_, err := w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{Overrides: []*model.IPUpdateRequest{
+		countryOverride("81.2.69.0/24", "VN"),
+		countryOverride("not a network", "VN"),
+	}})
+	if !errors.Is(err, model.ErrInvalidIP) {
+		t.Fatalf("BatchUpdate() error = %v, want %v", err, model.ErrInvalidIP)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "GB" {
+		t.Fatalf("country after a failed BatchUpdate() = %q, want GB", got)
+	}
+	if changes, _ := history.GetAll(ctx); len(changes) != 0 {
+		t.Fatalf("GetAll() = %+v after a failed BatchUpdate(), want nothing recorded", changes)
+	}
+
+	version, err := w.BatchUpdate(ctx, &model.IPBatchUpdateRequest{
+		Overrides: []*model.IPUpdateRequest{
+			countryOverride("81.2.69.0/24", "VN"),
+			countryOverride("89.160.20.0/24", "FR"),
+			countryOverride("81.2.69.0/24", "US"),
+		},
+		Reason: "test",
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+	if got := countryOf(t, "81.2.69.1"); got != "US" {
+		t.Fatalf("country = %q, want US of the later override of the batch", got)
+	}
+	if got := countryOf(t, "89.160.20.1"); got != "FR" {
+		t.Fatalf("country = %q, want FR", got)
+	}
+
+	changes, err := history.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].Name != version || len(changes[0].Overrides) != 3 {
+		t.Fatalf("GetAll() = %+v, want one change %q with the three overrides", changes, version)
+	}
+	audits, _ := history.Audits(ctx)
+	if len(audits) != 2 {
+		t.Fatalf("Audits() = %+v, want one entry per network", audits)
+	}
+	if current, _ := history.GetVersion(); current != version {
+		t.Fatalf("GetVersion() = %q, want %q", current, version)
+	}
+}
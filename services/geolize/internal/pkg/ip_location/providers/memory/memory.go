@@ -0,0 +1,477 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/overrides"
+	"maps"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Name identifies the in-memory provider in IPResult.Provider.
+const Name = "memory"
+
+// Source is a read-only dataset that the in-memory provider layers its
+// overrides on.
+type Source interface {
+	// Lookup returns the record for addr and the network it was matched on.
+	// found is false when the dataset has no record for addr.
+	Lookup(addr netip.Addr) (result *model.IPResult, network netip.Prefix, found bool, err error)
+	// Version identifies the loaded dataset.
+	Version() string
+}
+
+type override struct {
+	network netip.Prefix
+	update  *model.IPUpdateRequest
+	// version is the version the override produced.
+	version   string
+	createdAt time.Time
+}
+
+// Memory is a provider that keeps overrides in memory, on top of an optional
+// read-only Source. Overrides are not persisted, which makes it suitable for
+// tests and for evaluating datasets that have no writer of their own.
+type Memory struct {
+	name   string
+	source Source
+
+	mu        sync.RWMutex
+	overrides []override
+	// revision counts the changes made to overrides, so the version never
+	// goes back to an earlier value when one is removed.
+	revision int
+	// changes is the change log of the overrides.
+	changes []*model.IPOverrideChange
+	// snapshots holds the overrides as of every version that can be rolled
+	// back to.
+	snapshots map[string]snapshot
+}
+
+// snapshot is the state of the overrides as of a version.
+type snapshot struct {
+	revision  int
+	overrides []override
+	// changes is the length of the change log at the time.
+	changes int
+}
+
+// New returns a provider reporting itself as name. source may be nil, in
+// which case only overrides are ever found.
+func New(name string, source Source) *Memory {
+	m := &Memory{
+		name:      name,
+		source:    source,
+		snapshots: make(map[string]snapshot),
+	}
+	m.snapshot()
+	return m
+}
+
+// Version changes with every Update and Remove, and with the dataset when there is one.
+func (m *Memory) Version() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.version()
+}
+
+func (m *Memory) version() string {
+	base := m.name
+	if m.source != nil {
+		base = m.source.Version()
+	}
+	return fmt.Sprintf("%s+%d", base, m.revision)
+}
+
+func (m *Memory) Lookup(ctx context.Context, request *model.IPLookupRequest) ([]*model.IPResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	version := m.version()
+	result := make([]*model.IPResult, 0, len(request.IPs))
+	for _, ip := range request.IPs {
+		result = append(result, m.lookup(ip, version))
+	}
+	return result, nil
+}
+
+func (m *Memory) lookup(ip string, version string) *model.IPResult {
+	addr, err := ipaddr.Parse(ip)
+	if err != nil {
+		return &model.IPResult{IP: ip, DBVersion: version, Status: model.LookupStatusInvalidIP, Message: err.Error(), Provider: m.name}
+	}
+
+	result := &model.IPResult{}
+	network := netip.PrefixFrom(addr, addr.BitLen())
+	found := false
+
+	if m.source != nil {
+		record, matched, ok, err := m.source.Lookup(addr)
+		if err != nil {
+			return &model.IPResult{IP: ip, DBVersion: version, Status: model.LookupStatusInternal, Message: err.Error(), Provider: m.name}
+		}
+		if ok {
+			// Copy so overrides never modify the dataset's record.
+			*result = *record
+			found = true
+		}
+		if matched.IsValid() {
+			network = matched
+		}
+	}
+
+	// Overrides are layered in the order they were made, so a later override
+	// wins over an earlier one for the addresses they share. The most specific
+	// network involved is reported.
+	for _, o := range m.overrides {
+		if !o.network.Contains(addr) {
+			continue
+		}
+		if !found || o.network.Bits() > network.Bits() {
+			network = o.network
+		}
+		applyUpdate(result, o.update)
+		found = true
+	}
+
+	result.IP = ip
+	result.DBVersion = version
+	result.Provider = m.name
+	result.Network = network.String()
+	if !found {
+		result.Status = model.LookupStatusNotFound
+		result.Message = "no record found for IP"
+		return result
+	}
+
+	result.Found = true
+	result.Status = model.LookupStatusOK
+	traits := model.Traits{}
+	if result.Traits != nil {
+		traits = *result.Traits
+	}
+	traits.Network = result.Network
+	result.Traits = &traits
+
+	return result
+}
+
+// applyUpdate writes update into result the way the MaxMind writer writes it
+// into its records.
+func applyUpdate(result *model.IPResult, update *model.IPUpdateRequest) {
+	if len(update.Fields) == 0 {
+		replaceObjects(result, update)
+	}
+	if len(update.Fields) > 0 || len(update.RemoveFields) > 0 {
+		patch(result, update)
+	}
+}
+
+// patch applies the field paths of a partial override to the JSON form of
+// result. result is left untouched if it cannot be converted.
+func patch(result *model.IPResult, update *model.IPUpdateRequest) {
+	record := map[string]any{}
+	data, err := json.Marshal(result)
+	if err == nil {
+		err = json.Unmarshal(data, &record)
+	}
+	if err == nil {
+		err = overrides.Apply(record, update)
+	}
+	if err == nil {
+		data, err = json.Marshal(record)
+	}
+	patched := model.IPResult{}
+	if err == nil {
+		err = json.Unmarshal(data, &patched)
+	}
+	if err != nil {
+		return
+	}
+
+	*result = patched
+}
+
+// replaceObjects replaces every sub-object of result that update sets, with
+// copies so that changing a result never changes the stored override. An
+// empty but non-nil Subdivisions clears them, as the MaxMind writer does.
+func replaceObjects(result *model.IPResult, update *model.IPUpdateRequest) {
+	if update.Continent != nil {
+		continent := *update.Continent
+		continent.Names = maps.Clone(continent.Names)
+		result.Continent = &continent
+	}
+	if update.Country != nil {
+		country := *update.Country
+		country.Names = maps.Clone(country.Names)
+		result.Country = &country
+	}
+	if update.Location != nil {
+		location := *update.Location
+		result.Location = &location
+	}
+	if update.Subdivisions != nil {
+		result.Subdivisions = make([]*model.Subdivision, len(update.Subdivisions))
+		for i, s := range update.Subdivisions {
+			subdivision := *s
+			subdivision.Names = maps.Clone(subdivision.Names)
+			result.Subdivisions[i] = &subdivision
+		}
+	}
+	if update.Postal != nil {
+		postal := *update.Postal
+		result.Postal = &postal
+	}
+	if update.City != nil {
+		city := *update.City
+		city.Names = maps.Clone(city.Names)
+		result.City = &city
+	}
+	if update.RepresentedCountry != nil {
+		country := *update.RepresentedCountry
+		country.Names = maps.Clone(country.Names)
+		result.RepresentedCountry = &country
+	}
+	if update.RegisteredCountry != nil {
+		country := *update.RegisteredCountry
+		country.Names = maps.Clone(country.Names)
+		result.RegisteredCountry = &country
+	}
+	if update.Traits != nil {
+		traits := *update.Traits
+		result.Traits = &traits
+	}
+}
+
+// Check reports whether Update would accept request, without applying it.
+func (m *Memory) Check(request *model.IPUpdateRequest) error {
+	_, err := ipaddr.OverrideNetwork(request)
+	return err
+}
+
+// Checkpoint returns the current version, which Rollback takes the overrides
+// back to.
+func (m *Memory) Checkpoint() (string, error) {
+	return m.Version(), nil
+}
+
+func (m *Memory) Update(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	network, err := ipaddr.OverrideNetwork(request)
+	if err != nil {
+		return "", err
+	}
+
+	request.Network = network.String()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := m.record(network)
+	m.revision++
+	m.overrides = append(m.overrides, override{
+		network:   network,
+		update:    request,
+		version:   m.version(),
+		createdAt: time.Now(),
+	})
+	m.log(model.OverrideChangeKindOverride, network, before, request.Author, request.Reason, request.Ticket)
+	m.snapshot()
+
+	return m.version(), nil
+}
+
+// BatchUpdate adds every override of request at once, under a single
+// version. Nothing is added when one of them has an invalid target.
+func (m *Memory) BatchUpdate(ctx context.Context, request *model.IPBatchUpdateRequest) (string, error) {
+	networks := make([]netip.Prefix, len(request.Overrides))
+	for i, update := range request.Overrides {
+		network, err := ipaddr.OverrideNetwork(update)
+		if err != nil {
+			return "", fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		networks[i] = network
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var touched []netip.Prefix
+	befores := make(map[netip.Prefix]map[string]any)
+	for _, network := range networks {
+		if _, ok := befores[network]; !ok {
+			touched = append(touched, network)
+			befores[network] = m.record(network)
+		}
+	}
+
+	m.revision++
+	now := time.Now()
+	for i, update := range request.Overrides {
+		update.Network = networks[i].String()
+		m.overrides = append(m.overrides, override{
+			network:   networks[i],
+			update:    update,
+			version:   m.version(),
+			createdAt: now,
+		})
+	}
+	for _, network := range touched {
+		m.log(model.OverrideChangeKindOverride, network, befores[network], request.Author, request.Reason, request.Ticket)
+	}
+	m.snapshot()
+
+	return m.version(), nil
+}
+
+// Remove drops every override made for exactly the request's network.
+func (m *Memory) Remove(ctx context.Context, request *model.IPRemoveRequest) error {
+	network, err := ipaddr.OverrideNetwork(&model.IPUpdateRequest{IP: request.IP, Network: request.Network})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := m.record(network)
+	n := len(m.overrides)
+	m.overrides = slices.DeleteFunc(m.overrides, func(o override) bool {
+		return o.network == network
+	})
+	if len(m.overrides) == n {
+		return fmt.Errorf("%w for %s", model.ErrOverrideNotFound, network)
+	}
+	m.revision++
+	m.log(model.OverrideChangeKindRemove, network, before, request.Author, request.Reason, request.Ticket)
+	m.snapshot()
+
+	return nil
+}
+
+// Rollback restores the overrides as of the request's version. The changes
+// made after it stay in the change log, marked as reverted, and can no longer
+// be rolled back to.
+func (m *Memory) Rollback(ctx context.Context, request *model.RollbackRequest) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target, ok := m.snapshots[request.Version]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", model.ErrVersionNotFound, request.Version)
+	}
+	if target.revision == m.revision {
+		return m.version(), nil
+	}
+
+	var networks []netip.Prefix
+	for _, c := range m.changes[target.changes:] {
+		if c.Reverted {
+			continue
+		}
+		c.Reverted = true
+		if network, err := netip.ParsePrefix(c.Network); err == nil && !slices.Contains(networks, network) {
+			networks = append(networks, network)
+		}
+	}
+	befores := make([]map[string]any, len(networks))
+	for i, network := range networks {
+		befores[i] = m.record(network)
+	}
+
+	for version, s := range m.snapshots {
+		if s.revision > target.revision {
+			delete(m.snapshots, version)
+		}
+	}
+
+	m.overrides = slices.Clone(target.overrides)
+	m.revision++
+	for i, network := range networks {
+		m.log(model.OverrideChangeKindRollback, network, befores[i], request.Author, request.Reason, request.Ticket)
+	}
+	m.snapshot()
+
+	return m.version(), nil
+}
+
+func (m *Memory) ListOverrides(ctx context.Context, request *model.IPOverrideListRequest) (*model.IPOverrideList, error) {
+	m.mu.RLock()
+	changes := make([]overrides.Change, 0, len(m.overrides))
+	for _, o := range m.overrides {
+		changes = append(changes, overrides.Change{Override: o.update, Version: o.version, CreatedAt: o.createdAt})
+	}
+	m.mu.RUnlock()
+
+	return overrides.List(changes, request)
+}
+
+func (m *Memory) OverrideHistory(ctx context.Context, request *model.IPOverrideHistoryRequest) ([]*model.IPOverrideChange, error) {
+	m.mu.RLock()
+	changes := make([]*model.IPOverrideChange, 0, len(m.changes))
+	for _, c := range m.changes {
+		copied := *c
+		changes = append(changes, &copied)
+	}
+	m.mu.RUnlock()
+
+	return overrides.History(changes, request)
+}
+
+// snapshot keeps the overrides as of the current version. m.mu must be held.
+func (m *Memory) snapshot() {
+	m.snapshots[m.version()] = snapshot{
+		revision:  m.revision,
+		overrides: slices.Clone(m.overrides),
+		changes:   len(m.changes),
+	}
+}
+
+// log records a change of the record of network, which was before until
+// now. m.mu must be held.
+func (m *Memory) log(kind string, network netip.Prefix, before map[string]any, author, reason, ticket string) {
+	m.changes = append(m.changes, &model.IPOverrideChange{
+		Version:   m.version(),
+		Kind:      kind,
+		Network:   network.String(),
+		Author:    author,
+		Reason:    reason,
+		Ticket:    ticket,
+		CreatedAt: time.Now(),
+		Before:    before,
+		After:     m.record(network),
+	})
+}
+
+// record returns the data a lookup reports for the first address of network,
+// without the lookup metadata. m.mu must be held.
+func (m *Memory) record(network netip.Prefix) map[string]any {
+	result := m.lookup(network.Addr().String(), "")
+
+	record := map[string]any{}
+	data, err := json.Marshal(result)
+	if err == nil {
+		err = json.Unmarshal(data, &record)
+	}
+	if err != nil {
+		return map[string]any{}
+	}
+
+	for _, key := range []string{"ip", "db_version", "found", "network", "status", "message", "provider", "sources"} {
+		delete(record, key)
+	}
+	if traits, ok := record["traits"].(map[string]any); ok {
+		delete(traits, "network")
+		if len(traits) == 0 {
+			delete(record, "traits")
+		}
+	}
+
+	return record
+}
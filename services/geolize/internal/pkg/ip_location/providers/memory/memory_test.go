@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"net/netip"
+	"testing"
+)
+
+func lookup(t *testing.T, m *Memory, ip string) *model.IPResult {
+	t.Helper()
+
+	results, err := m.Lookup(context.Background(), &model.IPLookupRequest{IPs: []string{ip}})
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	return results[0]
+}
+
+func update(t *testing.T, m *Memory, request *model.IPUpdateRequest) string {
+	t.Helper()
+
+	version, err := m.Update(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	return version
+}
+
+func TestEmptySubdivisionsClear(t *testing.T) {
+	m := New(Name, nil)
+	update(t, m, &model.IPUpdateRequest{
+		Network:      "81.2.69.0/24",
+		Country:      &model.Country{ISOCode: "VN"},
+		Subdivisions: []*model.Subdivision{{ISOCode: "HN"}},
+	})
+	update(t, m, &model.IPUpdateRequest{Network: "81.2.69.0/24", Subdivisions: []*model.Subdivision{}})
+
+	result := lookup(t, m, "81.2.69.142")
+	if len(result.Subdivisions) != 0 {
+		t.Fatalf("subdivisions = %+v, want none", result.Subdivisions)
+	}
+	if result.Country == nil || result.Country.ISOCode != "VN" {
+		t.Fatalf("country = %+v, want VN", result.Country)
+	}
+}
+
+func TestResultsDoNotShareOverrides(t *testing.T) {
+	m := New(Name, nil)
+	update(t, m, &model.IPUpdateRequest{
+		IP:           "81.2.69.142",
+		Country:      &model.Country{ISOCode: "VN", Names: map[string]string{"en": "Vietnam"}},
+		Subdivisions: []*model.Subdivision{{ISOCode: "HN", Names: map[string]string{"en": "Hanoi"}}},
+		Location:     &model.Location{Latitude: 21, Longitude: 105.8},
+	})
+
+	result := lookup(t, m, "81.2.69.142")
+	result.Country.ISOCode = "FR"
+	result.Country.Names["en"] = "France"
+	result.Subdivisions[0].Names["en"] = "Paris"
+	result.Location.Latitude = 48.8
+
+	result = lookup(t, m, "81.2.69.142")
+	if result.Country.ISOCode != "VN" || result.Country.Names["en"] != "Vietnam" {
+		t.Fatalf("country = %+v, changed through an earlier result", result.Country)
+	}
+	if result.Subdivisions[0].Names["en"] != "Hanoi" {
+		t.Fatalf("subdivision = %+v, changed through an earlier result", result.Subdivisions[0])
+	}
+	if result.Location.Latitude != 21 {
+		t.Fatalf("location = %+v, changed through an earlier result", result.Location)
+	}
+}
+
+// staticSource holds one record for 81.2.69.0/24.
+type staticSource struct{}
+
+func (staticSource) Lookup(addr netip.Addr) (*model.IPResult, netip.Prefix, bool, error) {
+	network := netip.MustParsePrefix("81.2.69.0/24")
+	if !network.Contains(addr) {
+		return nil, netip.Prefix{}, false, nil
+	}
+	return &model.IPResult{Country: &model.Country{ISOCode: "GB"}}, network, true, nil
+}
+
+func (staticSource) Version() string {
+	return "static@1"
+}
+
+func countryOf(t *testing.T, m *Memory, ip string) string {
+	t.Helper()
+
+	result := lookup(t, m, ip)
+	if result.Country == nil {
+		return ""
+	}
+	return result.Country.ISOCode
+}
+
+func TestRemoveRestoresSourceRecord(t *testing.T) {
+	m := New(Name, staticSource{})
+	before := m.Version()
+	update(t, m, &model.IPUpdateRequest{Network: "81.2.69.0/24", Country: &model.Country{ISOCode: "VN"}})
+	update(t, m, &model.IPUpdateRequest{IP: "81.2.69.142", Country: &model.Country{ISOCode: "FR"}})
+
+	if err := m.Remove(context.Background(), &model.IPRemoveRequest{Network: "81.2.69.0/24"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got := countryOf(t, m, "81.2.69.1"); got != "GB" {
+		t.Fatalf("country after Remove() = %q, want GB", got)
+	}
+	if got := countryOf(t, m, "81.2.69.142"); got != "FR" {
+		t.Fatalf("country of another target = %q, want FR", got)
+	}
+	if m.Version() == before {
+		t.Fatalf("Version() = %q, unchanged by Remove()", before)
+	}
+
+	err := m.Remove(context.Background(), &model.IPRemoveRequest{Network: "81.2.69.0/24"})
+	if !errors.Is(err, model.ErrOverrideNotFound) {
+		t.Fatalf("second Remove() error = %v, want %v", err, model.ErrOverrideNotFound)
+	}
+}
+
+func TestBatchUpdateIsAllOrNothing(t *testing.T) {
+	m := New(Name, staticSource{})
+	before := m.Version()
+
+	_, err := m.BatchUpdate(context.Background(), &model.IPBatchUpdateRequest{Overrides: []*model.IPUpdateRequest{
+		{Network: "81.2.69.0/24", Country: &model.Country{ISOCode: "VN"}},
+		{IP: "not an ip", Country: &model.Country{ISOCode: "VN"}},
+	}})
+	if !errors.Is(err, model.ErrInvalidIP) {
+		t.Fatalf("BatchUpdate() error = %v, want %v", err, model.ErrInvalidIP)
+	}
+	if got := countryOf(t, m, "81.2.69.1"); got != "GB" {
+		t.Fatalf("country after a failed BatchUpdate() = %q, want GB", got)
+	}
+	if m.Version() != before {
+		t.Fatalf("Version() = %q after a failed BatchUpdate(), want %q", m.Version(), before)
+	}
+}
+
+func TestRollbackRestoresVersion(t *testing.T) {
+	m := New(Name, staticSource{})
+	ctx := context.Background()
+
+	version := update(t, m, &model.IPUpdateRequest{Network: "81.2.69.0/24", Country: &model.Country{ISOCode: "VN"}})
+	update(t, m, &model.IPUpdateRequest{Network: "81.2.69.0/24", Country: &model.Country{ISOCode: "FR"}})
+	if err := m.Remove(ctx, &model.IPRemoveRequest{Network: "81.2.69.0/24"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	rolledBack, err := m.Rollback(ctx, &model.RollbackRequest{Version: version, Reason: "test"})
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if rolledBack == version {
+		t.Fatalf("Rollback() = %q, want a new version", rolledBack)
+	}
+	if got := countryOf(t, m, "81.2.69.1"); got != "VN" {
+		t.Fatalf("country after Rollback() = %q, want VN", got)
+	}
+
+	changes, err := m.OverrideHistory(ctx, &model.IPOverrideHistoryRequest{Network: "81.2.69.0/24"})
+	if err != nil {
+		t.Fatalf("OverrideHistory() error = %v", err)
+	}
+	var reverted int
+	for _, c := range changes {
+		if c.Reverted {
+			reverted++
+		}
+	}
+	if reverted != 2 {
+		t.Fatalf("%d changes marked as reverted, want the override and the removal", reverted)
+	}
+
+	if _, err = m.Rollback(ctx, &model.RollbackRequest{Version: "unknown"}); !errors.Is(err, model.ErrVersionNotFound) {
+		t.Fatalf("Rollback() error = %v, want %v", err, model.ErrVersionNotFound)
+	}
+}
@@ -0,0 +1,167 @@
+package iplocation
+
+import (
+	"context"
+	"encoding/json"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/conf"
+	"geolize/utilities/logging"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	redisEnable, _        = conf.GetBool("redis", "enable", false)
+	redisAddr, _          = conf.GetString("redis", "addr", "localhost:6379")
+	redisPassword, _      = conf.GetString("redis", "password", "")
+	redisDB, _            = conf.GetInt32("redis", "db", 0)
+	redisKeyPrefix, _     = conf.GetString("redis", "key_prefix", "geolize:lookup")
+	redisTTL, _           = conf.GetDuration("redis", "ttl", time.Hour)
+	redisTimeout, _       = conf.GetDuration("redis", "timeout", 50*time.Millisecond)
+	redisRetryInterval, _ = conf.GetDuration("redis", "retry_interval", 30*time.Second)
+)
+
+// versioned is implemented by providers whose results depend on a database
+// version. The Redis cache needs it to keep overrides and reloads from ever
+// serving stale entries, so Version must change whenever any data merged into
+// a result does, including the optional MaxMind editions.
+type versioned interface {
+	Version() string
+}
+
+// redisCache is an IPGeolocate shared by every replica. Lookups are served
+// from Redis when possible and only the misses reach the wrapped provider.
+// When Redis is unreachable the cache is bypassed for retryInterval so a
+// Redis outage costs at most one timeout per interval.
+type redisCache struct {
+	IPGeolocate
+	logger        logging.Logger
+	client        *redis.Client
+	version       versioned
+	prefix        string
+	ttl           time.Duration
+	retryInterval time.Duration
+	// disabledUntil is the unix nano time until which Redis is bypassed.
+	disabledUntil atomic.Int64
+}
+
+func newRedisCache(logger logging.Logger, provider string, next IPGeolocate) IPGeolocate {
+	version, ok := next.(versioned)
+	if !ok {
+		logger.Warn(context.Background(), "Provider does not report a database version, Redis cache is disabled")
+		return next
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         redisAddr,
+		Password:     redisPassword,
+		DB:           int(redisDB),
+		DialTimeout:  redisTimeout,
+		ReadTimeout:  redisTimeout,
+		WriteTimeout: redisTimeout,
+	})
+
+	cache := &redisCache{
+		IPGeolocate:   next,
+		logger:        logger,
+		client:        client,
+		version:       version,
+		prefix:        redisKeyPrefix + ":" + provider,
+		ttl:           redisTTL,
+		retryInterval: redisRetryInterval,
+	}
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		logger.Warn(context.Background(), "Redis is unreachable, lookups fall back to the provider",
+			append(logging.NewError(err), logging.NewKeyVal("addr", redisAddr))...)
+		cache.disable()
+	} else {
+		logger.Info(context.Background(), "Redis lookup cache enabled", logging.NewKeyVal("addr", redisAddr))
+	}
+
+	return cache
+}
+
+func (c *redisCache) key(version string, fields []string, ip string) string {
+	if len(fields) > 1 {
+		fields = slices.Clone(fields)
+		slices.Sort(fields)
+	}
+	return c.prefix + ":" + version + ":" + strings.Join(fields, ",") + ":" + ip
+}
+
+func (c *redisCache) available() bool {
+	return time.Now().UnixNano() >= c.disabledUntil.Load()
+}
+
+func (c *redisCache) disable() {
+	c.disabledUntil.Store(time.Now().Add(c.retryInterval).UnixNano())
+}
+
+func (c *redisCache) Lookup(ctx context.Context, request *model.IPLookupRequest) ([]*model.IPResult, error) {
+	if !c.available() {
+		return c.IPGeolocate.Lookup(ctx, request)
+	}
+
+	version := c.version.Version()
+	keys := make([]string, len(request.IPs))
+	for i, ip := range request.IPs {
+		keys[i] = c.key(version, request.Fields, ip)
+	}
+
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		c.logger.Warn(ctx, "Redis lookup failed, falling back to the provider", logging.NewError(err)...)
+		c.disable()
+		return c.IPGeolocate.Lookup(ctx, request)
+	}
+
+	result := make([]*model.IPResult, len(request.IPs))
+	var missIPs []string
+	var missIndexes []int
+	for i, value := range values {
+		if raw, ok := value.(string); ok {
+			cached := &model.IPResult{}
+			if err = json.Unmarshal([]byte(raw), cached); err == nil {
+				result[i] = cached
+				continue
+			}
+		}
+		missIPs = append(missIPs, request.IPs[i])
+		missIndexes = append(missIndexes, i)
+	}
+
+	if len(missIPs) == 0 {
+		return result, nil
+	}
+
+	misses, err := c.IPGeolocate.Lookup(ctx, &model.IPLookupRequest{IPs: missIPs, Fields: request.Fields})
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := c.client.Pipeline()
+	for i, miss := range misses {
+		result[missIndexes[i]] = miss
+		if miss.Status == model.LookupStatusInternal {
+			continue
+		}
+		data, err := json.Marshal(miss)
+		if err != nil {
+			continue
+		}
+		// Store under the version the keys were read with; a result read
+		// after a reload then lands under a version nobody reads any more.
+		pipe.Set(ctx, keys[missIndexes[i]], data, c.ttl)
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		c.logger.Warn(ctx, "Failed to store lookups in Redis", logging.NewError(err)...)
+		c.disable()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,322 @@
+package iplocation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/utilities/logging"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRedis is a Redis stand-in that speaks just enough RESP2 for the cache:
+// PING, MGET and SET. Other commands, such as the HELLO and CLIENT of the
+// connection handshake, are answered with an error as an old server would.
+type fakeRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	r := &fakeRedis{values: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return r, listener.Addr().String()
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	in := bufio.NewReader(conn)
+	out := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(in)
+		if err != nil {
+			return
+		}
+		r.reply(out, args)
+		if in.Buffered() == 0 {
+			if err = out.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (r *fakeRedis) reply(out *bufio.Writer, args []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		out.WriteString("+PONG\r\n")
+	case "MGET":
+		fmt.Fprintf(out, "*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			if value, ok := r.values[key]; ok {
+				fmt.Fprintf(out, "$%d\r\n%s\r\n", len(value), value)
+			} else {
+				out.WriteString("$-1\r\n")
+			}
+		}
+	case "SET":
+		r.values[args[1]] = args[2]
+		out.WriteString("+OK\r\n")
+	default:
+		fmt.Fprintf(out, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func (r *fakeRedis) keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]string, 0, len(r.values))
+	for key := range r.values {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings.
+func readCommand(in *bufio.Reader) ([]string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("malformed command %q", line)
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if line, err = in.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("malformed argument %q", line)
+		}
+		arg := make([]byte, size+2)
+		if _, err = io.ReadFull(in, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+// countingProvider answers every lookup itself and counts the IPs it was
+// asked for. Like MaxMind with an edition loaded, its Version has more in it
+// than the DBVersion of its results.
+type countingProvider struct {
+	IPGeolocate
+	mu      sync.Mutex
+	version string
+	lookups int
+	// failing is looked up with an internal error.
+	failing string
+}
+
+func (p *countingProvider) Version() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.version + "+asn@1700000000"
+}
+
+func (p *countingProvider) Lookup(ctx context.Context, request *model.IPLookupRequest) ([]*model.IPResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	results := make([]*model.IPResult, len(request.IPs))
+	for i, ip := range request.IPs {
+		p.lookups++
+		if ip == p.failing {
+			results[i] = &model.IPResult{IP: ip, Status: model.LookupStatusInternal}
+			continue
+		}
+		results[i] = &model.IPResult{
+			IP:        ip,
+			DBVersion: p.version,
+			Found:     true,
+			Status:    model.LookupStatusOK,
+			Country:   &model.Country{ISOCode: "VN"},
+		}
+	}
+	return results, nil
+}
+
+func (p *countingProvider) setVersion(version string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.version = version
+}
+
+func (p *countingProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lookups
+}
+
+func testRedisCache(t *testing.T, provider *countingProvider) (IPGeolocate, *fakeRedis) {
+	t.Helper()
+
+	r, addr := newFakeRedis(t)
+	return newTestRedisCache(t, addr, provider), r
+}
+
+// newTestRedisCache returns the Redis cache of provider on the server at addr.
+func newTestRedisCache(t *testing.T, addr string, provider *countingProvider) IPGeolocate {
+	t.Helper()
+
+	previous := redisAddr
+	redisAddr = addr
+	t.Cleanup(func() { redisAddr = previous })
+
+	logger, err := logging.NewLogger(logging.ZapLoggerType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newRedisCache(logger, "test", provider)
+	if _, ok := cache.(*redisCache); !ok {
+		t.Fatalf("newRedisCache() = %T, want *redisCache", cache)
+	}
+	return cache
+}
+
+func lookup(t *testing.T, cache IPGeolocate, ips ...string) []*model.IPResult {
+	t.Helper()
+
+	results, err := cache.Lookup(context.Background(), &model.IPLookupRequest{IPs: ips, Fields: []string{"country"}})
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(results) != len(ips) {
+		t.Fatalf("Lookup() returned %d results, want %d", len(results), len(ips))
+	}
+	return results
+}
+
+func TestRedisCacheServesMissesFromRedis(t *testing.T) {
+	provider := &countingProvider{version: "GeoLite2-City.mmdb@1"}
+	cache, r := testRedisCache(t, provider)
+
+	lookup(t, cache, "81.2.69.142", "2001:db8::1")
+	if got := provider.count(); got != 2 {
+		t.Fatalf("provider looked up %d IPs, want 2", got)
+	}
+	if got := len(r.keys()); got != 2 {
+		t.Fatalf("Redis holds %d keys, want 2: %q", got, r.keys())
+	}
+
+	results := lookup(t, cache, "81.2.69.142", "2001:db8::1")
+	if got := provider.count(); got != 2 {
+		t.Fatalf("provider looked up %d IPs, want the lookups served from Redis", got)
+	}
+	for _, result := range results {
+		if result.Country == nil || result.Country.ISOCode != "VN" {
+			t.Fatalf("cached result = %+v, want country VN", result)
+		}
+	}
+}
+
+func TestRedisCacheMissesAfterVersionChange(t *testing.T) {
+	provider := &countingProvider{version: "GeoLite2-City.mmdb@1"}
+	cache, _ := testRedisCache(t, provider)
+
+	lookup(t, cache, "81.2.69.142")
+	provider.setVersion("GeoLite2-City.mmdb@2")
+
+	results := lookup(t, cache, "81.2.69.142")
+	if got := provider.count(); got != 2 {
+		t.Fatalf("provider looked up %d IPs, want a miss after the version change", got)
+	}
+	if results[0].DBVersion != "GeoLite2-City.mmdb@2" {
+		t.Fatalf("DBVersion = %q, want the new version", results[0].DBVersion)
+	}
+
+	lookup(t, cache, "81.2.69.142")
+	if got := provider.count(); got != 2 {
+		t.Fatalf("provider looked up %d IPs, want the new version served from Redis", got)
+	}
+}
+
+func TestRedisCacheLooksUpOnlyMisses(t *testing.T) {
+	provider := &countingProvider{version: "GeoLite2-City.mmdb@1"}
+	cache, _ := testRedisCache(t, provider)
+
+	lookup(t, cache, "81.2.69.142")
+	results := lookup(t, cache, "81.2.69.142", "89.160.20.112")
+	if got := provider.count(); got != 2 {
+		t.Fatalf("provider looked up %d IPs, want only the miss of the second lookup", got)
+	}
+	for i, ip := range []string{"81.2.69.142", "89.160.20.112"} {
+		if results[i].IP != ip {
+			t.Fatalf("results[%d].IP = %q, want %q", i, results[i].IP, ip)
+		}
+	}
+}
+
+func TestRedisCacheSkipsInternalErrors(t *testing.T) {
+	provider := &countingProvider{version: "GeoLite2-City.mmdb@1", failing: "89.160.20.112"}
+	cache, r := testRedisCache(t, provider)
+
+	results := lookup(t, cache, "81.2.69.142", "89.160.20.112")
+	if results[1].Status != model.LookupStatusInternal {
+		t.Fatalf("Status = %v, want %v", results[1].Status, model.LookupStatusInternal)
+	}
+	if got := len(r.keys()); got != 1 {
+		t.Fatalf("Redis holds %d keys, want only the successful lookup: %q", got, r.keys())
+	}
+
+	lookup(t, cache, "89.160.20.112")
+	if got := provider.count(); got != 3 {
+		t.Fatalf("provider looked up %d IPs, want the failed lookup retried", got)
+	}
+}
+
+func TestRedisCacheFallsBackWhenUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	provider := &countingProvider{version: "GeoLite2-City.mmdb@1"}
+	cache := newTestRedisCache(t, addr, provider)
+
+	results := lookup(t, cache, "81.2.69.142")
+	if results[0].Country == nil || results[0].Country.ISOCode != "VN" {
+		t.Fatalf("result = %+v, want the provider's result", results[0])
+	}
+	lookup(t, cache, "81.2.69.142")
+	if got := provider.count(); got != 2 {
+		t.Fatalf("provider looked up %d IPs, want every lookup served by it", got)
+	}
+}
@@ -0,0 +1,103 @@
+package iplocation
+
+import (
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/composite"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/dbip"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/ip2location"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/maxmind"
+	"geolize/services/geolize/internal/pkg/ip_location/providers/memory"
+	"geolize/utilities/conf"
+	"geolize/utilities/logging"
+	"maps"
+	"slices"
+	"sync"
+)
+
+var provider, _ = conf.GetString("geolize", "provider", maxmind.Name)
+
+// Factory creates a provider.
+type Factory func(logger logging.Logger) (IPGeolocate, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		maxmind.Name: func(logger logging.Logger) (IPGeolocate, error) {
+			return maxmind.New(logger), nil
+		},
+		dbip.Name: func(logger logging.Logger) (IPGeolocate, error) {
+			return dbip.New()
+		},
+		ip2location.Name: func(logger logging.Logger) (IPGeolocate, error) {
+			return ip2location.New()
+		},
+		memory.Name: func(logger logging.Logger) (IPGeolocate, error) {
+			return memory.New(memory.Name, nil), nil
+		},
+	}
+)
+
+func init() {
+	Register(composite.Name, newComposite)
+}
+
+// newComposite chains the providers listed in composite_providers. The
+// [composite_precedence] section maps a field to the providers that should
+// supply it first, e.g. city=ip2location,maxmind.
+func newComposite(logger logging.Logger) (IPGeolocate, error) {
+	names, _ := conf.GetStringSlice("geolize", "composite_providers")
+	if len(names) == 0 {
+		return nil, fmt.Errorf("composite_providers is not configured")
+	}
+
+	members := make([]composite.Member, 0, len(names))
+	for _, name := range names {
+		if name == composite.Name {
+			return nil, fmt.Errorf("composite provider cannot contain itself")
+		}
+		p, err := NewProvider(logger, name)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, composite.Member{Name: name, Provider: p})
+	}
+
+	precedence := make(map[string][]string)
+	for _, field := range composite.Fields {
+		if order, _ := conf.GetStringSlice("composite_precedence", field); len(order) > 0 {
+			precedence[field] = order
+		}
+	}
+
+	return composite.New(members, precedence)
+}
+
+// Register makes a provider available under name, replacing any provider
+// already registered with that name.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	factories[name] = factory
+}
+
+// NewProvider creates the provider registered under name.
+func NewProvider(logger logging.Logger, name string) (IPGeolocate, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q, expected one of %v", name, Providers())
+	}
+
+	return factory(logger)
+}
+
+// Providers lists the registered provider names.
+func Providers() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(factories))
+}
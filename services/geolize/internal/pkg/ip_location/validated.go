@@ -0,0 +1,72 @@
+package iplocation
+
+import (
+	"context"
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/validation"
+)
+
+// validated is an IPGeolocate that rejects overrides with invalid values
+// before they reach the provider, so no provider ever records one.
+type validated struct {
+	IPGeolocate
+}
+
+func (v validated) Update(ctx context.Context, request *model.IPUpdateRequest) (string, error) {
+	existing, err := v.existing(ctx, []*model.IPUpdateRequest{request})
+	if err != nil {
+		return "", err
+	}
+	if err = validation.Override(request, existing[0]); err != nil {
+		return "", err
+	}
+	return v.IPGeolocate.Update(ctx, request)
+}
+
+func (v validated) BatchUpdate(ctx context.Context, request *model.IPBatchUpdateRequest) (string, error) {
+	existing, err := v.existing(ctx, request.Overrides)
+	if err != nil {
+		return "", err
+	}
+	if err = validation.Batch(request, existing); err != nil {
+		return "", err
+	}
+	return v.IPGeolocate.BatchUpdate(ctx, request)
+}
+
+// existing looks up the records the overrides are applied to: the one of the
+// IP, or of the first address of the network. The record of an override whose
+// address does not parse is nil; the provider rejects it.
+func (v validated) existing(ctx context.Context, overrides []*model.IPUpdateRequest) ([]*model.IPResult, error) {
+	existing := make([]*model.IPResult, len(overrides))
+
+	var ips []string
+	var indexes []int
+	for i, override := range overrides {
+		network, err := ipaddr.OverrideNetwork(override)
+		if err != nil {
+			continue
+		}
+		ips = append(ips, network.Addr().String())
+		indexes = append(indexes, i)
+	}
+	if len(ips) == 0 {
+		return existing, nil
+	}
+
+	results, err := v.IPGeolocate.Lookup(ctx, &model.IPLookupRequest{
+		IPs:    ips,
+		Fields: []string{"continent", "country", "subdivisions"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if i < len(indexes) && result.Found {
+			existing[indexes[i]] = result
+		}
+	}
+
+	return existing, nil
+}
@@ -0,0 +1,119 @@
+//go:build ignore
+
+// gen_iso3166 regenerates iso3166.txt from the JSON files of the Debian
+// iso-codes project. ISO 3166 does not assign continents, so they are kept
+// from the current iso3166.txt; a new country has to be given one by hand.
+//
+//	go run gen_iso3166.go -iso-codes /usr/share/iso-codes/json -version 4.15.0
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+const header = `# ISO 3166-1 alpha-2 country codes with the continents they are on, as
+# MaxMind reports them, and the codes of their ISO 3166-2 subdivisions.
+# Generated by gen_iso3166.go from Debian iso-codes%s; XK is the
+# user-assigned code MaxMind uses for Kosovo.
+#
+# country continents subdivisions...
+`
+
+// userAssigned are the countries MaxMind reports that ISO 3166 does not have.
+var userAssigned = []string{"XK"}
+
+func main() {
+	isoCodes := flag.String("iso-codes", "/usr/share/iso-codes/json", "directory holding iso_3166-1.json and iso_3166-2.json")
+	version := flag.String("version", "", "iso-codes version, recorded in the header")
+	output := flag.String("o", "iso3166.txt", "file to write, also read for the continents")
+	flag.Parse()
+
+	continents, err := readContinents(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var countries struct {
+		Entries []struct {
+			Alpha2 string `json:"alpha_2"`
+		} `json:"3166-1"`
+	}
+	if err = readJSON(*isoCodes+"/iso_3166-1.json", &countries); err != nil {
+		log.Fatal(err)
+	}
+
+	var subdivisions struct {
+		Entries []struct {
+			Code string `json:"code"`
+		} `json:"3166-2"`
+	}
+	if err = readJSON(*isoCodes+"/iso_3166-2.json", &subdivisions); err != nil {
+		log.Fatal(err)
+	}
+
+	codes := slices.Clone(userAssigned)
+	for _, c := range countries.Entries {
+		codes = append(codes, c.Alpha2)
+	}
+	slices.Sort(codes)
+
+	subdivisionsOf := make(map[string][]string)
+	for _, s := range subdivisions.Entries {
+		country, code, ok := strings.Cut(s.Code, "-")
+		if !ok {
+			log.Fatalf("malformed subdivision code %q", s.Code)
+		}
+		subdivisionsOf[country] = append(subdivisionsOf[country], code)
+	}
+
+	if len(*version) > 0 {
+		*version = " " + *version
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, header, *version)
+	for _, code := range codes {
+		continent, ok := continents[code]
+		if !ok {
+			log.Fatalf("no continent for country %s, add it to %s", code, *output)
+		}
+		fields := append([]string{code, continent}, slices.Sorted(slices.Values(subdivisionsOf[code]))...)
+		b.WriteString(strings.Join(fields, " ") + "\n")
+	}
+
+	if err = os.WriteFile(*output, []byte(b.String()), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readContinents returns the continents column of an iso3166.txt.
+func readContinents(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	continents := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		continents[fields[0]] = fields[1]
+	}
+	return continents, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,377 @@
+// Package validation checks that an override describes a plausible record
+// before it is written: ISO 3166 codes that exist, coordinates in range, time
+// zones the runtime knows, a continent that matches the country and language
+// keys that parse.
+package validation
+
+import (
+	_ "embed"
+	"fmt"
+	"geolize/services/geolize/internal/pkg/ip_location/ipaddr"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"geolize/services/geolize/internal/pkg/ip_location/overrides"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	// Time zones are checked against the embedded database so the result
+	// does not depend on the zoneinfo files of the host.
+	_ "time/tzdata"
+
+	"golang.org/x/text/language"
+)
+
+//go:generate go run gen_iso3166.go
+
+//go:embed iso3166.txt
+var iso3166 string
+
+// continents are the continent codes MaxMind uses.
+var continents = []string{"AF", "AN", "AS", "EU", "NA", "OC", "SA"}
+
+type country struct {
+	continents   []string
+	subdivisions map[string]bool
+}
+
+// countries maps ISO 3166-1 alpha-2 codes to their continents and
+// subdivisions, and subdivisions holds the subdivision codes of every country.
+var countries, subdivisions = func() (map[string]*country, map[string]bool) {
+	countries := make(map[string]*country)
+	subdivisions := make(map[string]bool)
+
+	for _, line := range strings.Split(iso3166, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		c := &country{
+			continents:   strings.Split(fields[1], ","),
+			subdivisions: make(map[string]bool, len(fields)-2),
+		}
+		for _, code := range fields[2:] {
+			c.subdivisions[code] = true
+			subdivisions[code] = true
+		}
+		countries[fields[0]] = c
+	}
+
+	return countries, subdivisions
+}()
+
+// FieldViolation is a field of an override and what is wrong with it. Field
+// is the dotted path of the field, e.g. "location.latitude".
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Error lists every problem found in an override. It matches
+// model.ErrInvalidOverride.
+type Error struct {
+	Violations []FieldViolation
+}
+
+func (e *Error) Error() string {
+	descriptions := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		descriptions = append(descriptions, v.Field+": "+v.Description)
+	}
+	return fmt.Sprintf("%s: %s", model.ErrInvalidOverride, strings.Join(descriptions, "; "))
+}
+
+func (e *Error) Unwrap() error {
+	return model.ErrInvalidOverride
+}
+
+// Override checks every value that override sets and returns an *Error
+// listing the invalid ones, or nil. The continent and subdivisions are checked
+// against the country of the record override leaves behind, existing with
+// override applied; existing may be nil.
+func Override(override *model.IPUpdateRequest, existing *model.IPResult) error {
+	v := &validator{}
+	v.override(override, recordOf(existing))
+
+	return v.err()
+}
+
+// Batch checks every override of request against its existing record, the
+// one at the same index of existing. Violations are reported with the index
+// of the override, e.g. "overrides[3].location.latitude".
+func Batch(request *model.IPBatchUpdateRequest, existing []*model.IPResult) error {
+	v := &validator{}
+
+	// An override is checked against the record the earlier ones of the
+	// batch leave behind for the same network, however it is spelled.
+	records := make(map[string]record)
+	for i, override := range request.Overrides {
+		key := override.IP + override.Network
+		if network, err := ipaddr.OverrideNetwork(override); err == nil {
+			key = network.String()
+		}
+		r, ok := records[key]
+		if !ok && i < len(existing) {
+			r = recordOf(existing[i])
+		}
+
+		v.prefix = fmt.Sprintf("overrides[%d].", i)
+		records[key] = v.override(override, r)
+	}
+
+	return v.err()
+}
+
+// record is what the continent and subdivisions of an override are checked
+// against.
+type record struct {
+	country      string
+	continent    string
+	subdivisions []*model.Subdivision
+}
+
+func recordOf(result *model.IPResult) record {
+	r := record{}
+	if result == nil {
+		return r
+	}
+	if result.Country != nil {
+		r.country = result.Country.ISOCode
+	}
+	if result.Continent != nil {
+		r.continent = result.Continent.Code
+	}
+	r.subdivisions = result.Subdivisions
+
+	return r
+}
+
+// writes reports whether override writes the field path, and whether it
+// deletes it. set tells whether override has the sub-object of path, which a
+// whole-object override writes.
+func writes(override *model.IPUpdateRequest, path string, set bool) (written, removed bool) {
+	covered := func(field string) bool { return overrides.Covers(field, path) }
+
+	written = set
+	if len(override.Fields) > 0 {
+		written = slices.ContainsFunc(override.Fields, covered)
+	}
+	removed = slices.ContainsFunc(override.RemoveFields, covered)
+
+	return written || removed, removed
+}
+
+type validator struct {
+	// prefix is put in front of the field of every violation.
+	prefix     string
+	violations []FieldViolation
+}
+
+func (v *validator) add(field, description string) {
+	v.violations = append(v.violations, FieldViolation{Field: v.prefix + field, Description: description})
+}
+
+func (v *validator) err() error {
+	if len(v.violations) > 0 {
+		return &Error{Violations: v.violations}
+	}
+	return nil
+}
+
+// override checks override, applied to the record r, and returns the record
+// it leaves behind.
+func (v *validator) override(override *model.IPUpdateRequest, r record) record {
+	switch {
+	case len(override.IP) == 0 && len(override.Network) == 0:
+		v.add("ip", "ip or network is required")
+	case len(override.IP) > 0 && len(override.Network) > 0:
+		v.add("network", "only one of ip or network can be set")
+	}
+
+	for i, path := range override.Fields {
+		if err := overrides.CheckFieldPath(path); err != nil {
+			v.add(fmt.Sprintf("fields[%d]", i), err.Error())
+		}
+	}
+	for i, path := range override.RemoveFields {
+		if err := overrides.CheckFieldPath(path); err != nil {
+			v.add(fmt.Sprintf("remove_fields[%d]", i), err.Error())
+		}
+	}
+
+	if override.Country != nil {
+		v.countryCode("country.iso_code", override.Country.ISOCode)
+		v.names("country.names", override.Country.Names)
+		v.confidence("country.confidence", override.Country.Confidence)
+	}
+
+	if override.Continent != nil {
+		if code := override.Continent.Code; len(code) > 0 && !slices.Contains(continents, code) {
+			v.add("continent.code", fmt.Sprintf("unknown continent code %q, expected one of %s", code, strings.Join(continents, ", ")))
+		}
+		v.names("continent.names", override.Continent.Names)
+	}
+
+	for i, subdivision := range override.Subdivisions {
+		field := fmt.Sprintf("subdivisions[%d]", i)
+		v.names(field+".names", subdivision.Names)
+		v.confidence(field+".confidence", subdivision.Confidence)
+	}
+
+	r = v.consistency(override, r)
+
+	if override.City != nil {
+		v.names("city.names", override.City.Names)
+		v.confidence("city.confidence", override.City.Confidence)
+	}
+
+	if override.Location != nil {
+		if lat := override.Location.Latitude; lat < -90 || lat > 90 {
+			v.add("location.latitude", fmt.Sprintf("latitude %g is out of range [-90, 90]", lat))
+		}
+		if lon := override.Location.Longitude; lon < -180 || lon > 180 {
+			v.add("location.longitude", fmt.Sprintf("longitude %g is out of range [-180, 180]", lon))
+		}
+		if tz := override.Location.TimeZone; len(tz) > 0 {
+			if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+				v.add("location.time_zone", fmt.Sprintf("unknown IANA time zone %q", tz))
+			}
+		}
+	}
+
+	if override.Postal != nil {
+		v.confidence("postal.confidence", override.Postal.Confidence)
+	}
+
+	if override.RepresentedCountry != nil {
+		v.countryCode("represented_country.iso_code", override.RepresentedCountry.ISOCode)
+		v.names("represented_country.names", override.RepresentedCountry.Names)
+	}
+
+	if override.RegisteredCountry != nil {
+		v.countryCode("registered_country.iso_code", override.RegisteredCountry.ISOCode)
+		v.names("registered_country.names", override.RegisteredCountry.Names)
+		v.confidence("registered_country.confidence", override.RegisteredCountry.Confidence)
+	}
+
+	return r
+}
+
+// consistency applies override to r and checks that the continent and
+// subdivisions of the result belong to its country. Only a mismatch that
+// override is part of is reported: on the field it writes, the continent or
+// subdivisions first and the country otherwise.
+func (v *validator) consistency(override *model.IPUpdateRequest, r record) record {
+	countryWritten, removed := writes(override, "country.iso_code", override.Country != nil)
+	if countryWritten {
+		r.country = ""
+		if override.Country != nil && !removed {
+			r.country = override.Country.ISOCode
+		}
+	}
+	continentWritten, removed := writes(override, "continent.code", override.Continent != nil)
+	if continentWritten {
+		r.continent = ""
+		if override.Continent != nil && !removed {
+			r.continent = override.Continent.Code
+		}
+	}
+	subdivisionsWritten, removed := writes(override, "subdivisions", len(override.Subdivisions) > 0)
+	if subdivisionsWritten {
+		r.subdivisions = nil
+		if !removed {
+			r.subdivisions = override.Subdivisions
+		}
+	}
+
+	// An unknown country is reported on its own; the subdivisions can still
+	// be checked against those of every country.
+	country, known := countries[r.country]
+	countryCode := r.country
+	if !known {
+		countryCode = ""
+	}
+
+	switch {
+	case !known || len(r.continent) == 0 || !slices.Contains(continents, r.continent) || slices.Contains(country.continents, r.continent):
+	case continentWritten:
+		v.add("continent.code", fmt.Sprintf("country %s is in %s, not %s", r.country, strings.Join(country.continents, " or "), r.continent))
+	case countryWritten:
+		v.add("country.iso_code", fmt.Sprintf("country %s is in %s, not in the record's continent %s", r.country, strings.Join(country.continents, " or "), r.continent))
+	}
+
+	for i, subdivision := range r.subdivisions {
+		switch {
+		case subdivisionsWritten:
+			v.subdivisionCode(fmt.Sprintf("subdivisions[%d].iso_code", i), subdivision.ISOCode, countryCode)
+		case countryWritten && known && len(subdivision.ISOCode) > 0 && !inCountry(subdivision.ISOCode, r.country):
+			v.add("country.iso_code", fmt.Sprintf("the record's subdivision %q is not in country %s", subdivision.ISOCode, r.country))
+		}
+	}
+
+	return r
+}
+
+// countryCode reports whether code is a known country. An empty code is not
+// checked.
+func (v *validator) countryCode(field, code string) bool {
+	if len(code) == 0 {
+		return false
+	}
+	if _, ok := countries[code]; !ok {
+		v.add(field, fmt.Sprintf("unknown ISO 3166-1 alpha-2 country code %q", code))
+		return false
+	}
+	return true
+}
+
+// subdivisionCode checks code, with or without its country prefix, against
+// the subdivisions of countryCode, or of any country when it is empty.
+func (v *validator) subdivisionCode(field, code, countryCode string) {
+	if len(code) == 0 {
+		return
+	}
+
+	if prefix, rest, ok := strings.Cut(code, "-"); ok {
+		if len(countryCode) > 0 && prefix != countryCode {
+			v.add(field, fmt.Sprintf("subdivision %q is not in country %s", code, countryCode))
+			return
+		}
+		if _, known := countries[prefix]; known {
+			countryCode, code = prefix, rest
+		}
+	}
+
+	switch {
+	case len(countryCode) > 0 && !countries[countryCode].subdivisions[code]:
+		v.add(field, fmt.Sprintf("unknown ISO 3166-2 subdivision code %q for country %s", code, countryCode))
+	case len(countryCode) == 0 && !subdivisions[code]:
+		v.add(field, fmt.Sprintf("unknown ISO 3166-2 subdivision code %q", code))
+	}
+}
+
+// inCountry reports whether the subdivision code, with or without its
+// country prefix, is one of countryCode.
+func inCountry(code, countryCode string) bool {
+	if prefix, rest, ok := strings.Cut(code, "-"); ok {
+		if prefix != countryCode {
+			return false
+		}
+		code = rest
+	}
+	return countries[countryCode].subdivisions[code]
+}
+
+func (v *validator) names(field string, names map[string]string) {
+	for _, lang := range slices.Sorted(maps.Keys(names)) {
+		if _, err := language.Parse(lang); err != nil {
+			v.add(field+"."+lang, fmt.Sprintf("%q is not a BCP 47 language tag", lang))
+		}
+	}
+}
+
+func (v *validator) confidence(field string, confidence uint8) {
+	if confidence > 100 {
+		v.add(field, fmt.Sprintf("confidence %d is out of range [0, 100]", confidence))
+	}
+}
@@ -0,0 +1,181 @@
+package validation
+
+import (
+	"errors"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+	"slices"
+	"testing"
+)
+
+// fields returns the fields err reports violations for.
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var verr *Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("error %v is not a *validation.Error", err)
+	}
+	if !errors.Is(err, model.ErrInvalidOverride) {
+		t.Fatalf("error %v does not match model.ErrInvalidOverride", err)
+	}
+
+	var fields []string
+	for _, v := range verr.Violations {
+		fields = append(fields, v.Field)
+	}
+	return fields
+}
+
+func TestSubdivisionCodes(t *testing.T) {
+	tests := []struct {
+		country string
+		code    string
+		valid   bool
+	}{
+		{"AR", "F", true},
+		{"AR", "AR-F", true},
+		{"IN", "DH", true},
+		{"IN", "LA", true},
+		{"", "IN-DH", true},
+		{"", "IN-LA", true},
+		{"VN", "HN", true},
+		{"GB", "ENG", true},
+		// Dadra and Nagar Haveli and Daman and Diu merged into IN-DH in 2020.
+		{"IN", "DD", false},
+		{"IN", "DN", false},
+		{"", "IN-DD", false},
+		{"", "IN-DN", false},
+		{"AR", "IN-LA", false},
+		{"AR", "ZZZ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country+"/"+tt.code, func(t *testing.T) {
+			override := &model.IPUpdateRequest{
+				IP:           "81.2.69.142",
+				Subdivisions: []*model.Subdivision{{ISOCode: tt.code}},
+			}
+			if len(tt.country) > 0 {
+				override.Country = &model.Country{ISOCode: tt.country}
+			}
+
+			got := fields(t, Override(override, nil))
+			if want := []string{"subdivisions[0].iso_code"}; tt.valid && got != nil || !tt.valid && !slices.Equal(got, want) {
+				t.Fatalf("violations = %q, valid %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestCountryCodes(t *testing.T) {
+	for code, valid := range map[string]bool{"AR": true, "IN": true, "XK": true, "SS": true, "AN": false, "YU": false, "ZZ": false} {
+		got := fields(t, Override(&model.IPUpdateRequest{IP: "81.2.69.142", Country: &model.Country{ISOCode: code}}, nil))
+		if valid != (got == nil) {
+			t.Errorf("%s: violations = %q, valid %v", code, got, valid)
+		}
+	}
+}
+
+func TestEffectiveRecord(t *testing.T) {
+	existing := &model.IPResult{
+		Found:        true,
+		Continent:    &model.Continent{Code: "AS"},
+		Country:      &model.Country{ISOCode: "VN"},
+		Subdivisions: []*model.Subdivision{{ISOCode: "HN"}},
+	}
+
+	tests := []struct {
+		name     string
+		override *model.IPUpdateRequest
+		want     []string
+	}{
+		{
+			name:     "continent of the existing country",
+			override: &model.IPUpdateRequest{Continent: &model.Continent{Code: "AS"}},
+		},
+		{
+			name:     "continent not of the existing country",
+			override: &model.IPUpdateRequest{Continent: &model.Continent{Code: "EU"}},
+			want:     []string{"continent.code"},
+		},
+		{
+			name:     "country not on the existing continent",
+			override: &model.IPUpdateRequest{Country: &model.Country{ISOCode: "FR"}},
+			want:     []string{"country.iso_code", "country.iso_code"},
+		},
+		{
+			name: "country and continent together",
+			override: &model.IPUpdateRequest{
+				Country:      &model.Country{ISOCode: "FR"},
+				Continent:    &model.Continent{Code: "EU"},
+				Subdivisions: []*model.Subdivision{{ISOCode: "IDF"}},
+			},
+		},
+		{
+			name:     "subdivision not of the existing country",
+			override: &model.IPUpdateRequest{Subdivisions: []*model.Subdivision{{ISOCode: "IDF"}}},
+			want:     []string{"subdivisions[0].iso_code"},
+		},
+		{
+			name:     "country the existing subdivision is not in",
+			override: &model.IPUpdateRequest{Country: &model.Country{ISOCode: "TH"}},
+			want:     []string{"country.iso_code"},
+		},
+		{
+			name: "partial override leaving the country",
+			override: &model.IPUpdateRequest{
+				Country: &model.Country{ISOCode: "FR", Names: map[string]string{"vi": "Việt Nam"}},
+				Fields:  []string{"country.names.vi"},
+			},
+		},
+		{
+			name: "removed continent",
+			override: &model.IPUpdateRequest{
+				Country:      &model.Country{ISOCode: "FR"},
+				RemoveFields: []string{"continent", "subdivisions"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.override.IP = "81.2.69.142"
+			if got := fields(t, Override(tt.override, existing)); !slices.Equal(got, tt.want) {
+				t.Fatalf("violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchEffectiveRecord(t *testing.T) {
+	existing := &model.IPResult{Found: true, Continent: &model.Continent{Code: "AS"}, Country: &model.Country{ISOCode: "VN"}}
+	request := &model.IPBatchUpdateRequest{Overrides: []*model.IPUpdateRequest{
+		{Network: "81.2.69.0/24", Continent: &model.Continent{Code: "EU"}},
+		{Network: "81.2.69.0/24", Country: &model.Country{ISOCode: "FR"}},
+		{Network: "81.2.70.0/24", Country: &model.Country{ISOCode: "FR"}},
+	}}
+
+	got := fields(t, Batch(request, []*model.IPResult{existing, existing, existing}))
+	if want := []string{"overrides[0].continent.code", "overrides[2].country.iso_code"}; !slices.Equal(got, want) {
+		t.Fatalf("violations = %q, want %q", got, want)
+	}
+}
+
+func TestBatchSameTargetSpelledDifferently(t *testing.T) {
+	existing := &model.IPResult{Found: true, Continent: &model.Continent{Code: "AS"}, Country: &model.Country{ISOCode: "VN"}}
+	request := &model.IPBatchUpdateRequest{Overrides: []*model.IPUpdateRequest{
+		{IP: "81.2.69.142", Country: &model.Country{ISOCode: "FR"}, Continent: &model.Continent{Code: "EU"}},
+		{Network: "81.2.69.142/32", Subdivisions: []*model.Subdivision{{ISOCode: "IDF"}}},
+		{IP: "2001:db8::1", Country: &model.Country{ISOCode: "FR"}, Continent: &model.Continent{Code: "EU"}},
+		{IP: "2001:0db8:0:0::1", Subdivisions: []*model.Subdivision{{ISOCode: "IDF"}}},
+		{Network: "2001:db8::1/128", Continent: &model.Continent{Code: "EU"}},
+	}}
+
+	existings := []*model.IPResult{existing, existing, existing, existing, existing}
+	if got := fields(t, Batch(request, existings)); got != nil {
+		t.Fatalf("violations = %q, want none", got)
+	}
+}
@@ -0,0 +1,137 @@
+package transform_response
+
+import (
+	"fmt"
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// alwaysIncluded are IPInfo fields returned regardless of the mask, so every
+// entry of a batch can still be matched to its IP and outcome.
+var alwaysIncluded = []string{"ip", "status", "message"}
+
+// maskNode is one level of a field mask. A node without children selects the
+// whole field.
+type maskNode map[string]maskNode
+
+// FieldMask selects which IPInfo fields a lookup returns. A nil FieldMask
+// selects everything.
+type FieldMask struct {
+	root maskNode
+}
+
+// NewFieldMask validates paths against IPInfo. Paths use proto field names
+// ("country.iso_code", "location.time_zone") and may step into repeated
+// messages ("subdivisions.iso_code"). No paths means no mask.
+func NewFieldMask(paths []string) (*FieldMask, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	root := make(maskNode)
+	descriptor := (&geolize_pb.IPInfo{}).ProtoReflect().Descriptor()
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if len(path) == 0 {
+			continue
+		}
+
+		node, md := root, descriptor
+		for _, name := range strings.Split(path, ".") {
+			if md == nil {
+				return nil, fmt.Errorf("invalid field mask path %q: %q has no sub-fields", path, name)
+			}
+			fd := md.Fields().ByName(protoreflect.Name(name))
+			if fd == nil {
+				fd = md.Fields().ByJSONName(name)
+			}
+			if fd == nil {
+				return nil, fmt.Errorf("invalid field mask path %q: unknown field %q", path, name)
+			}
+
+			child, ok := node[string(fd.Name())]
+			if !ok {
+				child = make(maskNode)
+				node[string(fd.Name())] = child
+			}
+			node = child
+
+			md = nil
+			if fd.Kind() == protoreflect.MessageKind && !fd.IsMap() {
+				md = fd.Message()
+			}
+		}
+	}
+
+	if len(root) == 0 {
+		return nil, nil
+	}
+
+	for _, name := range alwaysIncluded {
+		root[name] = maskNode{}
+	}
+
+	return &FieldMask{root: root}, nil
+}
+
+// Has reports whether the top-level IPInfo field is selected, fully or in part.
+func (m *FieldMask) Has(field string) bool {
+	if m == nil {
+		return true
+	}
+	_, ok := m.root[field]
+	return ok
+}
+
+// Fields returns the selected top-level IPInfo fields, or nil for all of them.
+func (m *FieldMask) Fields() []string {
+	if m == nil {
+		return nil
+	}
+
+	fields := make([]string, 0, len(m.root))
+	for field := range m.root {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// trim clears every field of message that the mask does not select. Whether a
+// sub-message is there at all is left to the provider model, which leaves
+// the sub-objects a record does not have nil, so a selected sub-message is
+// kept even when all its values are zero, e.g. a location at 0, 0.
+func trim(message protoreflect.Message, node maskNode) {
+	message.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		child, selected := node[string(fd.Name())]
+		if node != nil && len(node) > 0 && !selected {
+			message.Clear(fd)
+			return true
+		}
+
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+			return true
+		}
+
+		if fd.IsList() {
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				trim(list.Get(i).Message(), child)
+			}
+			return true
+		}
+
+		trim(value.Message(), child)
+		return true
+	})
+}
+
+// Apply trims info to the mask. Without a mask info is left as it is.
+func (m *FieldMask) Apply(info *geolize_pb.IPInfo) {
+	var root maskNode
+	if m != nil {
+		root = m.root
+	}
+	trim(info.ProtoReflect(), root)
+}
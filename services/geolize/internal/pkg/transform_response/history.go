@@ -0,0 +1,56 @@
+package transform_response
+
+import (
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToGetIPOverrideHistoryResponse converts a change log. It fails when a
+// record holds a value that JSON cannot represent.
+func ToGetIPOverrideHistoryResponse(changes []*model.IPOverrideChange) (*geolize_pb.GetIPOverrideHistoryResponse, error) {
+	response := &geolize_pb.GetIPOverrideHistoryResponse{}
+	for _, change := range changes {
+		before, err := structpb.NewStruct(change.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := structpb.NewStruct(change.After)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &geolize_pb.IPOverrideChange{
+			Version:   change.Version,
+			Kind:      change.Kind,
+			Network:   change.Network,
+			Author:    change.Author,
+			Reason:    change.Reason,
+			Ticket:    change.Ticket,
+			CreatedAt: timestamppb.New(change.CreatedAt),
+			Before:    before,
+			After:     after,
+			Reverted:  change.Reverted,
+		}
+		for _, field := range change.Changes {
+			fieldBefore, err := structpb.NewValue(field.Before)
+			if err != nil {
+				return nil, err
+			}
+			fieldAfter, err := structpb.NewValue(field.After)
+			if err != nil {
+				return nil, err
+			}
+			entry.Changes = append(entry.Changes, &geolize_pb.FieldChange{
+				Path:   field.Path,
+				Before: fieldBefore,
+				After:  fieldAfter,
+			})
+		}
+
+		response.Changes = append(response.Changes, entry)
+	}
+	return response, nil
+}
@@ -0,0 +1,115 @@
+package transform_response
+
+import "strings"
+
+// defaultLanguage is always tried last, since every MaxMind record has it.
+const defaultLanguage = "en"
+
+// Locale narrows the localized names of a lookup response to the caller's
+// preferred languages. A nil Locale leaves every name untouched.
+type Locale struct {
+	languages []string
+	// resolve returns a single resolved name per place instead of a names map.
+	resolve bool
+}
+
+// NewLocale returns nil when there are no preferences, so responses keep all
+// languages by default.
+func NewLocale(languages []string, resolve bool) *Locale {
+	var preferred []string
+	for _, language := range languages {
+		language = strings.TrimSpace(language)
+		if len(language) > 0 {
+			preferred = append(preferred, language)
+		}
+	}
+
+	if len(preferred) == 0 && !resolve {
+		return nil
+	}
+
+	return &Locale{
+		languages: append(preferred, defaultLanguage),
+		resolve:   resolve,
+	}
+}
+
+// Names returns the localized names to put on the wire: only the locales that
+// match a preferred language, or none at all when names are resolved.
+func (l *Locale) Names(names map[string]string) map[string]string {
+	if l == nil {
+		return names
+	}
+	if l.resolve || len(names) == 0 {
+		return nil
+	}
+
+	filtered := make(map[string]string)
+	for _, language := range l.languages[:len(l.languages)-1] {
+		if key, ok := match(names, language); ok {
+			filtered[key] = names[key]
+		}
+	}
+
+	// Nothing matched: fall back to the default language rather than nothing.
+	if len(filtered) == 0 {
+		if key, ok := match(names, defaultLanguage); ok {
+			filtered[key] = names[key]
+		}
+	}
+
+	return filtered
+}
+
+// Name returns the name in the most preferred language that is available,
+// e.g. "vi" falls back to "en" for places without a Vietnamese name.
+func (l *Locale) Name(names map[string]string) string {
+	if l == nil {
+		return ""
+	}
+
+	for _, language := range l.languages {
+		if key, ok := match(names, language); ok {
+			return names[key]
+		}
+	}
+
+	return ""
+}
+
+// match finds the key in names for language. It tries an exact match, then
+// the base language ("pt-PT" matches "pt"), then any region of the same base
+// language ("pt" matches "pt-BR", "zh-TW" matches "zh-CN").
+func match(names map[string]string, language string) (string, bool) {
+	base := baseLanguage(language)
+
+	var baseKey, regionKey string
+	for key := range names {
+		switch {
+		case strings.EqualFold(key, language):
+			return key, true
+		case strings.EqualFold(key, base):
+			baseKey = key
+		case strings.EqualFold(baseLanguage(key), base):
+			if len(regionKey) == 0 || key < regionKey {
+				regionKey = key
+			}
+		}
+	}
+
+	if len(baseKey) > 0 {
+		return baseKey, true
+	}
+	if len(regionKey) > 0 {
+		return regionKey, true
+	}
+
+	return "", false
+}
+
+func baseLanguage(language string) string {
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		return language[:i]
+	}
+	return language
+}
@@ -5,60 +5,171 @@ import (
 	"geolize/services/geolize/internal/pkg/ip_location/model"
 )
 
-func ToLookupIPsResponse(ipResults []*model.IPResult) []*geolize_pb.IPInfo {
+var lookupStatuses = map[model.LookupStatus]geolize_pb.LookupStatus{
+	model.LookupStatusOK:        geolize_pb.LookupStatus_LOOKUP_STATUS_OK,
+	model.LookupStatusInvalidIP: geolize_pb.LookupStatus_LOOKUP_STATUS_INVALID_IP,
+	model.LookupStatusNotFound:  geolize_pb.LookupStatus_LOOKUP_STATUS_NOT_FOUND,
+	model.LookupStatusInternal:  geolize_pb.LookupStatus_LOOKUP_STATUS_INTERNAL,
+}
+
+// ToLookupIPsResponse converts lookup results to IPInfo, narrowing every
+// names map through locale. Sub-objects outside mask are never built, and
+// empty ones are omitted.
+func ToLookupIPsResponse(ipResults []*model.IPResult, locale *Locale, mask *FieldMask) []*geolize_pb.IPInfo {
 	var ipInfos []*geolize_pb.IPInfo
 	for _, ipResult := range ipResults {
-		ipInfos = append(ipInfos, &geolize_pb.IPInfo{
+		ipInfo := &geolize_pb.IPInfo{
 			Ip:        ipResult.IP,
 			DbVersion: ipResult.DBVersion,
-			City: &geolize_pb.City{
-				Names: ipResult.City.Names,
-			},
-			Location: &geolize_pb.Location{
-				Latitude:       ipResult.Location.Latitude,
-				Longitude:      ipResult.Location.Longitude,
-				AccuracyRadius: uint32(ipResult.Location.AccuracyRadius),
-				TimeZone:       ipResult.Location.TimeZone,
-			},
-			Continent: &geolize_pb.Continent{
-				Code:  ipResult.Continent.Code,
-				Names: ipResult.Continent.Names,
-			},
-			Country: &geolize_pb.Country{
-				IsoCode:           ipResult.Country.ISOCode,
-				Names:             ipResult.Country.Names,
-				IsInEuropeanUnion: ipResult.Country.IsInEuropeanUnion,
-			},
+			Found:     ipResult.Found,
+			Network:   ipResult.Network,
+			Status:    lookupStatuses[ipResult.Status],
+			Message:   ipResult.Message,
+			Provider:  ipResult.Provider,
+			Sources:   ipResult.Sources,
+
+			Asn:                uint32(ipResult.ASN),
+			AsOrg:              ipResult.ASOrg,
+			IsAnonymous:        ipResult.IsAnonymous,
+			IsVpn:              ipResult.IsVPN,
+			IsTor:              ipResult.IsTor,
+			IsHostingProvider:  ipResult.IsHostingProvider,
+			IsPublicProxy:      ipResult.IsPublicProxy,
+			IsResidentialProxy: ipResult.IsResidentialProxy,
+			ConnectionType:     ipResult.ConnectionType,
+
+			City: func() *geolize_pb.City {
+				if ipResult.City == nil || !mask.Has("city") {
+					return nil
+				}
+				return &geolize_pb.City{
+					Names:      locale.Names(ipResult.City.Names),
+					Name:       locale.Name(ipResult.City.Names),
+					GeonameId:  uint32(ipResult.City.GeoNameID),
+					Confidence: uint32(ipResult.City.Confidence),
+				}
+			}(),
+			Location: func() *geolize_pb.Location {
+				if ipResult.Location == nil || !mask.Has("location") {
+					return nil
+				}
+				return &geolize_pb.Location{
+					Latitude:          ipResult.Location.Latitude,
+					Longitude:         ipResult.Location.Longitude,
+					AccuracyRadius:    uint32(ipResult.Location.AccuracyRadius),
+					TimeZone:          ipResult.Location.TimeZone,
+					MetroCode:         uint32(ipResult.Location.MetroCode),
+					PopulationDensity: uint32(ipResult.Location.PopulationDensity),
+					AverageIncome:     uint32(ipResult.Location.AverageIncome),
+				}
+			}(),
+			Continent: func() *geolize_pb.Continent {
+				if ipResult.Continent == nil || !mask.Has("continent") {
+					return nil
+				}
+				return &geolize_pb.Continent{
+					Code:      ipResult.Continent.Code,
+					Names:     locale.Names(ipResult.Continent.Names),
+					Name:      locale.Name(ipResult.Continent.Names),
+					GeonameId: uint32(ipResult.Continent.GeoNameID),
+				}
+			}(),
+			Country: func() *geolize_pb.Country {
+				if ipResult.Country == nil || !mask.Has("country") {
+					return nil
+				}
+				return &geolize_pb.Country{
+					IsoCode:           ipResult.Country.ISOCode,
+					Names:             locale.Names(ipResult.Country.Names),
+					Name:              locale.Name(ipResult.Country.Names),
+					IsInEuropeanUnion: ipResult.Country.IsInEuropeanUnion,
+					GeonameId:         uint32(ipResult.Country.GeoNameID),
+					Confidence:        uint32(ipResult.Country.Confidence),
+				}
+			}(),
 			Subdivisions: func() []*geolize_pb.Subdivision {
+				if !mask.Has("subdivisions") {
+					return nil
+				}
 				var subdivisions []*geolize_pb.Subdivision
 				for _, subdivision := range ipResult.Subdivisions {
 					subdivisions = append(subdivisions, &geolize_pb.Subdivision{
-						IsoCode: subdivision.ISOCode,
-						Names:   subdivision.Names,
+						IsoCode:    subdivision.ISOCode,
+						Names:      locale.Names(subdivision.Names),
+						Name:       locale.Name(subdivision.Names),
+						GeonameId:  uint32(subdivision.GeoNameID),
+						Confidence: uint32(subdivision.Confidence),
 					})
 				}
 				return subdivisions
 			}(),
-			RepresentedCountry: &geolize_pb.RepresentedCountry{
-				IsoCode:           ipResult.RepresentedCountry.ISOCode,
-				Type:              ipResult.RepresentedCountry.Type,
-				Names:             ipResult.RepresentedCountry.Names,
-				IsInEuropeanUnion: ipResult.RepresentedCountry.IsInEuropeanUnion,
-			},
-			RegisteredCountry: &geolize_pb.RegisteredCountry{
-				IsoCode:           ipResult.RegisteredCountry.ISOCode,
-				Names:             ipResult.RegisteredCountry.Names,
-				IsInEuropeanUnion: ipResult.RegisteredCountry.IsInEuropeanUnion,
-			},
-			Postal: &geolize_pb.Postal{
-				Code: ipResult.Postal.Code,
-			},
-			Traits: &geolize_pb.Traits{
-				IsAnonymousProxy:    ipResult.Traits.IsAnonymousProxy,
-				IsAnycast:           ipResult.Traits.IsAnycast,
-				IsSatelliteProvider: ipResult.Traits.IsSatelliteProvider,
-			},
-		})
+			RepresentedCountry: func() *geolize_pb.RepresentedCountry {
+				if ipResult.RepresentedCountry == nil || !mask.Has("represented_country") {
+					return nil
+				}
+				return &geolize_pb.RepresentedCountry{
+					IsoCode:           ipResult.RepresentedCountry.ISOCode,
+					Type:              ipResult.RepresentedCountry.Type,
+					Names:             locale.Names(ipResult.RepresentedCountry.Names),
+					Name:              locale.Name(ipResult.RepresentedCountry.Names),
+					IsInEuropeanUnion: ipResult.RepresentedCountry.IsInEuropeanUnion,
+					GeonameId:         uint32(ipResult.RepresentedCountry.GeoNameID),
+				}
+			}(),
+			RegisteredCountry: func() *geolize_pb.RegisteredCountry {
+				if ipResult.RegisteredCountry == nil || !mask.Has("registered_country") {
+					return nil
+				}
+				return &geolize_pb.RegisteredCountry{
+					IsoCode:           ipResult.RegisteredCountry.ISOCode,
+					Names:             locale.Names(ipResult.RegisteredCountry.Names),
+					Name:              locale.Name(ipResult.RegisteredCountry.Names),
+					IsInEuropeanUnion: ipResult.RegisteredCountry.IsInEuropeanUnion,
+					GeonameId:         uint32(ipResult.RegisteredCountry.GeoNameID),
+					Confidence:        uint32(ipResult.RegisteredCountry.Confidence),
+				}
+			}(),
+			Postal: func() *geolize_pb.Postal {
+				if ipResult.Postal == nil || !mask.Has("postal") {
+					return nil
+				}
+				return &geolize_pb.Postal{
+					Code:       ipResult.Postal.Code,
+					Confidence: uint32(ipResult.Postal.Confidence),
+				}
+			}(),
+			Traits: func() *geolize_pb.Traits {
+				if ipResult.Traits == nil || !mask.Has("traits") {
+					return nil
+				}
+				return &geolize_pb.Traits{
+					IsAnonymousProxy:             ipResult.Traits.IsAnonymousProxy,
+					IsAnycast:                    ipResult.Traits.IsAnycast,
+					IsSatelliteProvider:          ipResult.Traits.IsSatelliteProvider,
+					IsAnonymous:                  ipResult.Traits.IsAnonymous,
+					IsAnonymousVpn:               ipResult.Traits.IsAnonymousVPN,
+					IsHostingProvider:            ipResult.Traits.IsHostingProvider,
+					IsLegitimateProxy:            ipResult.Traits.IsLegitimateProxy,
+					IsPublicProxy:                ipResult.Traits.IsPublicProxy,
+					IsResidentialProxy:           ipResult.Traits.IsResidentialProxy,
+					IsTorExitNode:                ipResult.Traits.IsTorExitNode,
+					AutonomousSystemNumber:       uint32(ipResult.Traits.AutonomousSystemNumber),
+					AutonomousSystemOrganization: ipResult.Traits.AutonomousSystemOrganization,
+					ConnectionType:               ipResult.Traits.ConnectionType,
+					Domain:                       ipResult.Traits.Domain,
+					Isp:                          ipResult.Traits.ISP,
+					MobileCountryCode:            ipResult.Traits.MobileCountryCode,
+					MobileNetworkCode:            ipResult.Traits.MobileNetworkCode,
+					Organization:                 ipResult.Traits.Organization,
+					UserType:                     ipResult.Traits.UserType,
+					StaticIpScore:                ipResult.Traits.StaticIPScore,
+					Network:                      ipResult.Traits.Network,
+				}
+			}(),
+		}
+		mask.Apply(ipInfo)
+
+		ipInfos = append(ipInfos, ipInfo)
 	}
 
 	return ipInfos
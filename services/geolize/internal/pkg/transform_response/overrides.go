@@ -0,0 +1,128 @@
+package transform_response
+
+import (
+	"geolize/service-protos/generated/geolize/geolize_pb"
+	"geolize/services/geolize/internal/pkg/ip_location/model"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToListIPOverridesResponse converts a page of effective overrides. Each
+// override is returned in the shape ModifyIP accepts it.
+func ToListIPOverridesResponse(list *model.IPOverrideList) *geolize_pb.ListIPOverridesResponse {
+	response := &geolize_pb.ListIPOverridesResponse{
+		NextPageToken: list.NextPageToken,
+	}
+	for _, override := range list.Overrides {
+		response.Overrides = append(response.Overrides, &geolize_pb.IPOverride{
+			Network:   override.Network,
+			Override:  ToModifyIPRequest(override.Override),
+			Version:   override.Version,
+			CreatedAt: timestamppb.New(override.CreatedAt),
+		})
+	}
+	return response
+}
+
+// ToModifyIPRequest converts an override back to the request that makes it.
+func ToModifyIPRequest(update *model.IPUpdateRequest) *geolize_pb.ModifyIPRequest {
+	request := &geolize_pb.ModifyIPRequest{
+		Ip:           update.IP,
+		Network:      update.Network,
+		Fields:       update.Fields,
+		RemoveFields: update.RemoveFields,
+	}
+
+	if update.Continent != nil {
+		request.Continent = &geolize_pb.Continent{
+			Code:      update.Continent.Code,
+			Names:     update.Continent.Names,
+			GeonameId: uint32(update.Continent.GeoNameID),
+		}
+	}
+	if update.Country != nil {
+		request.Country = &geolize_pb.Country{
+			IsoCode:           update.Country.ISOCode,
+			Names:             update.Country.Names,
+			IsInEuropeanUnion: update.Country.IsInEuropeanUnion,
+			GeonameId:         uint32(update.Country.GeoNameID),
+			Confidence:        uint32(update.Country.Confidence),
+		}
+	}
+	for _, subdivision := range update.Subdivisions {
+		request.Subdivisions = append(request.Subdivisions, &geolize_pb.Subdivision{
+			IsoCode:    subdivision.ISOCode,
+			Names:      subdivision.Names,
+			GeonameId:  uint32(subdivision.GeoNameID),
+			Confidence: uint32(subdivision.Confidence),
+		})
+	}
+	if update.Location != nil {
+		request.Location = &geolize_pb.Location{
+			Latitude:          update.Location.Latitude,
+			Longitude:         update.Location.Longitude,
+			AccuracyRadius:    uint32(update.Location.AccuracyRadius),
+			TimeZone:          update.Location.TimeZone,
+			MetroCode:         uint32(update.Location.MetroCode),
+			PopulationDensity: uint32(update.Location.PopulationDensity),
+			AverageIncome:     uint32(update.Location.AverageIncome),
+		}
+	}
+	if update.Postal != nil {
+		request.Postal = &geolize_pb.Postal{
+			Code:       update.Postal.Code,
+			Confidence: uint32(update.Postal.Confidence),
+		}
+	}
+	if update.City != nil {
+		request.City = &geolize_pb.City{
+			Names:      update.City.Names,
+			GeonameId:  uint32(update.City.GeoNameID),
+			Confidence: uint32(update.City.Confidence),
+		}
+	}
+	if update.RepresentedCountry != nil {
+		request.RepresentedCountry = &geolize_pb.RepresentedCountry{
+			IsoCode:           update.RepresentedCountry.ISOCode,
+			Names:             update.RepresentedCountry.Names,
+			Type:              update.RepresentedCountry.Type,
+			IsInEuropeanUnion: update.RepresentedCountry.IsInEuropeanUnion,
+			GeonameId:         uint32(update.RepresentedCountry.GeoNameID),
+		}
+	}
+	if update.RegisteredCountry != nil {
+		request.RegisteredCountry = &geolize_pb.RegisteredCountry{
+			IsoCode:           update.RegisteredCountry.ISOCode,
+			Names:             update.RegisteredCountry.Names,
+			IsInEuropeanUnion: update.RegisteredCountry.IsInEuropeanUnion,
+			GeonameId:         uint32(update.RegisteredCountry.GeoNameID),
+			Confidence:        uint32(update.RegisteredCountry.Confidence),
+		}
+	}
+	if update.Traits != nil {
+		request.Traits = &geolize_pb.Traits{
+			IsAnonymousProxy:             update.Traits.IsAnonymousProxy,
+			IsAnycast:                    update.Traits.IsAnycast,
+			IsSatelliteProvider:          update.Traits.IsSatelliteProvider,
+			IsAnonymous:                  update.Traits.IsAnonymous,
+			IsAnonymousVpn:               update.Traits.IsAnonymousVPN,
+			IsHostingProvider:            update.Traits.IsHostingProvider,
+			IsLegitimateProxy:            update.Traits.IsLegitimateProxy,
+			IsPublicProxy:                update.Traits.IsPublicProxy,
+			IsResidentialProxy:           update.Traits.IsResidentialProxy,
+			IsTorExitNode:                update.Traits.IsTorExitNode,
+			AutonomousSystemNumber:       uint32(update.Traits.AutonomousSystemNumber),
+			AutonomousSystemOrganization: update.Traits.AutonomousSystemOrganization,
+			ConnectionType:               update.Traits.ConnectionType,
+			Domain:                       update.Traits.Domain,
+			Isp:                          update.Traits.ISP,
+			MobileCountryCode:            update.Traits.MobileCountryCode,
+			MobileNetworkCode:            update.Traits.MobileNetworkCode,
+			Organization:                 update.Traits.Organization,
+			UserType:                     update.Traits.UserType,
+			StaticIpScore:                update.Traits.StaticIPScore,
+		}
+	}
+
+	return request
+}
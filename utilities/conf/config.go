@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"gopkg.in/ini.v1"
 	"os"
+	"time"
 )
 
 var (
@@ -73,6 +74,25 @@ func GetBool(group string, key string, fallback bool) (bool, error) {
 	return false, fmt.Errorf("missing %s.ini to start", env)
 }
 
+// GetDuration reads a Go duration string such as "10m" or "1h30m".
+func GetDuration(group string, key string, fallback time.Duration) (time.Duration, error) {
+	if f != nil {
+		k, err := f.Section(group).GetKey(key)
+		if err != nil {
+			return fallback, nil
+		}
+
+		v, err := k.Duration()
+		if err != nil {
+			return fallback, nil
+		}
+
+		return v, nil
+	}
+
+	return 0, fmt.Errorf("missing %s.ini to start", env)
+}
+
 func GetStringSlice(group string, key string) ([]string, error) {
 	if f != nil {
 		k, err := f.Section(group).GetKey(key)
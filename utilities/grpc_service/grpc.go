@@ -76,9 +76,13 @@ func (s *Server) Run() error {
 
 func (s *Server) grpcRun(l net.Listener) error {
 	var unaryInterceptors = []grpc.UnaryServerInterceptor{
+		interceptors.RecoveryInterceptor(s.logger),
 		interceptors.RequestInterceptor(s.logger),
 	}
-	var streamInterceptors []grpc.StreamServerInterceptor
+	var streamInterceptors = []grpc.StreamServerInterceptor{
+		interceptors.StreamRecoveryInterceptor(s.logger),
+		interceptors.StreamRequestInterceptor(s.logger),
+	}
 
 	s.server = grpc.NewServer(grpc.ChainUnaryInterceptor(unaryInterceptors...), grpc.ChainStreamInterceptor(streamInterceptors...))
 	s.register(s.server)
@@ -90,6 +94,7 @@ func (s *Server) grpcRun(l net.Listener) error {
 func (s *Server) httpRun(l net.Listener) error {
 	gwMux := runtime.NewServeMux(
 		runtime.WithErrorHandler(ErrorHandler),
+		runtime.WithIncomingHeaderMatcher(HeaderMatcher),
 	)
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
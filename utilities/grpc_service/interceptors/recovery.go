@@ -0,0 +1,49 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"geolize/utilities/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryInterceptor turns a panic in a unary handler into an Internal error
+// instead of crashing the server.
+func RecoveryInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, logger, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor is RecoveryInterceptor for streaming handlers.
+func StreamRecoveryInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), logger, info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, logger logging.Logger, method string, r interface{}) error {
+	logger.Error(ctx, "Recovered from panic",
+		append(logging.NewError(fmt.Errorf("%v", r)),
+			logging.NewKeyVal("api", method),
+			logging.NewKeyVal("stack", string(debug.Stack())))...)
+
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,58 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"geolize/utilities/contexts"
+	"geolize/utilities/logging"
+
+	"google.golang.org/grpc"
+)
+
+// serverStream overrides the context of a grpc.ServerStream so handlers see
+// the server context set up by the interceptor.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func StreamRequestInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		reqCtx := contexts.NewServerContext(ss.Context())
+
+		if len(contexts.GetServerData(reqCtx).RequestID) < 1 {
+			xRequestID, err := uuid.NewV7()
+			if err != nil {
+				logger.Error(reqCtx, "Error: cannot generate request id with UUIDV7", logging.NewError(err)...)
+				xRequestID = uuid.New()
+			}
+			contexts.SetRequestID(reqCtx, xRequestID.String())
+		}
+
+		logger.Info(reqCtx, "Request headers",
+			logging.NewKeyVal("in-md", contexts.GetServerData(reqCtx).IncomingHeaders))
+
+		// Create logger with request ID field
+		requestLogger := logger.WithFields(
+			logging.NewKeyVal("request_id", contexts.GetServerData(reqCtx).RequestID))
+
+		requestLogger.Info(reqCtx, "Incoming Stream", logging.NewKeyVal("api", info.FullMethod))
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: reqCtx})
+		if err != nil {
+			requestLogger.Error(reqCtx, "Error",
+				logging.NewError(err)...)
+			return err
+		}
+
+		requestLogger.Info(reqCtx, "Stream closed", logging.NewKeyVal("api", info.FullMethod))
+
+		return nil
+	}
+}
@@ -7,8 +7,25 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"net/http"
+	"strings"
 )
 
+// forwardedHeaders are passed on to gRPC metadata under their own name, so
+// handlers read them the same way for HTTP and gRPC callers.
+var forwardedHeaders = []string{"x-user"}
+
+// HeaderMatcher forwards forwardedHeaders as is and every other header the way
+// grpc-gateway does by default.
+func HeaderMatcher(key string) (string, bool) {
+	key = strings.ToLower(key)
+	for _, header := range forwardedHeaders {
+		if key == header {
+			return key, true
+		}
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
 func ErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
 
 	st := status.Convert(err)